@@ -0,0 +1,6 @@
+data "bitwarden_server_info" "current" {
+}
+
+output "server_version" {
+  value = data.bitwarden_server_info.current.server_version
+}
@@ -0,0 +1,182 @@
+//go:build offline
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/embedded"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordManagerRecordsWrites(t *testing.T) {
+	vault, err := embedded.NewMemoryVault(t.Context(), "")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "audit", "log.jsonl")
+	log := newTestLog(t, path, WithClientImplementation("memory"), WithActor("terraform@example.com"))
+	client := NewPasswordManager(vault, log)
+
+	created, err := client.CreateItem(t.Context(), models.Item{
+		Name:  "Postgres",
+		Type:  models.ItemTypeLogin,
+		Login: models.Login{Username: "admin", Password: "first-password"},
+	})
+	require.NoError(t, err)
+
+	edited := *created
+	edited.Login.Password = "second-password"
+	_, err = client.EditItem(WithPriorObject(t.Context(), *created), edited)
+	require.NoError(t, err)
+
+	require.NoError(t, client.DeleteItem(t.Context(), *created))
+	assert.Error(t, client.DeleteItem(t.Context(), *created))
+
+	entries := readEntries(t, path)
+	require.Len(t, entries, 4)
+
+	assert.Equal(t, Entry{
+		Timestamp:            "2025-06-01T12:00:00Z",
+		ClientImplementation: "memory",
+		Actor:                "terraform@example.com",
+		Operation:            OperationCreate,
+		ObjectType:           models.ObjectTypeItem,
+		ObjectID:             created.ID,
+		ChangedAttributes:    []string{"login.password", "login.username", "name", "type"},
+		Result:               ResultSuccess,
+	}, entries[0])
+
+	assert.Equal(t, OperationEdit, entries[1].Operation)
+	assert.Equal(t, []string{"login.password"}, entries[1].ChangedAttributes)
+
+	assert.Equal(t, OperationDelete, entries[2].Operation)
+	assert.Equal(t, created.ID, entries[2].ObjectID)
+	assert.Equal(t, ResultSuccess, entries[2].Result)
+
+	assert.Equal(t, ResultFailure, entries[3].Result)
+	assert.Equal(t, models.ErrorKindNotFound, entries[3].ErrorKind)
+	assert.Equal(t, "delete of item failed: not found", entries[3].Error)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "first-password")
+	assert.NotContains(t, string(content), "second-password")
+}
+
+func TestSecretsManagerRecordsWrites(t *testing.T) {
+	vault, err := embedded.NewMemoryVault(t.Context(), "")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	client := NewSecretsManager(vault, newTestLog(t, path, WithActor("access-token-id")))
+
+	project, err := client.CreateProject(t.Context(), models.Project{Name: "Backend"})
+	require.NoError(t, err)
+	secret, err := client.CreateSecret(t.Context(), models.Secret{Key: "KEY", Value: "v1", ProjectID: project.ID})
+	require.NoError(t, err)
+
+	edited := *secret
+	edited.Note = "rotated"
+	edited.Value = "v2"
+	_, err = client.EditSecret(WithPriorObject(t.Context(), *secret), edited)
+	require.NoError(t, err)
+
+	entries := readEntries(t, path)
+	require.Len(t, entries, 3)
+	assert.Equal(t, models.ObjectProject, entries[0].ObjectType)
+	assert.Equal(t, project.OrganizationID, entries[0].OrganizationID)
+	assert.Equal(t, models.ObjectSecret, entries[1].ObjectType)
+	assert.Equal(t, "access-token-id", entries[1].Actor)
+	assert.Equal(t, []string{"note", "value"}, entries[2].ChangedAttributes)
+}
+
+func TestPasswordManagerEditWithoutPriorObject(t *testing.T) {
+	vault, err := embedded.NewMemoryVault(t.Context(), "")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	client := NewPasswordManager(vault, newTestLog(t, path))
+
+	folder, err := client.CreateFolder(t.Context(), models.Folder{Name: "before"})
+	require.NoError(t, err)
+	folder.Name = "after"
+	_, err = client.EditFolder(t.Context(), *folder)
+	require.NoError(t, err)
+
+	entries := readEntries(t, path)
+	require.Len(t, entries, 2)
+	assert.Equal(t, OperationEdit, entries[1].Operation)
+	assert.Nil(t, entries[1].ChangedAttributes)
+}
+
+func TestLogNeverRecordsErrorMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	log := newTestLog(t, path)
+
+	log.record(t.Context(), Entry{Operation: OperationEdit, ObjectType: models.ObjectTypeItem}, errors.New(`unable to parse result of 'edit item', output: '{"login":{"password":"p4ssw0rd"}}'`))
+	log.record(t.Context(), Entry{Operation: OperationEdit, ObjectType: models.ObjectTypeItem}, models.Errorf(models.ErrorKindPermissionDenied, "not allowed to edit 'p4ssw0rd'"))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "p4ssw0rd")
+
+	entries := readEntries(t, path)
+	require.Len(t, entries, 2)
+	assert.Empty(t, entries[0].ErrorKind)
+	assert.Equal(t, "edit of item failed, see the provider logs for details", entries[0].Error)
+	assert.Equal(t, models.ErrorKindPermissionDenied, entries[1].ErrorKind)
+	assert.Equal(t, "edit of item failed: permission denied", entries[1].Error)
+}
+
+func TestPasswordManagerRotateItemKeyUnsupported(t *testing.T) {
+	vault, err := embedded.NewMemoryVault(t.Context(), "")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	client := NewPasswordManager(vault, newTestLog(t, path)).(itemKeyRotator)
+
+	_, err = client.RotateItemKey(t.Context(), models.Item{ID: "item-id"})
+	assert.ErrorIs(t, err, models.ErrItemKeyRotationUnsupported)
+	assert.Empty(t, readEntries(t, path))
+}
+
+func TestChangedAttributes(t *testing.T) {
+	before := &models.Folder{ID: "id", Name: "before"}
+	assert.Equal(t, []string{"name"}, changedAttributes(before, &models.Folder{ID: "id", Name: "after"}))
+	assert.Equal(t, []string{}, changedAttributes(before, before))
+	assert.Equal(t, []string{"name"}, changedAttributes(nil, before))
+	assert.Equal(t, []string{}, changedAttributes((*models.Folder)(nil), &models.Folder{}))
+}
+
+func newTestLog(t *testing.T, path string, opts ...Option) *Log {
+	log, err := NewLog(path, opts...)
+	require.NoError(t, err)
+	log.now = func() time.Time {
+		return time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	}
+	return log
+}
+
+func readEntries(t *testing.T, path string) []Entry {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.NoError(t, scanner.Err())
+	return entries
+}
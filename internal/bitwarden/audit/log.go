@@ -0,0 +1,244 @@
+// Package audit records the writes the provider makes to the Vault, as JSON
+// lines appended to a local file. Clients are wrapped with decorators, so that
+// every implementation is covered the same way.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+)
+
+type Operation string
+
+const (
+	OperationCreate    Operation = "create"
+	OperationEdit      Operation = "edit"
+	OperationDelete    Operation = "delete"
+	OperationRotateKey Operation = "rotate-key"
+)
+
+type Result string
+
+const (
+	ResultSuccess Result = "success"
+	ResultFailure Result = "failure"
+)
+
+// Entry is a line of the audit log. It never holds the values of the
+// attributes of an object, only their names.
+type Entry struct {
+	Timestamp            string            `json:"timestamp"`
+	ClientImplementation string            `json:"client_implementation,omitempty"`
+	Actor                string            `json:"actor,omitempty"`
+	Operation            Operation         `json:"operation"`
+	ObjectType           models.ObjectType `json:"object_type"`
+	ObjectID             string            `json:"object_id,omitempty"`
+	ItemID               string            `json:"item_id,omitempty"`
+	OrganizationID       string            `json:"organization_id,omitempty"`
+	ChangedAttributes    []string          `json:"changed_attributes,omitempty"`
+	Result               Result            `json:"result"`
+	ErrorKind            models.ErrorKind  `json:"error_kind,omitempty"`
+	Error                string            `json:"error,omitempty"`
+}
+
+// ignoredAttributes are maintained by the server or the client, either along
+// with other attributes or to describe what the account is allowed to do.
+var ignoredAttributes = []string{"creationDate", "deletedDate", "edit", "id", "key", "object", "organizationUseTotp", "passwordHistory", "read", "revisionDate", "viewPassword", "write"}
+
+type priorObjectKey struct{}
+
+// WithPriorObject attaches to the context of an edit the object as the caller
+// last knew it, which edits are compared with to record the attributes they
+// changed. Without it, edits are recorded without their changed attributes.
+func WithPriorObject[T any](ctx context.Context, obj T) context.Context {
+	return context.WithValue(ctx, priorObjectKey{}, &obj)
+}
+
+func priorObject[T any](ctx context.Context) *T {
+	obj, _ := ctx.Value(priorObjectKey{}).(*T)
+	return obj
+}
+
+var (
+	filesMu sync.Mutex
+	files   = map[string]*sync.Mutex{}
+)
+
+type Log struct {
+	path                 string
+	fileMu               *sync.Mutex
+	clientImplementation string
+	actor                string
+	now                  func() time.Time
+}
+
+type Option func(*Log)
+
+// WithClientImplementation sets the client implementation the entries are
+// attributed to.
+func WithClientImplementation(clientImplementation string) Option {
+	return func(l *Log) {
+		l.clientImplementation = clientImplementation
+	}
+}
+
+// WithActor sets who the entries are attributed to, like the email of an
+// account or the ID of a machine account's access token.
+func WithActor(actor string) Option {
+	return func(l *Log) {
+		l.actor = actor
+	}
+}
+
+// NewLog returns a log appending entries to the file at path, which is
+// created if it doesn't exist. Logs of the same process writing to the same
+// file never interleave their lines.
+func NewLog(path string, opts ...Option) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("error creating the directory of the audit log: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening the audit log: %w", err)
+	}
+	f.Close()
+
+	filesMu.Lock()
+	fileMu, ok := files[path]
+	if !ok {
+		fileMu = &sync.Mutex{}
+		files[path] = fileMu
+	}
+	filesMu.Unlock()
+
+	l := &Log{
+		path:   path,
+		fileMu: fileMu,
+		now:    time.Now,
+	}
+	for _, o := range opts {
+		o(l)
+	}
+	return l, nil
+}
+
+// record appends an entry for an operation to the log. Failing to do so
+// doesn't fail the operation, which already happened.
+func (l *Log) record(ctx context.Context, entry Entry, err error) {
+	entry.Timestamp = l.now().UTC().Format(time.RFC3339Nano)
+	entry.ClientImplementation = l.clientImplementation
+	entry.Actor = l.actor
+	entry.Result = ResultSuccess
+	if err != nil {
+		entry.Result = ResultFailure
+		entry.ErrorKind, entry.Error = errorSummary(entry, err)
+	}
+
+	if writeErr := l.append(entry); writeErr != nil {
+		tflog.Error(ctx, "Unable to write to the audit log", map[string]interface{}{"path": l.path, "error": writeErr})
+	}
+}
+
+// errorSummary describes why a write failed without the message of the
+// error, which may quote what the server or the CLI returned, decrypted
+// values included.
+func errorSummary(entry Entry, err error) (models.ErrorKind, string) {
+	kind, ok := models.ErrorKindOf(err)
+	if !ok {
+		return "", fmt.Sprintf("%s of %s failed, see the provider logs for details", entry.Operation, entry.ObjectType)
+	}
+	return kind, fmt.Sprintf("%s of %s failed: %s", entry.Operation, entry.ObjectType, kind)
+}
+
+func (l *Log) append(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.fileMu.Lock()
+	defer l.fileMu.Unlock()
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// changedAttributes returns the names of the attributes which differ between
+// two versions of an object, like "name" or "login.password". Attributes of
+// nested objects are compared one by one, lists as a whole. A nil before
+// returns the attributes set on after.
+func changedAttributes(before, after interface{}) []string {
+	changed := []string{}
+	diffAttributes("", toAttributes(before), toAttributes(after), &changed)
+	sort.Strings(changed)
+	return changed
+}
+
+func toAttributes(obj interface{}) map[string]interface{} {
+	attributes := map[string]interface{}{}
+	if obj == nil || (reflect.ValueOf(obj).Kind() == reflect.Pointer && reflect.ValueOf(obj).IsNil()) {
+		return attributes
+	}
+	out, err := json.Marshal(obj)
+	if err != nil {
+		return attributes
+	}
+	_ = json.Unmarshal(out, &attributes)
+	return attributes
+}
+
+func diffAttributes(prefix string, before, after map[string]interface{}, changed *[]string) {
+	names := map[string]struct{}{}
+	for name := range before {
+		names[name] = struct{}{}
+	}
+	for name := range after {
+		names[name] = struct{}{}
+	}
+
+	for name := range names {
+		if len(prefix) == 0 && slices.Contains(ignoredAttributes, name) {
+			continue
+		}
+		beforeValue, afterValue := before[name], after[name]
+		beforeMap, beforeIsMap := beforeValue.(map[string]interface{})
+		afterMap, afterIsMap := afterValue.(map[string]interface{})
+		switch {
+		case beforeIsMap || afterIsMap:
+			diffAttributes(prefix+name+".", beforeMap, afterMap, changed)
+		case isZero(beforeValue) && isZero(afterValue):
+		case !reflect.DeepEqual(beforeValue, afterValue):
+			*changed = append(*changed, prefix+name)
+		}
+	}
+}
+
+func isZero(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
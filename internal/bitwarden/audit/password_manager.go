@@ -0,0 +1,161 @@
+package audit
+
+import (
+	"context"
+	"sort"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+)
+
+var _ bitwarden.PasswordManager = &passwordManager{}
+
+type itemKeyRotator interface {
+	RotateItemKey(ctx context.Context, obj models.Item) (*models.Item, error)
+}
+
+// passwordManager records the writes made through a Password Manager client.
+// Reads are passed through.
+type passwordManager struct {
+	bitwarden.PasswordManager
+	log *Log
+}
+
+// NewPasswordManager wraps a Password Manager client so that its writes are
+// recorded in log.
+func NewPasswordManager(client bitwarden.PasswordManager, log *Log) bitwarden.PasswordManager {
+	return &passwordManager{PasswordManager: client, log: log}
+}
+
+func (c *passwordManager) CreateAttachmentFromContent(ctx context.Context, itemId, filename string, content []byte) (*models.Attachment, error) {
+	res, err := c.PasswordManager.CreateAttachmentFromContent(ctx, itemId, filename, content)
+	c.recordAttachment(ctx, itemId, res, err)
+	return res, err
+}
+
+func (c *passwordManager) CreateAttachmentFromFile(ctx context.Context, itemId, filePath string) (*models.Attachment, error) {
+	res, err := c.PasswordManager.CreateAttachmentFromFile(ctx, itemId, filePath)
+	c.recordAttachment(ctx, itemId, res, err)
+	return res, err
+}
+
+func (c *passwordManager) recordAttachment(ctx context.Context, itemId string, res *models.Attachment, err error) {
+	entry := Entry{Operation: OperationCreate, ObjectType: models.ObjectTypeAttachment, ItemID: itemId}
+	if res != nil {
+		entry.ObjectID = res.ID
+		entry.ChangedAttributes = changedAttributes(nil, res)
+	}
+	c.log.record(ctx, entry, err)
+}
+
+func (c *passwordManager) CreateFolder(ctx context.Context, obj models.Folder) (*models.Folder, error) {
+	res, err := c.PasswordManager.CreateFolder(ctx, obj)
+	created := returnedOrSent(res, obj)
+	c.log.record(ctx, Entry{Operation: OperationCreate, ObjectType: models.ObjectTypeFolder, ObjectID: created.ID, ChangedAttributes: changedAttributes(nil, created)}, err)
+	return res, err
+}
+
+func (c *passwordManager) CreateItem(ctx context.Context, obj models.Item) (*models.Item, error) {
+	res, err := c.PasswordManager.CreateItem(ctx, obj)
+	created := returnedOrSent(res, obj)
+	c.log.record(ctx, Entry{Operation: OperationCreate, ObjectType: models.ObjectTypeItem, ObjectID: created.ID, OrganizationID: created.OrganizationID, ChangedAttributes: changedAttributes(nil, created)}, err)
+	return res, err
+}
+
+func (c *passwordManager) CreateOrganizationCollection(ctx context.Context, obj models.OrgCollection) (*models.OrgCollection, error) {
+	res, err := c.PasswordManager.CreateOrganizationCollection(ctx, obj)
+	created := returnedOrSent(res, obj)
+	c.log.record(ctx, Entry{Operation: OperationCreate, ObjectType: models.ObjectTypeOrgCollection, ObjectID: created.ID, OrganizationID: obj.OrganizationID, ChangedAttributes: changedAttributes(nil, created)}, err)
+	return res, err
+}
+
+func (c *passwordManager) CreateOrganizationGroup(ctx context.Context, obj models.OrgGroup) (*models.OrgGroup, error) {
+	res, err := c.PasswordManager.CreateOrganizationGroup(ctx, obj)
+	created := returnedOrSent(res, obj)
+	c.log.record(ctx, Entry{Operation: OperationCreate, ObjectType: models.ObjectTypeOrgGroup, ObjectID: created.ID, OrganizationID: obj.OrganizationID, ChangedAttributes: changedAttributes(nil, created)}, err)
+	return res, err
+}
+
+func (c *passwordManager) DeleteAttachment(ctx context.Context, itemId, attachmentId string) error {
+	err := c.PasswordManager.DeleteAttachment(ctx, itemId, attachmentId)
+	c.log.record(ctx, Entry{Operation: OperationDelete, ObjectType: models.ObjectTypeAttachment, ObjectID: attachmentId, ItemID: itemId}, err)
+	return err
+}
+
+func (c *passwordManager) DeleteFolder(ctx context.Context, obj models.Folder) error {
+	err := c.PasswordManager.DeleteFolder(ctx, obj)
+	c.log.record(ctx, Entry{Operation: OperationDelete, ObjectType: models.ObjectTypeFolder, ObjectID: obj.ID}, err)
+	return err
+}
+
+func (c *passwordManager) DeleteItem(ctx context.Context, obj models.Item) error {
+	err := c.PasswordManager.DeleteItem(ctx, obj)
+	c.log.record(ctx, Entry{Operation: OperationDelete, ObjectType: models.ObjectTypeItem, ObjectID: obj.ID, OrganizationID: obj.OrganizationID}, err)
+	return err
+}
+
+func (c *passwordManager) DeleteOrganizationCollection(ctx context.Context, obj models.OrgCollection) error {
+	err := c.PasswordManager.DeleteOrganizationCollection(ctx, obj)
+	c.log.record(ctx, Entry{Operation: OperationDelete, ObjectType: models.ObjectTypeOrgCollection, ObjectID: obj.ID, OrganizationID: obj.OrganizationID}, err)
+	return err
+}
+
+func (c *passwordManager) DeleteOrganizationGroup(ctx context.Context, obj models.OrgGroup) error {
+	err := c.PasswordManager.DeleteOrganizationGroup(ctx, obj)
+	c.log.record(ctx, Entry{Operation: OperationDelete, ObjectType: models.ObjectTypeOrgGroup, ObjectID: obj.ID, OrganizationID: obj.OrganizationID}, err)
+	return err
+}
+
+func (c *passwordManager) EditFolder(ctx context.Context, obj models.Folder) (*models.Folder, error) {
+	res, err := c.PasswordManager.EditFolder(ctx, obj)
+	c.log.record(ctx, Entry{Operation: OperationEdit, ObjectType: models.ObjectTypeFolder, ObjectID: obj.ID, ChangedAttributes: editedAttributes(ctx, res, obj)}, err)
+	return res, err
+}
+
+func (c *passwordManager) EditItem(ctx context.Context, obj models.Item) (*models.Item, error) {
+	res, err := c.PasswordManager.EditItem(ctx, obj)
+	c.log.record(ctx, Entry{Operation: OperationEdit, ObjectType: models.ObjectTypeItem, ObjectID: obj.ID, OrganizationID: obj.OrganizationID, ChangedAttributes: editedAttributes(ctx, res, obj)}, err)
+	return res, err
+}
+
+func (c *passwordManager) EditOrganizationCollection(ctx context.Context, obj models.OrgCollection) (*models.OrgCollection, error) {
+	res, err := c.PasswordManager.EditOrganizationCollection(ctx, obj)
+	c.log.record(ctx, Entry{Operation: OperationEdit, ObjectType: models.ObjectTypeOrgCollection, ObjectID: obj.ID, OrganizationID: obj.OrganizationID, ChangedAttributes: editedAttributes(ctx, res, obj)}, err)
+	return res, err
+}
+
+// RotateItemKey rotates the key of an item when the wrapped client supports
+// it, which only the embedded client does.
+func (c *passwordManager) RotateItemKey(ctx context.Context, obj models.Item) (*models.Item, error) {
+	rotator, ok := c.PasswordManager.(itemKeyRotator)
+	if !ok {
+		return nil, models.ErrItemKeyRotationUnsupported
+	}
+	res, err := rotator.RotateItemKey(ctx, obj)
+	// Rotations are sent along with the content changes of the item.
+	changed := append(editedAttributes(ctx, res, obj), "key")
+	sort.Strings(changed)
+	c.log.record(ctx, Entry{Operation: OperationRotateKey, ObjectType: models.ObjectTypeItem, ObjectID: obj.ID, OrganizationID: obj.OrganizationID, ChangedAttributes: changed}, err)
+	return res, err
+}
+
+// returnedOrSent returns the object the server returned for a write, or the
+// one that was sent when the write failed.
+func returnedOrSent[T any](res *T, obj T) *T {
+	if res != nil {
+		return res
+	}
+	return &obj
+}
+
+// editedAttributes returns the attributes an edit changed, comparing the
+// object the caller attached to the context with the one the server
+// returned, or the one that was sent when the edit failed. It returns nil when
+// no object was attached.
+func editedAttributes[T any](ctx context.Context, res *T, obj T) []string {
+	before := priorObject[T](ctx)
+	if before == nil {
+		return nil
+	}
+	return changedAttributes(before, returnedOrSent(res, obj))
+}
@@ -0,0 +1,61 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+)
+
+var _ bitwarden.SecretsManager = &secretsManager{}
+
+// secretsManager records the writes made through a Secrets Manager client.
+// Reads are passed through.
+type secretsManager struct {
+	bitwarden.SecretsManager
+	log *Log
+}
+
+// NewSecretsManager wraps a Secrets Manager client so that its writes are
+// recorded in log.
+func NewSecretsManager(client bitwarden.SecretsManager, log *Log) bitwarden.SecretsManager {
+	return &secretsManager{SecretsManager: client, log: log}
+}
+
+func (c *secretsManager) CreateProject(ctx context.Context, obj models.Project) (*models.Project, error) {
+	res, err := c.SecretsManager.CreateProject(ctx, obj)
+	created := returnedOrSent(res, obj)
+	c.log.record(ctx, Entry{Operation: OperationCreate, ObjectType: models.ObjectProject, ObjectID: created.ID, OrganizationID: created.OrganizationID, ChangedAttributes: changedAttributes(nil, created)}, err)
+	return res, err
+}
+
+func (c *secretsManager) CreateSecret(ctx context.Context, obj models.Secret) (*models.Secret, error) {
+	res, err := c.SecretsManager.CreateSecret(ctx, obj)
+	created := returnedOrSent(res, obj)
+	c.log.record(ctx, Entry{Operation: OperationCreate, ObjectType: models.ObjectSecret, ObjectID: created.ID, OrganizationID: created.OrganizationID, ChangedAttributes: changedAttributes(nil, created)}, err)
+	return res, err
+}
+
+func (c *secretsManager) DeleteProject(ctx context.Context, obj models.Project) error {
+	err := c.SecretsManager.DeleteProject(ctx, obj)
+	c.log.record(ctx, Entry{Operation: OperationDelete, ObjectType: models.ObjectProject, ObjectID: obj.ID, OrganizationID: obj.OrganizationID}, err)
+	return err
+}
+
+func (c *secretsManager) DeleteSecret(ctx context.Context, obj models.Secret) error {
+	err := c.SecretsManager.DeleteSecret(ctx, obj)
+	c.log.record(ctx, Entry{Operation: OperationDelete, ObjectType: models.ObjectSecret, ObjectID: obj.ID, OrganizationID: obj.OrganizationID}, err)
+	return err
+}
+
+func (c *secretsManager) EditProject(ctx context.Context, obj models.Project) (*models.Project, error) {
+	res, err := c.SecretsManager.EditProject(ctx, obj)
+	c.log.record(ctx, Entry{Operation: OperationEdit, ObjectType: models.ObjectProject, ObjectID: obj.ID, OrganizationID: obj.OrganizationID, ChangedAttributes: editedAttributes(ctx, res, obj)}, err)
+	return res, err
+}
+
+func (c *secretsManager) EditSecret(ctx context.Context, obj models.Secret) (*models.Secret, error) {
+	res, err := c.SecretsManager.EditSecret(ctx, obj)
+	c.log.record(ctx, Entry{Operation: OperationEdit, ObjectType: models.ObjectSecret, ObjectID: obj.ID, OrganizationID: obj.OrganizationID, ChangedAttributes: editedAttributes(ctx, res, obj)}, err)
+	return res, err
+}
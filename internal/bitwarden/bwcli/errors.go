@@ -1,6 +1,7 @@
 package bwcli
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -11,29 +12,83 @@ import (
 
 var (
 	attachmentNotFoundRegexp = regexp.MustCompile(`^Attachment .* was not found.$`)
+	sessionLostRegexp        = regexp.MustCompile(`(?i)(vault is locked|not logged in)`)
+
+	// errorClassifications are tried in order against the messages of the
+	// CLI.
+	errorClassifications = []struct {
+		kind   models.ErrorKind
+		regexp *regexp.Regexp
+	}{
+		{models.ErrorKindRateLimited, regexp.MustCompile(`(?i)rate limit`)},
+		{models.ErrorKindAuthFailure, regexp.MustCompile(`(?i)(not logged in|vault is locked|invalid master password|username or password is incorrect|invalid_grant|invalid_client)`)},
+		{models.ErrorKindPermissionDenied, regexp.MustCompile(`(?im)(do not have permission|^(you are )?not allowed to\b|\bforbidden\b)`)},
+		{models.ErrorKindConflict, regexp.MustCompile(`(?im)(out of date|\balready exists\.?$)`)},
+		{models.ErrorKindNotFound, regexp.MustCompile(`^(Not found\.|Attachment .* was not found\.)$`)},
+		{models.ErrorKindTransportFailure, regexp.MustCompile(`(ECONNREFUSED|ECONNRESET|ENOTFOUND|ETIMEDOUT|EAI_AGAIN|socket hang up|FetchError|\b[A-Z_]*CERT[A-Z_]*\b|UNABLE_TO_VERIFY_LEAF_SIGNATURE|certificate has expired|self[- ]signed certificate|unable to (get|verify) [a-z ]*certificate)`)},
+	}
 )
 
 func newUnmarshallError(err error, args []string, out []byte) error {
 	return fmt.Errorf("unable to parse result of '%s', error: '%v', output: '%v'", strings.Join(args, " "), err, string(out))
 }
 
-func remapError(err error) error {
-	v, ok := err.(*command.CommandError)
-	if ok {
-		switch {
-		case isObjectNotFoundError(v):
-			return models.ErrObjectNotFound
-		case isAttachmentNotFoundError(v):
-			return models.ErrAttachmentNotFound
+// cliErrorMessage returns the message of the CLI behind an error, whether it
+// was printed by a command or returned by `bw serve`.
+func cliErrorMessage(err error) (string, bool) {
+	var cmdErr *command.CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.Stderr(), true
+	}
+	var srvErr *serveError
+	if errors.As(err, &srvErr) {
+		return srvErr.message, true
+	}
+	return "", false
+}
+
+// classifyError attaches a kind to the errors of the CLI, without changing
+// their message.
+func classifyError(err error) error {
+	message, ok := cliErrorMessage(err)
+	if !ok {
+		return err
+	}
+	for _, classification := range errorClassifications {
+		if classification.regexp.MatchString(strings.TrimSpace(message)) {
+			return models.NewError(classification.kind, err)
 		}
 	}
 	return err
 }
 
-func isAttachmentNotFoundError(err *command.CommandError) bool {
-	return attachmentNotFoundRegexp.Match([]byte(err.Stderr()))
+// remapError maps the messages of the CLI to model errors.
+func remapError(err error) error {
+	message, ok := cliErrorMessage(err)
+	if !ok {
+		return err
+	}
+
+	switch {
+	case isObjectNotFoundError(message):
+		return models.ErrObjectNotFound
+	case isAttachmentNotFoundError(message):
+		return models.ErrAttachmentNotFound
+	}
+	return err
+}
+
+// isSessionLostError returns true when a command failed because the Vault is
+// locked or logged out.
+func isSessionLostError(err error) bool {
+	message, ok := cliErrorMessage(err)
+	return ok && sessionLostRegexp.MatchString(message)
+}
+
+func isAttachmentNotFoundError(message string) bool {
+	return attachmentNotFoundRegexp.MatchString(message)
 }
 
-func isObjectNotFoundError(err *command.CommandError) bool {
-	return err.Stderr() == "Not found."
+func isObjectNotFoundError(message string) bool {
+	return message == "Not found."
 }
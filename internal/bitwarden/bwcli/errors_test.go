@@ -0,0 +1,32 @@
+//go:build offline
+
+package bwcli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/command"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorsAreOnlyClassifiedOnTheirMessage(t *testing.T) {
+	for message, kind := range map[string]models.ErrorKind{
+		`You are not allowed to edit this item.`:                                     models.ErrorKindPermissionDenied,
+		`An item with this name already exists.`:                                     models.ErrorKindConflict,
+		`FetchError: request failed, reason: unable to get local issuer certificate`: models.ErrorKindTransportFailure,
+		`FetchError: request failed, reason: CERT_HAS_EXPIRED`:                       models.ErrorKindTransportFailure,
+	} {
+		assert.ErrorIs(t, classifyError(command.NewError(fmt.Errorf("exit status 1"), []string{"test"}, "", message)), kind, message)
+	}
+
+	for _, message := range []string{
+		`Item "Certificate already exists for not allowed hosts" has invalid data.`,
+		`Field "certificate" is required.`,
+	} {
+		var classified *models.Error
+		assert.False(t, errors.As(classifyError(command.NewError(fmt.Errorf("exit status 1"), []string{"test"}, "", message)), &classified), message)
+	}
+}
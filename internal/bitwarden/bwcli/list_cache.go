@@ -0,0 +1,289 @@
+package bwcli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+)
+
+const (
+	filterNull    = "null"
+	filterNotNull = "notnull"
+)
+
+// listCache serves reads from a single `bw list` per object type, instead of
+// a command decrypting the whole Vault for every read. It lives as long as the
+// client, which is one Terraform run, and forgets the object types a write
+// may have modified.
+//
+// The lock is never held while running commands, as recovering a lost session
+// resets the cache from within them.
+type listCache struct {
+	mu      sync.Mutex
+	objects map[string][]cachedObject
+
+	// generation changes whenever objects are forgotten, so that lists which
+	// started before aren't stored.
+	generation uint64
+}
+
+// cachedObject is an object as printed by `bw list`, along with the fields
+// the CLI filters on.
+type cachedObject struct {
+	raw    json.RawMessage
+	fields cachedFields
+}
+
+type cachedFields struct {
+	CollectionIds []string   `json:"collectionIds"`
+	DeletedDate   *time.Time `json:"deletedDate"`
+	FolderID      string     `json:"folderId"`
+	ID            string     `json:"id"`
+	Login         struct {
+		Username string `json:"username"`
+		URIs     []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+	} `json:"login"`
+	Name           string `json:"name"`
+	OrganizationID string `json:"organizationId"`
+	SSHKey         struct {
+		KeyFingerprint string `json:"keyFingerprint"`
+	} `json:"sshKey"`
+}
+
+func newListCache() *listCache {
+	return &listCache{objects: map[string][]cachedObject{}}
+}
+
+// listCacheKey returns the key under which objects of a type are cached, and
+// false when the CLI can't list them all in a single command.
+func listCacheKey(objectType models.ObjectType, orgID string) (string, bool) {
+	switch objectType {
+	case models.ObjectTypeItem, models.ObjectTypeFolder:
+		return string(objectType), true
+	case models.ObjectTypeOrgCollection:
+		// Organization collections can only be listed one organization at a
+		// time.
+		if len(orgID) == 0 {
+			return "", false
+		}
+		return fmt.Sprintf("%s/%s", objectType, orgID), true
+	}
+	return "", false
+}
+
+func (l *listCache) load(ctx context.Context, c *client, objectType models.ObjectType, orgID string) ([]cachedObject, bool, error) {
+	key, ok := listCacheKey(objectType, orgID)
+	if !ok {
+		return nil, false, nil
+	}
+
+	l.mu.Lock()
+	objects, ok := l.objects[key]
+	generation := l.generation
+	l.mu.Unlock()
+	if ok {
+		return objects, true, nil
+	}
+
+	args := []string{"list", fmt.Sprintf("%ss", objectType)}
+	if objectType == models.ObjectTypeOrgCollection {
+		args = append(args, "--organizationid", orgID)
+	}
+	out, err := c.run(ctx, args...)
+	if err != nil {
+		return nil, false, remapError(err)
+	}
+
+	var raws []json.RawMessage
+	if err := json.Unmarshal(out, &raws); err != nil {
+		return nil, false, newUnmarshallError(err, args, out)
+	}
+
+	objects = make([]cachedObject, 0, len(raws))
+	for _, raw := range raws {
+		obj := cachedObject{raw: raw}
+		if err := json.Unmarshal(raw, &obj.fields); err != nil {
+			return nil, false, newUnmarshallError(err, args, out)
+		}
+		// Like `bw list`, the cache ignores items in the trash.
+		if obj.fields.DeletedDate != nil {
+			continue
+		}
+		objects = append(objects, obj)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.generation == generation {
+		l.objects[key] = objects
+	}
+	return objects, true, nil
+}
+
+// get returns an object the way `bw get` would print it. It returns false when
+// the object can't be served from the cache, e.g. because `bw get` would have
+// treated the ID as a search term. Organization collections aren't served, as
+// `bw get` prints their members while `bw list` doesn't.
+func (l *listCache) get(ctx context.Context, c *client, objectType models.ObjectType, id string) ([]byte, bool, error) {
+	if l == nil {
+		return nil, false, nil
+	}
+	if objectType != models.ObjectTypeItem && objectType != models.ObjectTypeFolder {
+		return nil, false, nil
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, false, nil
+	}
+
+	objects, ok, err := l.load(ctx, c, objectType, "")
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+
+	for _, obj := range objects {
+		if obj.fields.ID == id {
+			return obj.raw, true, nil
+		}
+	}
+	return nil, true, models.ErrObjectNotFound
+}
+
+// list returns objects matching filters the way `bw list` would print them,
+// given the arguments applyFiltersToArgs() generates.
+func (l *listCache) list(ctx context.Context, c *client, objectType models.ObjectType, filters bitwarden.ListObjectsFilterOptions) ([]byte, bool, error) {
+	if l == nil {
+		return nil, false, nil
+	}
+	// Matching URLs depends on the equivalent domains and match detection
+	// settings of the account, which is left to the CLI.
+	if len(filters.UrlFilter) > 0 {
+		return nil, false, nil
+	}
+
+	objects, ok, err := l.load(ctx, c, objectType, filters.OrganizationFilter)
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+
+	search := strings.ToLower(strings.TrimSpace(filters.SearchFilter))
+	matches := []json.RawMessage{}
+	for _, obj := range objects {
+		if objectType == models.ObjectTypeItem && !obj.fields.matchesItemFilters(filters) {
+			continue
+		}
+		if len(search) > 0 && !obj.fields.matchesSearch(objectType, search) {
+			continue
+		}
+		matches = append(matches, obj.raw)
+	}
+
+	out, err := json.Marshal(matches)
+	return out, true, err
+}
+
+// invalidate forgets the objects a write on an object type may have modified.
+// Deleting a folder or a collection also updates the items it contained.
+func (l *listCache) invalidate(objectType models.ObjectType) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.generation++
+
+	switch objectType {
+	case models.ObjectTypeItem, models.ObjectTypeAttachment:
+		delete(l.objects, string(models.ObjectTypeItem))
+	case models.ObjectTypeFolder:
+		delete(l.objects, string(models.ObjectTypeFolder))
+		delete(l.objects, string(models.ObjectTypeItem))
+	case models.ObjectTypeOrgCollection:
+		for key := range l.objects {
+			if strings.HasPrefix(key, string(models.ObjectTypeOrgCollection)+"/") {
+				delete(l.objects, key)
+			}
+		}
+		delete(l.objects, string(models.ObjectTypeItem))
+	default:
+		clear(l.objects)
+	}
+}
+
+func (l *listCache) reset() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.generation++
+	clear(l.objects)
+}
+
+// matchesItemFilters reproduces `bw list items`, which returns items matching
+// any of the folder, organization and collection filters.
+func (f cachedFields) matchesItemFilters(filters bitwarden.ListObjectsFilterOptions) bool {
+	if len(filters.FolderFilter) == 0 && len(filters.OrganizationFilter) == 0 && len(filters.CollectionFilter) == 0 {
+		return true
+	}
+
+	if len(filters.FolderFilter) > 0 && matchesIDFilter(filters.FolderFilter, f.FolderID) {
+		return true
+	}
+	if len(filters.OrganizationFilter) > 0 && matchesIDFilter(filters.OrganizationFilter, f.OrganizationID) {
+		return true
+	}
+	if len(filters.CollectionFilter) > 0 {
+		switch filters.CollectionFilter {
+		case filterNull:
+			return len(f.CollectionIds) == 0
+		case filterNotNull:
+			return len(f.CollectionIds) > 0
+		}
+		return slices.Contains(f.CollectionIds, filters.CollectionFilter)
+	}
+	return false
+}
+
+func matchesIDFilter(filter, value string) bool {
+	switch filter {
+	case filterNull:
+		return len(value) == 0
+	case filterNotNull:
+		return len(value) > 0
+	}
+	return filter == value
+}
+
+// matchesSearch reproduces the basic search of the CLI. The search term is
+// expected to be trimmed and lowercased.
+func (f cachedFields) matchesSearch(objectType models.ObjectType, search string) bool {
+	if objectType != models.ObjectTypeItem {
+		return f.ID == search || strings.Contains(strings.ToLower(f.Name), search)
+	}
+
+	if strings.Contains(strings.ToLower(f.Name), search) {
+		return true
+	}
+	if len(search) >= 8 && strings.HasPrefix(f.ID, search) {
+		return true
+	}
+	if strings.Contains(strings.ToLower(f.Login.Username), search) || strings.Contains(strings.ToLower(f.SSHKey.KeyFingerprint), search) {
+		return true
+	}
+	for _, uri := range f.Login.URIs {
+		if strings.Contains(strings.ToLower(uri.URI), search) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+//go:build offline
+
+package bwcli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	test_command "github.com/maxlaverse/terraform-provider-bitwarden/internal/command/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	cachedItemID   = "5b4ab9a3-2b1b-4b8f-8c1b-b0f5011d5c0e"
+	cachedFolderID = "8d8bd4a5-c6d3-41f0-9d6e-b0f5011d5c0e"
+	cachedOrgID    = "c4a0d0a5-5b6e-4fd7-8e1a-b0f5011d5c0e"
+)
+
+func TestListCacheServesReads(t *testing.T) {
+	removeMocks, commandsExecuted := test_command.MockCommands(t, map[string]string{
+		"list items": `[
+			{"id": "` + cachedItemID + `", "name": "Postgres", "type": 1, "folderId": "` + cachedFolderID + `", "login": {"username": "admin", "uris": [{"uri": "https://db.example.com"}]}},
+			{"id": "0f1a0c6e-0000-4000-8000-b0f5011d5c0e", "name": "Shared", "type": 2, "organizationId": "` + cachedOrgID + `", "collectionIds": ["collection-id"]},
+			{"id": "7c3e2a10-0000-4000-8000-b0f5011d5c0e", "name": "Trashed", "type": 1, "deletedDate": "2025-01-01T00:00:00Z"}
+		]`,
+		"list folders": `[{"id": "` + cachedFolderID + `", "name": "Databases"}]`,
+	})
+	defer removeMocks(t)
+
+	b := NewPasswordManagerClient(WithListCache())
+	ctx := t.Context()
+
+	item, err := b.GetItem(ctx, models.Item{ID: cachedItemID, Object: models.ObjectTypeItem, Type: models.ItemTypeLogin})
+	require.NoError(t, err)
+	assert.Equal(t, "Postgres", item.Name)
+
+	found, err := b.FindItem(ctx, bitwarden.WithSearch("ADMIN"))
+	require.NoError(t, err)
+	assert.Equal(t, cachedItemID, found.ID)
+
+	found, err = b.FindItem(ctx, bitwarden.WithSearch("db.example"))
+	require.NoError(t, err)
+	assert.Equal(t, cachedItemID, found.ID)
+
+	// Like the CLI, items matching any of the folder, organization and
+	// collection filters are returned.
+	_, err = b.FindItem(ctx, bitwarden.WithFolderID(cachedFolderID), bitwarden.WithCollectionID("collection-id"))
+	assert.ErrorIs(t, err, models.ErrTooManyObjectsFound)
+
+	found, err = b.FindItem(ctx, bitwarden.WithOrganizationID(cachedOrgID), bitwarden.WithSearch("shared"))
+	require.NoError(t, err)
+	assert.Equal(t, "Shared", found.Name)
+
+	_, err = b.FindItem(ctx, bitwarden.WithSearch("Trashed"))
+	assert.ErrorIs(t, err, models.ErrNoObjectFoundMatchingFilter)
+
+	_, err = b.GetItem(ctx, models.Item{ID: "7c3e2a10-0000-4000-8000-b0f5011d5c0e", Object: models.ObjectTypeItem})
+	assert.ErrorIs(t, err, models.ErrObjectNotFound)
+
+	folder, err := b.FindFolder(ctx, bitwarden.WithSearch("data"))
+	require.NoError(t, err)
+	assert.Equal(t, cachedFolderID, folder.ID)
+
+	_, err = b.GetFolder(ctx, models.Folder{ID: cachedFolderID, Object: models.ObjectTypeFolder})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"list items", "list folders"}, commandsExecuted())
+}
+
+func TestListCacheIsInvalidatedByWrites(t *testing.T) {
+	removeMocks, commandsExecuted := test_command.MockCommands(t, map[string]string{
+		"list items":                  `[{"id": "` + cachedItemID + `", "name": "Postgres", "type": 1}]`,
+		"list folders":                `[]`,
+		"delete item " + cachedItemID: ``,
+		"sync":                        ``,
+	})
+	defer removeMocks(t)
+
+	b := NewPasswordManagerClient(WithListCache())
+	ctx := t.Context()
+
+	_, err := b.GetItem(ctx, models.Item{ID: cachedItemID, Object: models.ObjectTypeItem})
+	require.NoError(t, err)
+	_, err = b.GetFolder(ctx, models.Folder{ID: cachedFolderID, Object: models.ObjectTypeFolder})
+	assert.ErrorIs(t, err, models.ErrObjectNotFound)
+
+	require.NoError(t, b.DeleteItem(ctx, models.Item{ID: cachedItemID}))
+	_, err = b.GetItem(ctx, models.Item{ID: cachedItemID, Object: models.ObjectTypeItem})
+	require.NoError(t, err)
+
+	// Folders weren't touched by the deletion, but are by a sync.
+	_, err = b.GetFolder(ctx, models.Folder{ID: cachedFolderID, Object: models.ObjectTypeFolder})
+	assert.ErrorIs(t, err, models.ErrObjectNotFound)
+	require.NoError(t, b.Sync(ctx))
+	_, err = b.GetFolder(ctx, models.Folder{ID: cachedFolderID, Object: models.ObjectTypeFolder})
+	assert.ErrorIs(t, err, models.ErrObjectNotFound)
+
+	assert.Equal(t, []string{
+		"list items",
+		"list folders",
+		"delete item " + cachedItemID,
+		"list items",
+		"sync",
+		"list folders",
+	}, commandsExecuted())
+}
+
+func TestListCacheRecoversLostSessions(t *testing.T) {
+	removeMocks, commandsExecuted := test_command.MockCommands(t, map[string]string{
+		"list items @error": `Vault is locked.`,
+		"sync":              ``,
+	})
+	defer removeMocks(t)
+
+	b := NewPasswordManagerClient(WithListCache(), WithSessionRecovery(func(ctx context.Context, c PasswordManagerClient) error {
+		c.SetSessionKey("new-session-key")
+		return c.Sync(ctx)
+	}))
+	b.SetSessionKey("expired-session-key")
+
+	// Recovering resets the cache while it's being loaded.
+	done := make(chan error)
+	go func() {
+		_, err := b.GetItem(t.Context(), models.Item{ID: cachedItemID, Object: models.ObjectTypeItem})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		assert.ErrorContains(t, err, "Vault is locked.")
+	case <-time.After(5 * time.Second):
+		t.Fatal("loading the cache didn't return after recovering the session")
+	}
+	assert.Equal(t, []string{"list items", "sync", "list items"}, commandsExecuted())
+}
+
+func TestListCacheLeavesURLSearchesToTheCLI(t *testing.T) {
+	removeMocks, commandsExecuted := test_command.MockCommands(t, map[string]string{
+		"list items --url https://example.com": `[{"id": "` + cachedItemID + `"}]`,
+		"get item not-an-id":                   `{"id": "` + cachedItemID + `"}`,
+		"list org-collections --search search": `[{"id": "collection-id"}]`,
+	})
+	defer removeMocks(t)
+
+	b := NewPasswordManagerClient(WithListCache())
+	ctx := t.Context()
+
+	_, err := b.FindItem(ctx, bitwarden.WithUrl("https://example.com"))
+	require.NoError(t, err)
+	_, err = b.GetItem(ctx, models.Item{ID: "not-an-id", Object: models.ObjectTypeItem})
+	require.NoError(t, err)
+	_, err = b.FindOrganizationCollection(ctx, bitwarden.WithSearch("search"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"list items --url https://example.com",
+		"get item not-an-id",
+		"list org-collections --search search",
+	}, commandsExecuted())
+}
@@ -7,13 +7,24 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/command"
 )
 
+const (
+	// defaultAppDataDirName is the data directory of the CLI, under the user
+	// configuration directory, when BITWARDENCLI_APPDATA_DIR isn't set.
+	defaultAppDataDirName = "Bitwarden CLI"
+	lockFileName          = "terraform-provider-bitwarden.lock"
+)
+
 type PasswordManagerClient interface {
 	CreateAttachmentFromContent(ctx context.Context, itemId, filename string, content []byte) (*models.Attachment, error)
 	CreateAttachmentFromFile(ctx context.Context, itemId, filePath string) (*models.Attachment, error)
@@ -52,30 +63,51 @@ type PasswordManagerClient interface {
 	Status(context.Context) (*Status, error)
 	Sync(context.Context) error
 	Unlock(ctx context.Context, password string) error
+	Version(context.Context) (string, error)
 }
 
 func NewPasswordManagerClient(opts ...Options) PasswordManagerClient {
-	c := &client{}
+	c := &client{retryHandler: newRetryHandler()}
 
 	for _, o := range opts {
 		o(c)
 	}
 
-	c.newCommand = command.NewWithRetries(&retryHandler{disableRetryBackoff: c.disableRetryBackoff})
+	c.retryHandler.disableRetryBackoff = c.disableRetryBackoff
+	if lockPath := c.lockFilePath(); len(lockPath) > 0 {
+		c.schedulerOpts = append(c.schedulerOpts, command.WithLockFile(lockPath))
+	}
+	c.scheduler = command.NewScheduler(c.schedulerOpts...)
+	c.newCommand = command.NewWithScheduler(command.NewWithRetries(c.retryHandler), c.scheduler, isWriteCommand)
 
 	return c
 }
 
 type client struct {
+	apiURL                  string
 	appDataDir              string
 	disableSync             bool
 	disableRetryBackoff     bool
+	retryHandler            *retryHandler
+	eventsURL               string
 	extraCACertsPath        string
+	identityURL             string
+	listCache               *listCache
 	newCommand              command.NewFn
+	scheduler               *command.Scheduler
+	schedulerOpts           []command.SchedulerOptions
+	recoverSession          func(context.Context, PasswordManagerClient) error
+	recoveryMutex           sync.Mutex
+	server                  *server
 	sessionKey              string
+	sessionKeyMutex         sync.RWMutex
 	attachmentCreationMutex sync.Mutex
 }
 
+// recoveringSessionKey marks the context of commands run while recovering a
+// session, which must not try to recover it again.
+type recoveringSessionKey struct{}
+
 type Options func(c bitwarden.PasswordManager)
 
 func WithAppDataDir(appDataDir string) Options {
@@ -84,12 +116,70 @@ func WithAppDataDir(appDataDir string) Options {
 	}
 }
 
+// WithServerURLs sets the URLs of the API, identity and events services when
+// they're not served under the server URL. Empty values are ignored.
+func WithServerURLs(apiURL, identityURL, eventsURL string) Options {
+	return func(c bitwarden.PasswordManager) {
+		c.(*client).apiURL = apiURL
+		c.(*client).identityURL = identityURL
+		c.(*client).eventsURL = eventsURL
+	}
+}
+
 func WithExtraCACertsPath(extraCACertsPath string) Options {
 	return func(c bitwarden.PasswordManager) {
 		c.(*client).extraCACertsPath = extraCACertsPath
 	}
 }
 
+// WithServe sends the operations on the Vault to a `bw serve` process started
+// on first use, instead of running a new `bw` command for each of them.
+func WithServe() Options {
+	return func(c bitwarden.PasswordManager) {
+		c.(*client).server = newServer(c.(*client).retryHandler)
+	}
+}
+
+// WithListCache serves reads from a single `bw list` per object type for the
+// lifetime of the client, instead of running a command for each of them.
+func WithListCache() Options {
+	return func(c bitwarden.PasswordManager) {
+		c.(*client).listCache = newListCache()
+	}
+}
+
+// WithMaxParallelReads sets how many commands reading the Vault can run at
+// the same time.
+func WithMaxParallelReads(reads int) Options {
+	return func(c bitwarden.PasswordManager) {
+		c.(*client).schedulerOpts = append(c.(*client).schedulerOpts, command.WithMaxParallelReads(reads))
+	}
+}
+
+// WithMaxParallelWrites sets how many commands writing to the Vault can run at
+// the same time. Writes never run alongside reads.
+func WithMaxParallelWrites(writes int) Options {
+	return func(c bitwarden.PasswordManager) {
+		c.(*client).schedulerOpts = append(c.(*client).schedulerOpts, command.WithMaxParallelWrites(writes))
+	}
+}
+
+// WithCommandTimeout sets how long a command can run before being killed.
+func WithCommandTimeout(timeout time.Duration) Options {
+	return func(c bitwarden.PasswordManager) {
+		c.(*client).schedulerOpts = append(c.(*client).schedulerOpts, command.WithCommandTimeout(timeout))
+	}
+}
+
+// WithSessionRecovery sets how to log in or unlock the Vault again when a
+// command fails because it got locked or logged out while the client was
+// used, e.g. after its session expired. The command is then retried once.
+func WithSessionRecovery(recoverSession func(ctx context.Context, c PasswordManagerClient) error) Options {
+	return func(c bitwarden.PasswordManager) {
+		c.(*client).recoverSession = recoverSession
+	}
+}
+
 func DisableSync() Options {
 	return func(c bitwarden.PasswordManager) {
 		c.(*client).disableSync = true
@@ -102,6 +192,28 @@ func DisableRetryBackoff() Options {
 	}
 }
 
+// WithMaxRetries sets how many times a command failing because of rate
+// limiting is attempted.
+func WithMaxRetries(maxRetries int) Options {
+	return func(c bitwarden.PasswordManager) {
+		c.(*client).retryHandler.maxRetries = maxRetries
+	}
+}
+
+// WithRetryBackoffFactor sets the base of the exponential backoff between
+// attempts, in seconds.
+func WithRetryBackoffFactor(backoffFactor float64) Options {
+	return func(c bitwarden.PasswordManager) {
+		c.(*client).retryHandler.backoffFactor = backoffFactor
+	}
+}
+
+func DisableRateLimitRetries() Options {
+	return func(c bitwarden.PasswordManager) {
+		c.(*client).retryHandler.disableRateLimitRetries = true
+	}
+}
+
 func (c *client) CreateAttachmentFromFile(ctx context.Context, itemId string, filePath string) (*models.Attachment, error) {
 	// The CLI returns the entire item after adding an attachment to it. There is no way to know
 	// which attachment was added, besides comparing the list of attachments before and after the
@@ -117,7 +229,8 @@ func (c *client) CreateAttachmentFromFile(ctx context.Context, itemId string, fi
 	}
 	existingAttachmentIDs := getAttachmentIDs(*obj)
 
-	out, err := c.cmdWithSession("create", string(models.ObjectTypeAttachment), "--itemid", itemId, "--file", filePath).Run(ctx)
+	out, err := c.run(ctx, "create", string(models.ObjectTypeAttachment), "--itemid", itemId, "--file", filePath)
+	c.listCache.invalidate(models.ObjectTypeAttachment)
 	if err != nil {
 		return nil, err
 	}
@@ -188,9 +301,10 @@ func createObject[T any](ctx context.Context, c *client, obj T, objectType model
 	case models.OrgCollection:
 		args = append(args, "--organizationid", orgObj.OrganizationID)
 	}
-	out, err := c.cmdWithSession(args...).Run(ctx)
+	out, err := c.run(ctx, args...)
+	c.listCache.invalidate(objectType)
 	if err != nil {
-		return nil, err
+		return nil, models.WithObject(err, objectType, "")
 	}
 	err = json.Unmarshal(out, &obj)
 	if err != nil {
@@ -237,9 +351,10 @@ func editGenericObject[T any](ctx context.Context, c *client, obj T, objectType
 
 	args = append(args, []string{objEncoded}...)
 
-	out, err := c.cmdWithSession(args...).Run(ctx)
+	out, err := c.run(ctx, args...)
+	c.listCache.invalidate(objectType)
 	if err != nil {
-		return nil, err
+		return nil, models.WithObject(err, objectType, id)
 	}
 	err = json.Unmarshal(out, &obj)
 	if err != nil {
@@ -265,7 +380,8 @@ func (c *client) editItemCollections(ctx context.Context, objId string, collecti
 		objId,
 		string(collectionIdsJSON),
 	}
-	out, err := c.cmdWithSession(args...).Run(ctx)
+	out, err := c.run(ctx, args...)
+	c.listCache.invalidate(models.ObjectTypeItem)
 	if err != nil {
 		return nil, fmt.Errorf("error editing item collections: %w", err)
 	}
@@ -282,9 +398,9 @@ func (c *client) editItemCollections(ctx context.Context, objId string, collecti
 }
 
 func (c *client) GetAttachment(ctx context.Context, itemId, attachmentId string) ([]byte, error) {
-	out, err := c.cmdWithSession("get", string(models.ObjectTypeAttachment), attachmentId, "--itemid", itemId, "--raw").Run(ctx)
+	out, err := c.run(ctx, "get", string(models.ObjectTypeAttachment), attachmentId, "--itemid", itemId, "--raw")
 	if err != nil {
-		return nil, remapError(err)
+		return nil, models.WithObject(remapError(err), models.ObjectTypeAttachment, attachmentId)
 	}
 
 	return out, nil
@@ -333,9 +449,15 @@ func getObject[T any](ctx context.Context, c *client, obj T, objectType models.O
 		desiredObjType = itemObj.Type
 	}
 
-	out, err := c.cmdWithSession(args...).Run(ctx)
+	out, cached, err := c.listCache.get(ctx, c, objectType, id)
 	if err != nil {
-		return nil, remapError(err)
+		return nil, models.WithObject(err, objectType, id)
+	}
+	if !cached {
+		out, err = c.run(ctx, args...)
+		if err != nil {
+			return nil, models.WithObject(remapError(err), objectType, id)
+		}
 	}
 
 	err = json.Unmarshal(out, &obj)
@@ -354,6 +476,8 @@ func getObject[T any](ctx context.Context, c *client, obj T, objectType models.O
 }
 
 func (c *client) GetSessionKey() string {
+	c.sessionKeyMutex.RLock()
+	defer c.sessionKeyMutex.RUnlock()
 	return c.sessionKey
 }
 
@@ -389,9 +513,16 @@ func findGenericObject[T any](ctx context.Context, c *client, objType models.Obj
 
 	applyFiltersToArgs(&args, options...)
 
-	out, err := c.cmdWithSession(args...).Run(ctx)
+	filters := bitwarden.ListObjectsOptionsToFilterOptions(options...)
+	out, cached, err := c.listCache.list(ctx, c, objType, filters)
 	if err != nil {
-		return nil, remapError(err)
+		return nil, err
+	}
+	if !cached {
+		out, err = c.run(ctx, args...)
+		if err != nil {
+			return nil, remapError(err)
+		}
 	}
 
 	var foundObjects []T
@@ -400,7 +531,6 @@ func findGenericObject[T any](ctx context.Context, c *client, objType models.Obj
 		return nil, newUnmarshallError(err, args[0:2], out)
 	}
 
-	filters := bitwarden.ListObjectsOptionsToFilterOptions(options...)
 	filteredObj := []T{}
 	for _, obj := range foundObjects {
 		switch itemObj := any(obj).(type) {
@@ -428,7 +558,7 @@ func (c *client) LoginWithPassword(ctx context.Context, username, password strin
 	if err != nil {
 		return err
 	}
-	c.sessionKey = string(out)
+	c.SetSessionKey(string(out))
 	return nil
 }
 
@@ -443,13 +573,18 @@ func (c *client) LoginWithAPIKey(ctx context.Context, password, clientId, client
 }
 
 func (c *client) Logout(ctx context.Context) error {
+	c.listCache.reset()
+	if c.server != nil {
+		c.server.stop()
+	}
 	_, err := c.cmd("logout").Run(ctx)
 	return err
 }
 
 func (c *client) DeleteFolder(ctx context.Context, obj models.Folder) error {
-	_, err := c.cmdWithSession("delete", string(models.ObjectTypeFolder), obj.ID).Run(ctx)
-	return err
+	_, err := c.run(ctx, "delete", string(models.ObjectTypeFolder), obj.ID)
+	c.listCache.invalidate(models.ObjectTypeFolder)
+	return models.WithObject(err, models.ObjectTypeFolder, obj.ID)
 }
 
 func (c *client) DeleteOrganizationGroup(ctx context.Context, obj models.OrgGroup) error {
@@ -457,23 +592,37 @@ func (c *client) DeleteOrganizationGroup(ctx context.Context, obj models.OrgGrou
 }
 
 func (c *client) DeleteItem(ctx context.Context, obj models.Item) error {
-	_, err := c.cmdWithSession("delete", string(models.ObjectTypeItem), obj.ID).Run(ctx)
-	return err
+	_, err := c.run(ctx, "delete", string(models.ObjectTypeItem), obj.ID)
+	c.listCache.invalidate(models.ObjectTypeItem)
+	return models.WithObject(err, models.ObjectTypeItem, obj.ID)
 }
 
 func (c *client) DeleteOrganizationCollection(ctx context.Context, obj models.OrgCollection) error {
-	_, err := c.cmdWithSession("delete", string(models.ObjectTypeOrgCollection), obj.ID, "--organizationid", obj.OrganizationID).Run(ctx)
-	return err
+	_, err := c.run(ctx, "delete", string(models.ObjectTypeOrgCollection), obj.ID, "--organizationid", obj.OrganizationID)
+	c.listCache.invalidate(models.ObjectTypeOrgCollection)
+	return models.WithObject(err, models.ObjectTypeOrgCollection, obj.ID)
 }
 
 func (c *client) DeleteAttachment(ctx context.Context, itemId, attachmentId string) error {
 	// TODO: Don't fail if attachment is already gone
-	_, err := c.cmdWithSession("delete", string(models.ObjectTypeAttachment), attachmentId, "--itemid", itemId).Run(ctx)
-	return err
+	_, err := c.run(ctx, "delete", string(models.ObjectTypeAttachment), attachmentId, "--itemid", itemId)
+	c.listCache.invalidate(models.ObjectTypeAttachment)
+	return models.WithObject(err, models.ObjectTypeAttachment, attachmentId)
 }
 
 func (c *client) SetServer(ctx context.Context, server string) error {
-	_, err := c.cmd("config", "server", server).Run(ctx)
+	args := []string{"config", "server", server}
+	if len(c.apiURL) > 0 {
+		args = append(args, "--api", c.apiURL)
+	}
+	if len(c.identityURL) > 0 {
+		args = append(args, "--identity", c.identityURL)
+	}
+	if len(c.eventsURL) > 0 {
+		args = append(args, "--events", c.eventsURL)
+	}
+
+	_, err := c.cmd(args...).Run(ctx)
 	return err
 }
 
@@ -498,15 +647,26 @@ func (c *client) Unlock(ctx context.Context, password string) error {
 		return err
 	}
 
-	c.sessionKey = string(out)
+	c.SetSessionKey(string(out))
 	return nil
 }
 
+// Version returns the version of the Bitwarden CLI, e.g. "2025.4.0".
+func (c *client) Version(ctx context.Context) (string, error) {
+	out, err := c.cmd("--version").Run(ctx)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 func (c *client) HasSessionKey() bool {
-	return len(c.sessionKey) > 0
+	return len(c.GetSessionKey()) > 0
 }
 
 func (c *client) SetSessionKey(sessionKey string) {
+	c.sessionKeyMutex.Lock()
+	defer c.sessionKeyMutex.Unlock()
 	c.sessionKey = sessionKey
 }
 
@@ -514,16 +674,72 @@ func (c *client) Sync(ctx context.Context) error {
 	if c.disableSync {
 		return nil
 	}
-	_, err := c.cmdWithSession("sync").Run(ctx)
+	_, err := c.run(ctx, "sync")
+	c.listCache.reset()
 	return err
 }
 
+// run runs a command, and retries it once after recovering the session when
+// the Vault got locked or logged out in the meantime.
+func (c *client) run(ctx context.Context, args ...string) ([]byte, error) {
+	sessionKey := c.GetSessionKey()
+	out, err := c.runWithSession(ctx, sessionKey, args...)
+	if err == nil || c.recoverSession == nil || ctx.Value(recoveringSessionKey{}) != nil || !isSessionLostError(err) {
+		return out, err
+	}
+
+	tflog.Warn(ctx, "Vault was locked or logged out, unlocking it again", map[string]interface{}{"command": args[0], "error": err})
+	if recoveryErr := c.recover(ctx, sessionKey); recoveryErr != nil {
+		return nil, fmt.Errorf("%w, and unlocking the Vault again failed: %w", err, recoveryErr)
+	}
+	return c.runWithSession(ctx, c.GetSessionKey(), args...)
+}
+
+// recover logs in or unlocks the Vault again, unless another command already
+// did it since the session was lost.
+func (c *client) recover(ctx context.Context, lostSessionKey string) error {
+	c.recoveryMutex.Lock()
+	defer c.recoveryMutex.Unlock()
+
+	if c.GetSessionKey() != lostSessionKey {
+		return nil
+	}
+
+	if err := c.recoverSession(context.WithValue(ctx, recoveringSessionKey{}, true), c); err != nil {
+		return err
+	}
+	c.listCache.reset()
+	if c.server != nil {
+		// The process unlocked the Vault when it started, and is restarted
+		// on next use.
+		c.server.stop()
+	}
+	return nil
+}
+
+// runWithSession sends the command to `bw serve` when enabled and part of its
+// API, or runs it otherwise.
+func (c *client) runWithSession(ctx context.Context, sessionKey string, args ...string) ([]byte, error) {
+	var (
+		out []byte
+		err error
+	)
+	if req, ok := newServeRequest(args); ok && c.server != nil {
+		out, err = c.scheduler.Run(ctx, args, isWriteCommand(args), func(ctx context.Context) ([]byte, error) {
+			return c.server.do(ctx, c.env(), sessionKey, req)
+		})
+	} else {
+		out, err = c.cmd(args...).AppendEnv([]string{fmt.Sprintf("BW_SESSION=%s", sessionKey)}).Run(ctx)
+	}
+	return out, classifyError(err)
+}
+
 func (c *client) cmd(args ...string) command.Command {
 	return c.newCommand("bw", args...).AppendEnv(c.env())
 }
 
 func (c *client) cmdWithSession(args ...string) command.Command {
-	return c.cmd(args...).AppendEnv([]string{fmt.Sprintf("BW_SESSION=%s", c.sessionKey)})
+	return c.cmd(args...).AppendEnv([]string{fmt.Sprintf("BW_SESSION=%s", c.GetSessionKey())})
 }
 
 func (c *client) env() []string {
@@ -544,6 +760,38 @@ func (c *client) env() []string {
 	return defaultEnv
 }
 
+// lockFilePath returns the file locked while commands run, in the data
+// directory of the CLI. Nothing is locked when the default data directory
+// doesn't exist yet, to avoid creating it.
+func (c *client) lockFilePath() string {
+	if len(c.appDataDir) > 0 {
+		return filepath.Join(c.appDataDir, lockFileName)
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	appDataDir := filepath.Join(configDir, defaultAppDataDirName)
+	if _, err := os.Stat(appDataDir); err != nil {
+		return ""
+	}
+	return filepath.Join(appDataDir, lockFileName)
+}
+
+// isWriteCommand returns true for commands changing the data directory of the
+// CLI, which can't run alongside other commands.
+func isWriteCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "get", "list", "status", "--version":
+		return false
+	}
+	return true
+}
+
 func envKV(key string) string {
 	return fmt.Sprintf("%s=%s", key, os.Getenv(key))
 }
@@ -3,6 +3,7 @@
 package bwcli
 
 import (
+	"context"
 	"strings"
 	"testing"
 
@@ -167,3 +168,87 @@ func TestSettingAppDataDir(t *testing.T) {
 	assert.Contains(t, pwWithoutCustomAppDataDir.env(), envKV("XDG_CONFIG_HOME"))
 	assert.Contains(t, pwWithoutCustomAppDataDir.env(), envKV("APPDATA"))
 }
+
+func TestSetServerWithServerURLs(t *testing.T) {
+	removeMocks, commandsExecuted := test_command.MockCommands(t, map[string]string{
+		"config server https://vault.example.com --api https://api.example.com --identity https://identity.example.com": ``,
+	})
+	defer removeMocks(t)
+
+	b := NewPasswordManagerClient(WithServerURLs("https://api.example.com", "https://identity.example.com", ""))
+	err := b.SetServer(t.Context(), "https://vault.example.com")
+
+	assert.NoError(t, err)
+	if assert.Len(t, commandsExecuted(), 1) {
+		assert.Equal(t, "config server https://vault.example.com --api https://api.example.com --identity https://identity.example.com", commandsExecuted()[0])
+	}
+}
+
+func TestErrorsAreClassified(t *testing.T) {
+	removeMocks, _ := test_command.MockCommands(t, map[string]string{
+		"delete item item-id @error":   `You do not have permission to delete this item.`,
+		"get folder folder-id @error":  `Not found.`,
+		"list items --search x @error": `FetchError: request to https://vault.example.com/api/sync failed, reason: getaddrinfo ENOTFOUND vault.example.com`,
+	})
+	defer removeMocks(t)
+
+	b := NewPasswordManagerClient()
+
+	err := b.DeleteItem(t.Context(), models.Item{ID: "item-id"})
+	assert.ErrorIs(t, err, models.ErrorKindPermissionDenied)
+	assert.ErrorContains(t, err, "You do not have permission to delete this item.")
+	objectType, objectID := models.ObjectOf(err)
+	assert.Equal(t, models.ObjectTypeItem, objectType)
+	assert.Equal(t, "item-id", objectID)
+
+	_, err = b.GetFolder(t.Context(), models.Folder{ID: "folder-id", Object: models.ObjectTypeFolder})
+	assert.ErrorIs(t, err, models.ErrObjectNotFound)
+	assert.ErrorIs(t, err, models.ErrorKindNotFound)
+	objectType, objectID = models.ObjectOf(err)
+	assert.Equal(t, models.ObjectTypeFolder, objectType)
+	assert.Equal(t, "folder-id", objectID)
+
+	_, err = b.FindItem(t.Context(), bitwarden.WithSearch("x"))
+	assert.ErrorIs(t, err, models.ErrorKindTransportFailure)
+}
+
+func TestSessionIsRecoveredOnceWhenVaultGetsLocked(t *testing.T) {
+	removeMocks, commandsExecuted := test_command.MockCommands(t, map[string]string{
+		"delete item item-id @error": `Vault is locked.`,
+		"sync":                       ``,
+	})
+	defer removeMocks(t)
+
+	recoveries := 0
+	b := NewPasswordManagerClient(WithSessionRecovery(func(ctx context.Context, c PasswordManagerClient) error {
+		recoveries++
+		c.SetSessionKey("new-session-key")
+		// Commands run while recovering aren't recovered again.
+		return c.Sync(ctx)
+	}))
+	b.SetSessionKey("expired-session-key")
+
+	err := b.DeleteItem(t.Context(), models.Item{ID: "item-id"})
+	assert.ErrorContains(t, err, "Vault is locked.")
+	assert.Equal(t, 1, recoveries)
+	assert.Equal(t, "new-session-key", b.GetSessionKey())
+	assert.Equal(t, []string{"delete item item-id", "sync", "delete item item-id"}, commandsExecuted())
+}
+
+func TestSessionRecoveryFailureIsReported(t *testing.T) {
+	removeMocks, commandsExecuted := test_command.MockCommands(t, map[string]string{
+		"get folder folder-id @error": `You are not logged in.`,
+	})
+	defer removeMocks(t)
+
+	b := NewPasswordManagerClient(WithSessionRecovery(func(ctx context.Context, c PasswordManagerClient) error {
+		return models.ErrWrongMasterPassword
+	}))
+
+	_, err := b.GetFolder(t.Context(), models.Folder{ID: "folder-id", Object: models.ObjectTypeFolder})
+	assert.ErrorContains(t, err, "You are not logged in.")
+	assert.ErrorContains(t, err, "unlocking the Vault again failed: invalid master password")
+	assert.ErrorIs(t, err, models.ErrWrongMasterPassword)
+	assert.ErrorIs(t, err, models.ErrorKindAuthFailure)
+	assert.Equal(t, []string{"get folder folder-id"}, commandsExecuted())
+}
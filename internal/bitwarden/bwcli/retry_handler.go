@@ -8,14 +8,32 @@ import (
 
 const (
 	rateLimitExceededError = "Rate limit exceeded."
+
+	defaultMaxRetries         = 2
+	defaultRetryBackoffFactor = 2
 )
 
 type retryHandler struct {
-	disableRetryBackoff bool
+	disableRetryBackoff     bool
+	disableRateLimitRetries bool
+	maxRetries              int
+	backoffFactor           float64
 }
 
+func newRetryHandler() *retryHandler {
+	return &retryHandler{
+		maxRetries:    defaultMaxRetries,
+		backoffFactor: defaultRetryBackoffFactor,
+	}
+}
+
+// IsRetryable tells whether a command can be retried after the given attempt,
+// the first one being 1. Commands are retried up to maxRetries times.
 func (r *retryHandler) IsRetryable(err error, attempt int) bool {
-	return strings.Contains(err.Error(), rateLimitExceededError) && attempt < 3
+	if r.disableRateLimitRetries {
+		return false
+	}
+	return strings.Contains(err.Error(), rateLimitExceededError) && attempt <= r.maxRetries
 }
 
 func (r *retryHandler) Backoff(attempt int) time.Duration {
@@ -24,7 +42,7 @@ func (r *retryHandler) Backoff(attempt int) time.Duration {
 	}
 
 	maxInterval := 30 * time.Second
-	delay := time.Duration(math.Pow(2, float64(attempt))) * time.Second
+	delay := time.Duration(math.Pow(r.backoffFactor, float64(attempt))) * time.Second
 	if delay > maxInterval {
 		delay = maxInterval
 	}
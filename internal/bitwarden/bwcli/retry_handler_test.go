@@ -0,0 +1,33 @@
+//go:build offline
+
+package bwcli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryHandlerIsRetryable(t *testing.T) {
+	rateLimitErr := errors.New("Rate limit exceeded.")
+
+	defaultHandler := NewPasswordManagerClient().(*client).retryHandler
+	assert.True(t, defaultHandler.IsRetryable(rateLimitErr, 2))
+	assert.False(t, defaultHandler.IsRetryable(rateLimitErr, 3))
+	assert.False(t, defaultHandler.IsRetryable(errors.New("Not found."), 1))
+
+	customHandler := NewPasswordManagerClient(WithMaxRetries(5)).(*client).retryHandler
+	assert.True(t, customHandler.IsRetryable(rateLimitErr, 5))
+	assert.False(t, customHandler.IsRetryable(rateLimitErr, 6))
+
+	singleRetryHandler := NewPasswordManagerClient(WithMaxRetries(1)).(*client).retryHandler
+	assert.True(t, singleRetryHandler.IsRetryable(rateLimitErr, 1))
+	assert.False(t, singleRetryHandler.IsRetryable(rateLimitErr, 2))
+
+	noRetryHandler := NewPasswordManagerClient(WithMaxRetries(0)).(*client).retryHandler
+	assert.False(t, noRetryHandler.IsRetryable(rateLimitErr, 1))
+
+	disabledHandler := NewPasswordManagerClient(DisableRateLimitRetries()).(*client).retryHandler
+	assert.False(t, disabledHandler.IsRetryable(rateLimitErr, 1))
+}
@@ -0,0 +1,414 @@
+package bwcli
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/redact"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+const (
+	serveSocketName   = "bw-serve.sock"
+	serveStartTimeout = 30 * time.Second
+	serveStopTimeout  = 5 * time.Second
+)
+
+var (
+	runningServersMu sync.Mutex
+	runningServers   = map[*server]struct{}{}
+)
+
+// serveProcess is a running `bw serve`.
+type serveProcess struct {
+	baseURL    string
+	httpClient *http.Client
+	exited     <-chan error
+	stop       func() error
+}
+
+// startServe is only meant to be changed during tests. As `bw serve` doesn't
+// authenticate requests, it listens on a unix socket in a directory only the
+// current user can open, instead of a port any local process could reach.
+var startServe = func(env []string) (*serveProcess, error) {
+	socketDir, err := os.MkdirTemp("", "bw-serve-")
+	if err != nil {
+		return nil, fmt.Errorf("error creating the directory of the socket of 'bw serve': %w", err)
+	}
+	socketPath := filepath.Join(socketDir, serveSocketName)
+
+	var stdErr bytes.Buffer
+	cmd := exec.Command("bw", "serve", "--hostname", "unix:"+socketPath)
+	cmd.Env = env
+	cmd.Stderr = &stdErr
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(socketDir)
+		return nil, fmt.Errorf("error starting 'bw serve': %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		os.RemoveAll(socketDir)
+		if err != nil && stdErr.Len() > 0 {
+			err = fmt.Errorf("%w: %s", err, strings.TrimSpace(stdErr.String()))
+		}
+		exited <- err
+		close(exited)
+	}()
+
+	return &serveProcess{
+		// The host is ignored, requests are sent to the socket.
+		baseURL: "http://bw-serve",
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+		exited: exited,
+		stop: func() error {
+			select {
+			case <-exited:
+				return nil
+			default:
+			}
+
+			// Interrupting lets the CLI flush its state, which isn't supported
+			// on Windows.
+			if err := cmd.Process.Signal(os.Interrupt); err != nil {
+				return cmd.Process.Kill()
+			}
+			select {
+			case <-exited:
+				return nil
+			case <-time.After(serveStopTimeout):
+				return cmd.Process.Kill()
+			}
+		},
+	}, nil
+}
+
+// server talks to the Vault Management API of a `bw serve` process, started
+// on first use and shared by all the operations of a client. The process
+// unlocks the Vault with a session key, and is restarted when it changes.
+type server struct {
+	mu           sync.Mutex
+	process      *serveProcess
+	retryHandler *retryHandler
+	sessionKey   string
+}
+
+func newServer(retryHandler *retryHandler) *server {
+	return &server{
+		retryHandler: retryHandler,
+	}
+}
+
+// Shutdown stops all the `bw serve` processes started by the clients. It is
+// meant to be called when the plugin stops.
+func Shutdown() {
+	runningServersMu.Lock()
+	servers := make([]*server, 0, len(runningServers))
+	for s := range runningServers {
+		servers = append(servers, s)
+	}
+	runningServersMu.Unlock()
+
+	for _, s := range servers {
+		s.stop()
+	}
+}
+
+func (s *server) start(ctx context.Context, env []string, sessionKey string) (*serveProcess, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.process != nil && s.sessionKey == sessionKey {
+		select {
+		case err := <-s.process.exited:
+			tflog.Warn(ctx, "'bw serve' exited unexpectedly, restarting it", map[string]interface{}{"error": err})
+		default:
+			return s.process, nil
+		}
+	}
+	s.stopLocked()
+
+	tflog.Debug(ctx, "Starting 'bw serve'")
+	process, err := startServe(append(env, fmt.Sprintf("BW_SESSION=%s", sessionKey)))
+	if err != nil {
+		return nil, err
+	}
+	s.process = process
+	s.sessionKey = sessionKey
+
+	runningServersMu.Lock()
+	runningServers[s] = struct{}{}
+	runningServersMu.Unlock()
+
+	if err := s.waitUntilReady(ctx); err != nil {
+		s.stopLocked()
+		return nil, err
+	}
+	return process, nil
+}
+
+func (s *server) waitUntilReady(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, serveStartTimeout)
+	defer cancel()
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.process.baseURL+"/status", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := s.process.httpClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+
+		select {
+		case err := <-s.process.exited:
+			return fmt.Errorf("'bw serve' exited before being ready: %v", err)
+		case <-ctx.Done():
+			return fmt.Errorf("'bw serve' wasn't ready after %s: %w", serveStartTimeout, ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func (s *server) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopLocked()
+}
+
+func (s *server) stopLocked() {
+	if s.process == nil {
+		return
+	}
+
+	runningServersMu.Lock()
+	delete(runningServers, s)
+	runningServersMu.Unlock()
+
+	if err := s.process.stop(); err != nil {
+		tflog.Warn(context.Background(), "Unable to stop 'bw serve'", map[string]interface{}{"error": err})
+	}
+	s.process = nil
+	s.sessionKey = ""
+}
+
+// serveRequest is the Vault Management API equivalent of a `bw` command.
+type serveRequest struct {
+	args   []string
+	method string
+	path   string
+	query  url.Values
+	body   []byte
+	file   string
+	list   bool
+	raw    bool
+}
+
+// newServeRequest translates the arguments of a `bw` command into a request
+// to `bw serve`. Commands managing the CLI's own state (login, unlock, config,
+// ...) aren't part of the API and return false.
+func newServeRequest(args []string) (*serveRequest, bool) {
+	positional := []string{}
+	req := &serveRequest{args: args, query: url.Values{}}
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--raw":
+			req.raw = true
+		case strings.HasPrefix(args[i], "--") && i+1 < len(args):
+			req.query.Set(strings.TrimPrefix(args[i], "--"), args[i+1])
+			i++
+		default:
+			positional = append(positional, args[i])
+		}
+	}
+
+	switch {
+	case len(positional) == 1 && positional[0] == "sync":
+		req.method, req.path = http.MethodPost, "/sync"
+	case len(positional) == 3 && positional[0] == "get":
+		req.method, req.path = http.MethodGet, fmt.Sprintf("/object/%s/%s", positional[1], url.PathEscape(positional[2]))
+	case len(positional) == 2 && positional[0] == "list":
+		req.method, req.path, req.list = http.MethodGet, fmt.Sprintf("/list/object/%s", positional[1]), true
+	case len(positional) == 2 && positional[0] == "create" && positional[1] == "attachment":
+		req.method, req.path, req.file = http.MethodPost, "/attachment", req.query.Get("file")
+		req.query.Del("file")
+	case len(positional) == 3 && positional[0] == "create":
+		body, err := base64.RawStdEncoding.DecodeString(positional[2])
+		if err != nil {
+			return nil, false
+		}
+		req.method, req.path, req.body = http.MethodPost, fmt.Sprintf("/object/%s", positional[1]), body
+	case len(positional) == 4 && positional[0] == "edit":
+		body, err := base64.RawStdEncoding.DecodeString(positional[3])
+		if err != nil {
+			return nil, false
+		}
+		req.method, req.path, req.body = http.MethodPut, fmt.Sprintf("/object/%s/%s", positional[1], url.PathEscape(positional[2])), body
+	case len(positional) == 3 && positional[0] == "delete":
+		req.method, req.path = http.MethodDelete, fmt.Sprintf("/object/%s/%s", positional[1], url.PathEscape(positional[2]))
+	default:
+		return nil, false
+	}
+	return req, true
+}
+
+// serveResponse is the envelope of every JSON response of `bw serve`.
+type serveResponse struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type serveListResponse struct {
+	Data json.RawMessage `json:"data"`
+}
+
+// serveError is returned when `bw serve` rejects a request. The message is
+// the one the CLI would print on stderr for the same command.
+type serveError struct {
+	args       []string
+	statusCode int
+	message    string
+}
+
+func (e *serveError) Error() string {
+	return fmt.Sprintf("'bw serve' returned %d while running '%s': %s", e.statusCode, strings.Join(redact.Args(e.args), " "), e.message)
+}
+
+// do sends a request to `bw serve` and returns what the equivalent command
+// would have printed, so that callers don't need to know which was used.
+func (s *server) do(ctx context.Context, env []string, sessionKey string, req *serveRequest) (out []byte, err error) {
+	ctx, span := tracing.Start(ctx, fmt.Sprintf("bw %s", req.args[0]),
+		semconv.ProcessCommandArgs(redact.Args(req.args)...),
+		attribute.Bool("bitwarden.cli.serve", true),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	process, err := s.start(ctx, env, sessionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := 0
+	for {
+		attempts = attempts + 1
+		out, err := s.send(ctx, process, req)
+		if err == nil || !s.retryHandler.IsRetryable(err, attempts) {
+			return out, err
+		}
+		s.retryHandler.Backoff(attempts)
+		tflog.Error(ctx, "Retrying request after error", map[string]interface{}{"error": err})
+	}
+}
+
+func (s *server) send(ctx context.Context, process *serveProcess, req *serveRequest) ([]byte, error) {
+	ctx = tflog.SetField(ctx, "command", redact.Args(req.args))
+	tflog.Debug(ctx, "Sending request to 'bw serve'")
+
+	body, contentType, err := req.encodeBody()
+	if err != nil {
+		return nil, err
+	}
+
+	reqURL := process.baseURL + req.path
+	if len(req.query) > 0 {
+		reqURL = reqURL + "?" + req.query.Encode()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, reqURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if len(contentType) > 0 {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := process.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request to 'bw serve': %w", err)
+	}
+	defer resp.Body.Close()
+
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response of 'bw serve': %w", err)
+	}
+
+	if req.raw && resp.StatusCode == http.StatusOK && !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return out, nil
+	}
+
+	var envelope serveResponse
+	if err := json.Unmarshal(out, &envelope); err != nil {
+		return nil, newUnmarshallError(err, req.args, out)
+	}
+	if !envelope.Success {
+		return nil, &serveError{args: req.args, statusCode: resp.StatusCode, message: envelope.Message}
+	}
+	tflog.Debug(ctx, "Request to 'bw serve' finished with success")
+
+	if req.list {
+		var list serveListResponse
+		if err := json.Unmarshal(envelope.Data, &list); err != nil {
+			return nil, newUnmarshallError(err, req.args, out)
+		}
+		return list.Data, nil
+	}
+	return envelope.Data, nil
+}
+
+func (req *serveRequest) encodeBody() (io.Reader, string, error) {
+	if len(req.file) > 0 {
+		content, err := os.ReadFile(req.file)
+		if err != nil {
+			return nil, "", err
+		}
+
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, err := writer.CreateFormFile("file", filepath.Base(req.file))
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(content); err != nil {
+			return nil, "", err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, "", err
+		}
+		return &body, writer.FormDataContentType(), nil
+	}
+
+	if req.body != nil {
+		return bytes.NewReader(req.body), "application/json", nil
+	}
+	return nil, "", nil
+}
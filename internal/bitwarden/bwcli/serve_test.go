@@ -0,0 +1,242 @@
+//go:build offline
+
+package bwcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockServe replaces `bw serve` with a test server answering with the given
+// handler, and returns the requests it received and the sessions it was
+// started with.
+func mockServe(t *testing.T, handler http.HandlerFunc) (func() []string, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	requests := []string{}
+	sessions := []string{}
+
+	startServeToRestore := startServe
+	startServe = func(env []string) (*serveProcess, error) {
+		mu.Lock()
+		for _, e := range env {
+			if sessionKey, ok := strings.CutPrefix(e, "BW_SESSION="); ok {
+				sessions = append(sessions, sessionKey)
+			}
+		}
+		mu.Unlock()
+
+		httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/status" {
+				writeServeResponse(w, map[string]string{"status": "unlocked"})
+				return
+			}
+			mu.Lock()
+			requests = append(requests, fmt.Sprintf("%s %s", r.Method, r.URL.RequestURI()))
+			mu.Unlock()
+			handler(w, r)
+		}))
+		return &serveProcess{
+			baseURL:    httpServer.URL,
+			httpClient: httpServer.Client(),
+			exited:     make(chan error),
+			stop: func() error {
+				httpServer.Close()
+				return nil
+			},
+		}, nil
+	}
+	t.Cleanup(func() {
+		Shutdown()
+		startServe = startServeToRestore
+	})
+
+	return func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return slices.Clone(requests)
+		}, func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return slices.Clone(sessions)
+		}
+}
+
+func writeServeResponse(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": data})
+}
+
+func writeServeError(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": message})
+}
+
+func TestServeSharesOneProcess(t *testing.T) {
+	requests, sessions := mockServe(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/object/item/item-id":
+			if r.Method == http.MethodPut {
+				var item models.Item
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&item))
+				writeServeResponse(w, item)
+				return
+			}
+			writeServeResponse(w, models.Item{ID: "item-id", Name: "Item", Type: models.ItemTypeLogin})
+		case "/list/object/items":
+			writeServeResponse(w, map[string]interface{}{"object": "list", "data": []models.Item{{ID: "item-id", Type: models.ItemTypeLogin}}})
+		case "/sync":
+			writeServeResponse(w, map[string]string{"object": "message", "title": "Syncing complete."})
+		default:
+			writeServeError(w, "Not found.")
+		}
+	})
+
+	b := NewPasswordManagerClient(WithServe())
+	b.SetSessionKey("session-key")
+
+	item, err := b.GetItem(t.Context(), models.Item{ID: "item-id", Object: models.ObjectTypeItem, Type: models.ItemTypeLogin})
+	require.NoError(t, err)
+	assert.Equal(t, "Item", item.Name)
+
+	_, err = b.FindItem(t.Context(), bitwarden.WithOrganizationID("org-id"), bitwarden.WithSearch("Item"))
+	require.NoError(t, err)
+
+	item.Name = "Renamed"
+	item, err = b.EditItem(t.Context(), *item)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", item.Name)
+
+	_, err = b.GetFolder(t.Context(), models.Folder{ID: "missing", Object: models.ObjectTypeFolder})
+	assert.ErrorIs(t, err, models.ErrObjectNotFound)
+
+	assert.Equal(t, []string{"session-key"}, sessions())
+	assert.Equal(t, []string{
+		"GET /object/item/item-id",
+		"GET /list/object/items?organizationid=org-id&search=Item",
+		"PUT /object/item/item-id",
+		"POST /sync",
+		"GET /object/folder/missing",
+	}, requests())
+}
+
+func TestServeRestartsWithNewSessionKey(t *testing.T) {
+	_, sessions := mockServe(t, func(w http.ResponseWriter, r *http.Request) {
+		writeServeResponse(w, map[string]string{"object": "message", "title": "Syncing complete."})
+	})
+
+	b := NewPasswordManagerClient(WithServe())
+	b.SetSessionKey("first")
+	require.NoError(t, b.Sync(t.Context()))
+	require.NoError(t, b.Sync(t.Context()))
+
+	b.SetSessionKey("second")
+	require.NoError(t, b.Sync(t.Context()))
+
+	assert.Equal(t, []string{"first", "second"}, sessions())
+}
+
+func TestServeAttachments(t *testing.T) {
+	requests, _ := mockServe(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/object/attachment/attachment-id":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write([]byte("content"))
+		default:
+			writeServeError(w, "Attachment `other-id` was not found.")
+		}
+	})
+
+	b := NewPasswordManagerClient(WithServe())
+	content, err := b.GetAttachment(t.Context(), "item-id", "attachment-id")
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+
+	_, err = b.GetAttachment(t.Context(), "item-id", "other-id")
+	assert.ErrorIs(t, err, models.ErrAttachmentNotFound)
+
+	assert.Equal(t, []string{
+		"GET /object/attachment/attachment-id?itemid=item-id",
+		"GET /object/attachment/other-id?itemid=item-id",
+	}, requests())
+}
+
+func TestNewServeRequestIgnoresCLICommands(t *testing.T) {
+	for _, args := range [][]string{
+		{"status"},
+		{"login", "--apikey"},
+		{"unlock", "--raw", "--passwordenv", "BW_PASSWORD"},
+		{"config", "server", "https://vault.example.com"},
+	} {
+		_, ok := newServeRequest(args)
+		assert.False(t, ok, args)
+	}
+}
+
+func TestStartServeListensOnAPrivateSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("'bw serve' doesn't listen on unix sockets on Windows")
+	}
+
+	// The fake CLI records its arguments and waits to be stopped.
+	binDir := t.TempDir()
+	argsFile := filepath.Join(binDir, "args")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$@\" > %s.tmp && mv %s.tmp %s\nexec sleep 30\n", argsFile, argsFile, argsFile)
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "bw"), []byte(script), 0700))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	process, err := startServe(os.Environ())
+	require.NoError(t, err)
+	defer process.stop()
+
+	var args []byte
+	require.Eventually(t, func() bool {
+		args, err = os.ReadFile(argsFile)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	socketPath, ok := strings.CutPrefix(strings.TrimSpace(string(args)), "serve --hostname unix:")
+	require.True(t, ok, "unexpected arguments: %s", args)
+	info, err := os.Stat(filepath.Dir(socketPath))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+
+	// Requests are sent to the socket.
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	go func() {
+		_ = http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeServeResponse(w, map[string]string{"status": "unlocked"})
+		}))
+	}()
+	resp, err := process.httpClient.Get(process.baseURL + "/status")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// The directory of the socket is removed once the process exits.
+	listener.Close()
+	require.NoError(t, process.stop())
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Dir(socketPath))
+		return os.IsNotExist(err)
+	}, 5*time.Second, 10*time.Millisecond)
+}
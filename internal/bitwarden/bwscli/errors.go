@@ -11,20 +11,45 @@ import (
 
 var (
 	resourceNotFoundRegexp = regexp.MustCompile(`(?m)Resource not found\.`)
+
+	// errorClassifications are tried in order against the messages of the
+	// CLI. Status codes are only matched as such, and not within the IDs the
+	// messages can contain.
+	errorClassifications = []struct {
+		kind   models.ErrorKind
+		regexp *regexp.Regexp
+	}{
+		{models.ErrorKindRateLimited, regexp.MustCompile(`(?i)(` + statusCodePattern("429") + `|too many requests)`)},
+		{models.ErrorKindAuthFailure, regexp.MustCompile(`(?i)(` + statusCodePattern("401") + `|unauthorized|access token|invalid_client)`)},
+		{models.ErrorKindPermissionDenied, regexp.MustCompile(`(?i)(` + statusCodePattern("403") + `|\bforbidden\b)`)},
+		{models.ErrorKindConflict, regexp.MustCompile(`(?i)(` + statusCodePattern("409") + `|\bconflict\b)`)},
+		{models.ErrorKindTransportFailure, regexp.MustCompile(`(?i)(error sending request|dns error|connection refused|timed out|invalid peer certificate|certificate verify failed)`)},
+	}
 )
 
+// statusCodePattern matches an HTTP status code as printed by the CLI, either
+// as "[429 Too Many Requests]" or as "status code: 429".
+func statusCodePattern(code string) string {
+	return `(\[` + code + `\b|\bstatus( code)?:?\s*` + code + `\b)`
+}
+
 func newUnmarshallError(err error, args []string, out []byte) error {
 	return fmt.Errorf("unable to parse result of '%s', error: '%v', output: '%v'", strings.Join(args, " "), err, string(out))
 }
 
+// remapError maps the messages of the CLI to model errors, and classifies the
+// others without changing their message.
 func remapError(err error) error {
 	v, ok := err.(*command.CommandError)
-	if ok {
-		switch {
-		case isObjectNotFoundError(v):
-			return models.ErrObjectNotFound
-		default:
-			return err
+	if !ok {
+		return err
+	}
+	if isObjectNotFoundError(v) {
+		return models.ErrObjectNotFound
+	}
+	for _, classification := range errorClassifications {
+		if classification.regexp.MatchString(v.Stderr()) {
+			return models.NewError(classification.kind, err)
 		}
 	}
 	return err
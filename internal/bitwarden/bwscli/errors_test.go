@@ -3,6 +3,7 @@
 package bwscli
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -41,3 +42,35 @@ func TestRemapError(t *testing.T) {
 		})
 	}
 }
+
+func TestRemapErrorClassifiesErrors(t *testing.T) {
+	for stderr, kind := range map[string]models.ErrorKind{
+		`Error: Received error message from server: [401 Unauthorized] {"message":"Unauthorized"}`:       models.ErrorKindAuthFailure,
+		`Error: Received error message from server: [403 Forbidden] {"message":"Forbidden"}`:             models.ErrorKindPermissionDenied,
+		`Error: Received error message from server: [429 Too Many Requests] {"message":"Slow down"}`:     models.ErrorKindRateLimited,
+		`Error: error sending request for url (https://api.bitwarden.com/): dns error: failed to lookup`: models.ErrorKindTransportFailure,
+	} {
+		err := remapError(command.NewError(fmt.Errorf("test error"), []string{"test"}, "", stderr))
+		if !errors.Is(err, kind) {
+			t.Errorf("remapError(%q) = %v, want kind %q", stderr, err, kind)
+		}
+	}
+}
+
+func TestRemapErrorIgnoresStatusCodesInIdentifiers(t *testing.T) {
+	for _, stderr := range []string{
+		`Error: Secret 4290a1b2-4010-4030-8409-401403409429 is invalid`,
+		`Error: project 28e67429-2b95-46b7-9a1e-89b4fcf56401 has no secret named "forbidden_list"`,
+	} {
+		err := remapError(command.NewError(fmt.Errorf("test error"), []string{"test"}, "", stderr))
+		var classified *models.Error
+		if errors.As(err, &classified) {
+			t.Errorf("remapError(%q) = %v, want no kind", stderr, err)
+		}
+	}
+
+	err := remapError(command.NewError(fmt.Errorf("test error"), []string{"test"}, "", `Error: request failed with status code: 429`))
+	if !errors.Is(err, models.ErrorKindRateLimited) {
+		t.Errorf("remapError() = %v, want kind %q", err, models.ErrorKindRateLimited)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
@@ -22,6 +23,7 @@ type SecretsManagerClient interface {
 	GetSecret(ctx context.Context, secret models.Secret) (*models.Secret, error)
 	GetSecretByKey(ctx context.Context, secretKey string) (*models.Secret, error)
 	LoginWithAccessToken(ctx context.Context, accessToken string) error
+	Version(context.Context) (string, error)
 }
 
 func NewSecretsManagerClient(serverURL string, opts ...Options) SecretsManagerClient {
@@ -71,7 +73,7 @@ func (c *client) CreateProject(ctx context.Context, project models.Project) (*mo
 
 	out, err := c.cmdWithAccessToken(args...).Run(ctx)
 	if err != nil {
-		return nil, remapError(err)
+		return nil, models.WithObject(remapError(err), models.ObjectProject, "")
 	}
 
 	var projectObj models.Project
@@ -102,7 +104,7 @@ func (c *client) CreateSecret(ctx context.Context, secret models.Secret) (*model
 
 	out, err := c.cmdWithAccessToken(args...).Run(ctx)
 	if err != nil {
-		return nil, remapError(err)
+		return nil, models.WithObject(remapError(err), models.ObjectSecret, "")
 	}
 
 	var secretObj models.Secret
@@ -128,7 +130,7 @@ func (c *client) EditProject(ctx context.Context, project models.Project) (*mode
 
 	out, err := c.cmdWithAccessToken(args...).Run(ctx)
 	if err != nil {
-		return nil, remapError(err)
+		return nil, models.WithObject(remapError(err), models.ObjectProject, project.ID)
 	}
 
 	var projectObj models.Project
@@ -167,7 +169,7 @@ func (c *client) EditSecret(ctx context.Context, secret models.Secret) (*models.
 
 	out, err := c.cmdWithAccessToken(args...).Run(ctx)
 	if err != nil {
-		return nil, remapError(err)
+		return nil, models.WithObject(remapError(err), models.ObjectSecret, secret.ID)
 	}
 
 	var secretObj models.Secret
@@ -185,7 +187,7 @@ func (c *client) DeleteProject(ctx context.Context, project models.Project) erro
 	}
 
 	_, err := c.cmdWithAccessToken("project", "delete", project.ID).Run(ctx)
-	return remapError(err)
+	return models.WithObject(remapError(err), models.ObjectProject, project.ID)
 }
 
 func (c *client) DeleteSecret(ctx context.Context, secret models.Secret) error {
@@ -194,7 +196,7 @@ func (c *client) DeleteSecret(ctx context.Context, secret models.Secret) error {
 	}
 
 	_, err := c.cmdWithAccessToken("secret", "delete", secret.ID).Run(ctx)
-	return remapError(err)
+	return models.WithObject(remapError(err), models.ObjectSecret, secret.ID)
 }
 
 func (c *client) GetProject(ctx context.Context, project models.Project) (*models.Project, error) {
@@ -210,7 +212,7 @@ func (c *client) GetProject(ctx context.Context, project models.Project) (*model
 
 	out, err := c.cmdWithAccessToken(args...).Run(ctx)
 	if err != nil {
-		return nil, remapError(err)
+		return nil, models.WithObject(remapError(err), models.ObjectProject, project.ID)
 	}
 
 	var projectObj models.Project
@@ -235,7 +237,7 @@ func (c *client) GetSecret(ctx context.Context, secret models.Secret) (*models.S
 
 	out, err := c.cmdWithAccessToken(args...).Run(ctx)
 	if err != nil {
-		return nil, remapError(err)
+		return nil, models.WithObject(remapError(err), models.ObjectSecret, secret.ID)
 	}
 
 	var secretObj models.Secret
@@ -285,6 +287,20 @@ func (c *client) GetSecretByKey(ctx context.Context, secretKey string) (*models.
 	return nil, models.ErrNoObjectFoundMatchingFilter
 }
 
+// Version returns the version of the Secrets Manager CLI, which prints it
+// after its name, e.g. "bws 1.0.0".
+func (c *client) Version(ctx context.Context) (string, error) {
+	out, err := c.newCommand("bws", "--version").AppendEnv(c.env()).Run(ctx)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("unable to parse version of 'bws': '%s'", string(out))
+	}
+	return fields[len(fields)-1], nil
+}
+
 func (c *client) cmdWithAccessToken(args ...string) command.Command {
 	return c.newCommand("bws", args...).AppendEnv(c.env())
 }
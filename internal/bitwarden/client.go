@@ -8,8 +8,17 @@ import (
 
 const (
 	DefaultBitwardenServerURL = "https://vault.bitwarden.com"
+
+	RegionUS = "us"
+	RegionEU = "eu"
 )
 
+// RegionServerURLs maps the regions of Bitwarden's cloud to their server URL.
+var RegionServerURLs = map[string]string{
+	RegionUS: DefaultBitwardenServerURL,
+	RegionEU: "https://vault.bitwarden.eu",
+}
+
 type PasswordManager interface {
 	CreateAttachmentFromContent(ctx context.Context, itemId, filename string, content []byte) (*models.Attachment, error)
 	CreateAttachmentFromFile(ctx context.Context, itemId, filePath string) (*models.Attachment, error)
@@ -13,6 +13,13 @@ func aes256Decode(cipherText []byte, key []byte, iv []byte) ([]byte, error) {
 		return nil, fmt.Errorf("error creating new cipher block: %w", err)
 	}
 
+	if len(iv) != block.BlockSize() {
+		return nil, fmt.Errorf("bad IV length: %d", len(iv))
+	}
+	if len(cipherText) == 0 || len(cipherText)%block.BlockSize() != 0 {
+		return nil, fmt.Errorf("%w: data isn't a multiple of the block size", ErrInvalidPadding)
+	}
+
 	plainText := make([]byte, len(cipherText))
 
 	mode := cipher.NewCBCDecrypter(block, iv)
@@ -39,13 +46,18 @@ func aes256Encode(plainText []byte, key []byte, iv []byte, blockSize int) ([]byt
 
 func pkcs5Unpadding(src []byte, blockSize int) ([]byte, error) {
 	srcLen := len(src)
-	paddingLen := int(src[srcLen-1])
-	if paddingLen == srcLen {
-		return []byte{}, nil
+	if srcLen == 0 {
+		return nil, ErrInvalidPadding
 	}
 
-	if paddingLen > blockSize {
-		return nil, fmt.Errorf("bad padding size")
+	paddingLen := int(src[srcLen-1])
+	if paddingLen == 0 || paddingLen > blockSize || paddingLen > srcLen {
+		return nil, fmt.Errorf("%w: bad padding size", ErrInvalidPadding)
+	}
+	for _, b := range src[srcLen-paddingLen:] {
+		if int(b) != paddingLen {
+			return nil, fmt.Errorf("%w: inconsistent padding bytes", ErrInvalidPadding)
+		}
 	}
 	return src[:srcLen-paddingLen], nil
 }
@@ -3,6 +3,7 @@ package encryptedstring
 import (
 	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -17,6 +18,11 @@ const (
 	BUFFER_MIN_DATA_LENGTH = 1
 )
 
+var (
+	ErrUnsupportedEncryptionType = errors.New("unsupported encryption type")
+	ErrMalformedEncryptedString  = errors.New("malformed encrypted string")
+)
+
 type EncryptedString struct {
 	IV   []byte
 	Data []byte
@@ -33,41 +39,88 @@ func New(iv, data, hmac []byte, key symmetrickey.Key) EncryptedString {
 	}
 }
 
+// HasIV returns whether the encryption type carries an IV, i.e. is a
+// symmetric one.
+func HasIV(encType symmetrickey.EncryptionType) bool {
+	switch encType {
+	case symmetrickey.AesCbc256_B64, symmetrickey.AesCbc128_HmacSha256_B64, symmetrickey.AesCbc256_HmacSha256_B64:
+		return true
+	}
+	return false
+}
+
+// HasMac returns whether the encryption type is authenticated with an HMAC.
+func HasMac(encType symmetrickey.EncryptionType) bool {
+	switch encType {
+	case symmetrickey.AesCbc128_HmacSha256_B64, symmetrickey.AesCbc256_HmacSha256_B64:
+		return true
+	}
+	return false
+}
+
+func isSupported(encType symmetrickey.EncryptionType) bool {
+	return HasIV(encType) || encType == symmetrickey.Rsa2048_OaepSha256_B64 || encType == symmetrickey.Rsa2048_OaepSha1_B64
+}
+
 func NewFromEncryptedBuffer(encBytes []byte) (*EncryptedString, error) {
+	if len(encBytes) < BUFFER_ENC_TYPE_LENGTH+BUFFER_MIN_DATA_LENGTH {
+		return nil, fmt.Errorf("%w: encrypted buffer is too short (%d bytes)", ErrMalformedEncryptedString, len(encBytes))
+	}
+
 	encType := symmetrickey.EncryptionType(encBytes[0])
+	if !isSupported(encType) {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedEncryptionType, encType)
+	}
 
 	encString := EncryptedString{}
+	encString.Key.EncryptionType = encType
 
-	switch encType {
-	case symmetrickey.AesCbc256_HmacSha256_B64:
-		const minimumLength = BUFFER_ENC_TYPE_LENGTH + BUFFER_IV_LENGTH + BUFFER_MAC_LENGTH + BUFFER_MIN_DATA_LENGTH
-		if len(encBytes) < minimumLength {
-			return nil, fmt.Errorf("bad minimum length for encrypted buffer: %d", len(encBytes))
-		}
-		encString.Key.EncryptionType = encType
-		encString.IV = encBytes[BUFFER_ENC_TYPE_LENGTH : BUFFER_ENC_TYPE_LENGTH+BUFFER_IV_LENGTH]
-		encString.Hmac = encBytes[BUFFER_ENC_TYPE_LENGTH+BUFFER_IV_LENGTH : BUFFER_ENC_TYPE_LENGTH+BUFFER_IV_LENGTH+BUFFER_MAC_LENGTH]
-		encString.Data = encBytes[BUFFER_ENC_TYPE_LENGTH+BUFFER_IV_LENGTH+BUFFER_MAC_LENGTH:]
-	default:
-		return nil, fmt.Errorf("unsupported encrypted buffer type: %d", encType)
+	minimumLength := BUFFER_ENC_TYPE_LENGTH + BUFFER_MIN_DATA_LENGTH
+	if HasIV(encType) {
+		minimumLength += BUFFER_IV_LENGTH
+	}
+	if HasMac(encType) {
+		minimumLength += BUFFER_MAC_LENGTH
+	}
+	if len(encBytes) < minimumLength {
+		return nil, fmt.Errorf("%w: bad minimum length for encrypted buffer of type %d: %d", ErrMalformedEncryptedString, encType, len(encBytes))
+	}
+
+	offset := BUFFER_ENC_TYPE_LENGTH
+	if HasIV(encType) {
+		encString.IV = encBytes[offset : offset+BUFFER_IV_LENGTH]
+		offset += BUFFER_IV_LENGTH
+	}
+	if HasMac(encType) {
+		encString.Hmac = encBytes[offset : offset+BUFFER_MAC_LENGTH]
+		offset += BUFFER_MAC_LENGTH
 	}
+	encString.Data = encBytes[offset:]
 
 	return &encString, nil
 }
 
 func (encString *EncryptedString) ToEncryptedBuffer() ([]byte, error) {
-	if len(encString.IV) != BUFFER_IV_LENGTH {
+	encType := encString.Key.EncryptionType
+	if !isSupported(encType) {
+		return nil, fmt.Errorf("can't output encrypted buffer: %w: %d", ErrUnsupportedEncryptionType, encType)
+	}
+	if HasIV(encType) && len(encString.IV) != BUFFER_IV_LENGTH {
 		return nil, fmt.Errorf("can't output encrypted buffer: bad IV length")
 	}
-	if len(encString.Hmac) != BUFFER_MAC_LENGTH {
+	if HasMac(encType) && len(encString.Hmac) != BUFFER_MAC_LENGTH {
 		return nil, fmt.Errorf("can't output encrypted buffer: bad HMAC length")
 	}
 	if len(encString.Data) < BUFFER_MIN_DATA_LENGTH {
 		return nil, fmt.Errorf("can't output encrypted buffer: bad data length")
 	}
-	encType := []byte{byte(encString.Key.EncryptionType)}
-	encBuffer := append(encType, encString.IV...)
-	encBuffer = append(encBuffer, encString.Hmac...)
+	encBuffer := []byte{byte(encType)}
+	if HasIV(encType) {
+		encBuffer = append(encBuffer, encString.IV...)
+	}
+	if HasMac(encType) {
+		encBuffer = append(encBuffer, encString.Hmac...)
+	}
 	encBuffer = append(encBuffer, encString.Data...)
 
 	return encBuffer, nil
@@ -91,7 +144,7 @@ func (encString *EncryptedString) Equals(otherString *EncryptedString) bool {
 
 func NewFromEncryptedValue(encryptedValue string) (*EncryptedString, error) {
 	if len(encryptedValue) == 0 {
-		return nil, fmt.Errorf("supposedly encrypted string is empty")
+		return nil, fmt.Errorf("%w: supposedly encrypted string is empty", ErrMalformedEncryptedString)
 	}
 	var encPieces []string
 	encString := EncryptedString{}
@@ -100,7 +153,7 @@ func NewFromEncryptedValue(encryptedValue string) (*EncryptedString, error) {
 	if len(headerPieces) == 2 {
 		s, err := strconv.ParseInt(headerPieces[0], 10, 8)
 		if err != nil {
-			return nil, fmt.Errorf("unable to parse encryption type: %w", err)
+			return nil, fmt.Errorf("%w: unable to parse encryption type: %w", ErrMalformedEncryptedString, err)
 		}
 		encString.Key.EncryptionType = symmetrickey.EncryptionType(s)
 		encPieces = strings.Split(headerPieces[1], "|")
@@ -116,7 +169,7 @@ func NewFromEncryptedValue(encryptedValue string) (*EncryptedString, error) {
 	switch encString.Key.EncryptionType {
 	case symmetrickey.AesCbc128_HmacSha256_B64, symmetrickey.AesCbc256_HmacSha256_B64:
 		if len(encPieces) != 3 {
-			return nil, fmt.Errorf("bad amount of pieces (expected: 3, got: %d)", len(encPieces))
+			return nil, fmt.Errorf("%w: bad amount of pieces (expected: 3, got: %d)", ErrMalformedEncryptedString, len(encPieces))
 		}
 
 		encString.IV = []byte(encPieces[0])
@@ -124,34 +177,42 @@ func NewFromEncryptedValue(encryptedValue string) (*EncryptedString, error) {
 		encString.Hmac = []byte(encPieces[2])
 	case symmetrickey.AesCbc256_B64:
 		if len(encPieces) != 2 {
-			return nil, fmt.Errorf("bad amount of pieces (expected: 2, got: %d)", len(encPieces))
+			return nil, fmt.Errorf("%w: bad amount of pieces (expected: 2, got: %d)", ErrMalformedEncryptedString, len(encPieces))
 		}
 
 		encString.IV = []byte(encPieces[0])
 		encString.Data = []byte(encPieces[1])
 	case symmetrickey.Rsa2048_OaepSha256_B64, symmetrickey.Rsa2048_OaepSha1_B64:
 		if len(encPieces) != 1 {
-			return nil, fmt.Errorf("bad amount of pieces (expected: 1, got: %d)", len(encPieces))
+			return nil, fmt.Errorf("%w: bad amount of pieces (expected: 1, got: %d)", ErrMalformedEncryptedString, len(encPieces))
 		}
 
 		encString.Data = []byte(encPieces[0])
 	default:
-		return nil, fmt.Errorf("unsupported encryption type)")
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedEncryptionType, encString.Key.EncryptionType)
 	}
 
 	base64DecodedIV, err := base64.StdEncoding.DecodeString(string(encString.IV))
 	if err != nil {
-		return nil, fmt.Errorf("unable to base64 decode IV: %w", err)
+		return nil, fmt.Errorf("%w: unable to base64 decode IV: %w", ErrMalformedEncryptedString, err)
 	}
 
 	base64DecodedData, err := base64.StdEncoding.DecodeString(string(encString.Data))
 	if err != nil {
-		return nil, fmt.Errorf("unable to base64 decode data: %w", err)
+		return nil, fmt.Errorf("%w: unable to base64 decode data: %w", ErrMalformedEncryptedString, err)
 	}
 
 	base64DecodedMac, err := base64.StdEncoding.DecodeString(string(encString.Hmac))
 	if err != nil {
-		return nil, fmt.Errorf("unable to base64 decode hmac: %w", err)
+		return nil, fmt.Errorf("%w: unable to base64 decode hmac: %w", ErrMalformedEncryptedString, err)
+	}
+
+	if HasIV(encString.Key.EncryptionType) && len(base64DecodedIV) != BUFFER_IV_LENGTH {
+		return nil, fmt.Errorf("%w: bad IV length: %d", ErrMalformedEncryptedString, len(base64DecodedIV))
+	}
+
+	if HasMac(encString.Key.EncryptionType) && len(base64DecodedMac) != BUFFER_MAC_LENGTH {
+		return nil, fmt.Errorf("%w: bad HMAC length: %d", ErrMalformedEncryptedString, len(base64DecodedMac))
 	}
 
 	encString.IV = base64DecodedIV
@@ -2,10 +2,12 @@ package crypto
 
 import (
 	"bytes"
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/x509"
+	"errors"
 	"fmt"
 
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/encryptedstring"
@@ -15,6 +17,11 @@ import (
 
 var (
 	SafeMode = true
+
+	ErrKeyTypeMismatch = errors.New("key and payload encryption types don't match")
+	ErrMacMissing      = errors.New("payload is missing its MAC")
+	ErrMacMismatch     = errors.New("MAC verification failed")
+	ErrInvalidPadding  = errors.New("invalid padding")
 )
 
 func EncryptAsString(plainValue []byte, key symmetrickey.Key) (string, error) {
@@ -35,8 +42,11 @@ func Encrypt(plainValue []byte, key symmetrickey.Key) (*encryptedstring.Encrypte
 		return nil, fmt.Errorf("error generating random bytes: %w", err)
 	}
 
+	if !encryptedstring.HasIV(key.EncryptionType) {
+		return nil, fmt.Errorf("%w: %d can't be used to encrypt symmetrically", encryptedstring.ErrUnsupportedEncryptionType, key.EncryptionType)
+	}
 	if len(key.EncryptionKey) == 0 {
-		return nil, fmt.Errorf("no encryption key was provided: %v", key.EncryptionKey)
+		return nil, fmt.Errorf("no encryption key was provided")
 	}
 	if len(randomIV) != 16 {
 		return nil, fmt.Errorf("bad IV length - expected 16, got: %d", len(randomIV))
@@ -47,9 +57,12 @@ func Encrypt(plainValue []byte, key symmetrickey.Key) (*encryptedstring.Encrypte
 		return nil, fmt.Errorf("error to aes256encoding data: %w", err)
 	}
 
-	hmac := helpers.HMACSum(append(randomIV, data...), key.MacKey, sha256.New)
+	var mac []byte
+	if encryptedstring.HasMac(key.EncryptionType) {
+		mac = helpers.HMACSum(append(append([]byte{}, randomIV...), data...), key.MacKey, sha256.New)
+	}
 
-	res := encryptedstring.New(randomIV, data, hmac, key)
+	res := encryptedstring.New(randomIV, data, mac, key)
 
 	if SafeMode {
 		safeDecryptedValue, err := Decrypt(&res, &key)
@@ -101,7 +114,7 @@ func DecryptEncryptionKey(encryptedKeyStr string, key symmetrickey.Key) (*symmet
 			return nil, fmt.Errorf("error decrypting encryption key: %w", err)
 		}
 	} else {
-		return nil, fmt.Errorf("unsupported encryption key type")
+		return nil, fmt.Errorf("error decrypting encryption key: %w: %d", encryptedstring.ErrUnsupportedEncryptionType, encKeyCipher.Key.EncryptionType)
 	}
 
 	encryptionKey, err := symmetrickey.NewFromRawBytes(decEncKey)
@@ -112,29 +125,46 @@ func DecryptEncryptionKey(encryptedKeyStr string, key symmetrickey.Key) (*symmet
 }
 
 func Decrypt(encString *encryptedstring.EncryptedString, key *symmetrickey.Key) ([]byte, error) {
-	if encString.Key.EncryptionType == symmetrickey.AesCbc128_HmacSha256_B64 && key.EncryptionType == symmetrickey.AesCbc256_B64 {
-		return nil, fmt.Errorf("unsupported old scheme")
+	encType := encString.Key.EncryptionType
+	if !encryptedstring.HasIV(encType) {
+		return nil, fmt.Errorf("%w: %d can't be decrypted with a symmetric key", encryptedstring.ErrUnsupportedEncryptionType, encType)
 	}
 
-	if encString.Key.EncryptionType != key.EncryptionType {
-		return nil, fmt.Errorf("bad encryption type: %d!=%d", encString.Key.EncryptionType, key.EncryptionType)
+	key, err := resolveLegacyKey(encType, key)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(encString.Hmac) == 0 && len(key.MacKey) > 0 {
-		return nil, fmt.Errorf("hmac value is missing")
+	if encType != key.EncryptionType {
+		return nil, fmt.Errorf("%w: %d!=%d", ErrKeyTypeMismatch, encType, key.EncryptionType)
 	}
 
-	if len(encString.Hmac) != len(key.MacKey) {
-		return nil, fmt.Errorf("hmac lengths differ: %d!=%d", len(encString.Hmac), len(key.MacKey))
-	}
+	if encryptedstring.HasMac(encType) {
+		if len(encString.Hmac) == 0 {
+			return nil, ErrMacMissing
+		}
 
-	computedHmac := helpers.HMACSum(append(append([]byte{}, encString.IV...), encString.Data...), key.MacKey, sha256.New)
-	if !bytes.Equal(computedHmac, encString.Hmac) {
-		return nil, fmt.Errorf("hmac comparison failed: %v != %v", computedHmac, encString.Hmac)
+		computedHmac := helpers.HMACSum(append(append([]byte{}, encString.IV...), encString.Data...), key.MacKey, sha256.New)
+		if !hmac.Equal(computedHmac, encString.Hmac) {
+			return nil, ErrMacMismatch
+		}
+	} else if len(encString.Hmac) > 0 {
+		return nil, fmt.Errorf("%w: unexpected MAC for encryption type %d", encryptedstring.ErrMalformedEncryptedString, encType)
 	}
+
 	decData, err := aes256Decode(encString.Data, key.EncryptionKey, encString.IV)
 	if err != nil {
 		return nil, fmt.Errorf("error aes256Decoding: %w", err)
 	}
 	return decData, nil
 }
+
+// resolveLegacyKey returns the key to use for a payload. Legacy accounts have
+// 32 bytes keys, which were split into an encryption and a MAC key for
+// AesCbc128_HmacSha256_B64 payloads.
+func resolveLegacyKey(encType symmetrickey.EncryptionType, key *symmetrickey.Key) (*symmetrickey.Key, error) {
+	if encType == symmetrickey.AesCbc128_HmacSha256_B64 && key.EncryptionType == symmetrickey.AesCbc256_B64 {
+		return symmetrickey.NewFromRawBytesWithEncryptionType(key.Key, symmetrickey.AesCbc128_HmacSha256_B64)
+	}
+	return key, nil
+}
@@ -3,12 +3,15 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/x509"
 	"encoding/base64"
 	"testing"
 
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/encryptedstring"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/symmetrickey"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -43,3 +46,103 @@ func TestDecryptPrivateKey(t *testing.T) {
 	base64PublicKey := base64.StdEncoding.EncodeToString(publicKeyBytes)
 	assert.Equal(t, "MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAzfZx4rRpKBVnhiqZe5IH5mRvHjY1iTrZOpooma8PtOIoIdtSRY5YdeX4Hben09C8jZODgyPtxVbWZv/YBS9okE6gPsqugDMQ5M+t7hp3ye9art7CkfvIDjGHZMrANQCYB/tPWkda7jaaAIBkCIPM4+vZ7afBN3Mq/BX7hotSaGlPPP7DCkzbKK/f5U/F/dA8UTZFXtST9ivRWWI8bHdjNwe6Zm2wGUT29zcDmkFq5FqvtY5AuQ6yhuOjXwS1vLP1ckXSJePz0TJNDITW5UmSRI/tesjvnbsq+D/NcerrOvuF0xzKkXlm/lMYq2n3EgQ7neWCCQCrKiQcY9BdhsFEqwIDAQAB", base64PublicKey)
 }
+
+func TestEncryptDecryptAllSymmetricTypes(t *testing.T) {
+	rawKey64 := bytes.Repeat([]byte{0x42}, 64)
+	rawKey32 := bytes.Repeat([]byte{0x24}, 32)
+
+	testData := map[string]struct {
+		rawKey  []byte
+		encType symmetrickey.EncryptionType
+	}{
+		"AesCbc256_B64":            {rawKey: rawKey32, encType: symmetrickey.AesCbc256_B64},
+		"AesCbc128_HmacSha256_B64": {rawKey: rawKey32, encType: symmetrickey.AesCbc128_HmacSha256_B64},
+		"AesCbc256_HmacSha256_B64": {rawKey: rawKey64, encType: symmetrickey.AesCbc256_HmacSha256_B64},
+	}
+
+	for name, tt := range testData {
+		t.Run(name, func(t *testing.T) {
+			key, err := symmetrickey.NewFromRawBytesWithEncryptionType(tt.rawKey, tt.encType)
+			require.NoError(t, err)
+
+			encString, err := Encrypt([]byte("secret value"), *key)
+			require.NoError(t, err)
+			assert.Equal(t, tt.encType, encString.Key.EncryptionType)
+
+			fromString, err := encryptedstring.NewFromEncryptedValue(encString.String())
+			require.NoError(t, err)
+			decrypted, err := Decrypt(fromString, key)
+			require.NoError(t, err)
+			assert.Equal(t, "secret value", string(decrypted))
+
+			buffer, err := encString.ToEncryptedBuffer()
+			require.NoError(t, err)
+			fromBuffer, err := encryptedstring.NewFromEncryptedBuffer(buffer)
+			require.NoError(t, err)
+			assert.True(t, fromBuffer.Equals(encString))
+		})
+	}
+}
+
+func TestDecryptLegacyKeyWithAesCbc128HmacPayload(t *testing.T) {
+	rawKey := bytes.Repeat([]byte{0x24}, 32)
+	legacyKey, err := symmetrickey.NewFromRawBytes(rawKey)
+	require.NoError(t, err)
+	require.Equal(t, symmetrickey.AesCbc256_B64, legacyKey.EncryptionType)
+
+	macKey, err := symmetrickey.NewFromRawBytesWithEncryptionType(rawKey, symmetrickey.AesCbc128_HmacSha256_B64)
+	require.NoError(t, err)
+	encString, err := Encrypt([]byte("secret value"), *macKey)
+	require.NoError(t, err)
+
+	decrypted, err := Decrypt(encString, legacyKey)
+	require.NoError(t, err)
+	assert.Equal(t, "secret value", string(decrypted))
+}
+
+func TestDecryptErrors(t *testing.T) {
+	key, err := symmetrickey.NewFromRawBytes(bytes.Repeat([]byte{0x42}, 64))
+	require.NoError(t, err)
+	otherKey, err := symmetrickey.NewFromRawBytes(bytes.Repeat([]byte{0x43}, 64))
+	require.NoError(t, err)
+	legacyKey, err := symmetrickey.NewFromRawBytes(bytes.Repeat([]byte{0x42}, 32))
+	require.NoError(t, err)
+
+	encString, err := Encrypt([]byte("secret value"), *key)
+	require.NoError(t, err)
+
+	_, err = Decrypt(encString, otherKey)
+	assert.ErrorIs(t, err, ErrMacMismatch)
+
+	_, err = Decrypt(encString, legacyKey)
+	assert.ErrorIs(t, err, ErrKeyTypeMismatch)
+
+	withoutMac := encryptedstring.New(encString.IV, encString.Data, nil, encString.Key)
+	_, err = Decrypt(&withoutMac, key)
+	assert.ErrorIs(t, err, ErrMacMissing)
+
+	rsaString, err := encryptedstring.NewFromEncryptedValue("4.AAAA")
+	require.NoError(t, err)
+	_, err = Decrypt(rsaString, key)
+	assert.ErrorIs(t, err, encryptedstring.ErrUnsupportedEncryptionType)
+
+	_, err = encryptedstring.NewFromEncryptedValue("9.AAAA|AAAA")
+	assert.ErrorIs(t, err, encryptedstring.ErrUnsupportedEncryptionType)
+
+	_, err = encryptedstring.NewFromEncryptedBuffer([]byte{byte(symmetrickey.AesCbc256_HmacSha256_B64), 1, 2})
+	assert.ErrorIs(t, err, encryptedstring.ErrMalformedEncryptedString)
+}
+
+func TestDecryptInvalidPadding(t *testing.T) {
+	key, err := symmetrickey.NewFromRawBytes(bytes.Repeat([]byte{0x24}, 32))
+	require.NoError(t, err)
+
+	iv := make([]byte, 16)
+	data, err := aes256Encode(bytes.Repeat([]byte{0x00}, 16), key.EncryptionKey, iv, 16)
+	require.NoError(t, err)
+
+	// Drop the padding block, leaving a block ending with a zero byte.
+	encString := encryptedstring.New(iv, data[:16], nil, *key)
+	_, err = Decrypt(&encString, key)
+	assert.ErrorIs(t, err, ErrInvalidPadding)
+}
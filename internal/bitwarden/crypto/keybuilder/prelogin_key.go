@@ -14,6 +14,18 @@ func BuildPreloginKey(masterPassword, email string, kdfConfig models.KdfConfigur
 	return buildKey(masterPassword, email, kdfConfig)
 }
 
+// BuildExportKey derives the key protecting a password-protected export from
+// its password and the salt stored in the export.
+func BuildExportKey(password, salt string, kdfConfig models.KdfConfiguration) (*symmetrickey.Key, error) {
+	key, err := buildKey(password, salt, kdfConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	stretchedKey := key.StretchKey()
+	return &stretchedKey, nil
+}
+
 func buildKey(masterPassword, salt string, kdfConfig models.KdfConfiguration) (*symmetrickey.Key, error) {
 	var rawKey []byte
 	switch kdfConfig.KdfType {
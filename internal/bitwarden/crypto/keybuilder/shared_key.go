@@ -4,8 +4,10 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
+	"hash"
 
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/encryptedstring"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/symmetrickey"
@@ -50,11 +52,18 @@ func RSADecrypt(data string, privateKey *rsa.PrivateKey) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create encrypted string from data: %w", err)
 	}
-	if s.Key.EncryptionType != symmetrickey.Rsa2048_OaepSha1_B64 {
-		return nil, fmt.Errorf("encType !=4")
+
+	var oaepHash hash.Hash
+	switch s.Key.EncryptionType {
+	case symmetrickey.Rsa2048_OaepSha1_B64:
+		oaepHash = sha1.New()
+	case symmetrickey.Rsa2048_OaepSha256_B64:
+		oaepHash = sha256.New()
+	default:
+		return nil, fmt.Errorf("%w: %d isn't an RSA encryption type", encryptedstring.ErrUnsupportedEncryptionType, s.Key.EncryptionType)
 	}
 
-	clearText, err := rsa.DecryptOAEP(sha1.New(), nil, privateKey, s.Data, nil)
+	clearText, err := rsa.DecryptOAEP(oaepHash, nil, privateKey, s.Data, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed decryptRSA to decrypt text: %w", err)
 	}
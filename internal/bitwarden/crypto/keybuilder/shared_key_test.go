@@ -0,0 +1,38 @@
+//go:build offline
+
+package keybuilder
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/encryptedstring"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/symmetrickey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRSADecrypt(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	encryptedSha1, err := RSAEncrypt([]byte("org-key"), &privateKey.PublicKey)
+	require.NoError(t, err)
+
+	rawSha256, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, &privateKey.PublicKey, []byte("org-key"), nil)
+	require.NoError(t, err)
+	encryptedSha256 := fmt.Sprintf("%d.%s", symmetrickey.Rsa2048_OaepSha256_B64, base64.StdEncoding.EncodeToString(rawSha256))
+
+	for _, encrypted := range []string{encryptedSha1, encryptedSha256} {
+		clearText, err := RSADecrypt(encrypted, privateKey)
+		require.NoError(t, err)
+		assert.Equal(t, "org-key", string(clearText))
+	}
+
+	_, err = RSADecrypt("2.AAAAAAAAAAAAAAAAAAAAAA==|AAAA|AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", privateKey)
+	assert.ErrorIs(t, err, encryptedstring.ErrUnsupportedEncryptionType)
+}
@@ -0,0 +1,115 @@
+package embedded
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/encryptedstring"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/symmetrickey"
+)
+
+const (
+	cliDataFile = "data.json"
+
+	// The bw CLI stores the user key of an unlocked vault in data.json,
+	// encrypted with the session key it prints (BW_SESSION).
+	cliProtectedKeyPrefix = "__PROTECTED__"
+	cliUserKeyAutoSuffix  = "_user_auto"
+
+	cliActiveAccountIdKey       = "global_account_activeAccountId"
+	cliLegacyActiveAccountIdKey = "activeUserId"
+)
+
+// UserKeyFromCLISession returns the base64-encoded user key of the active
+// account of the bw CLI vault stored in vaultPath, using the session key the
+// CLI returned when the vault was unlocked.
+func UserKeyFromCLISession(vaultPath, sessionKey string) (string, error) {
+	data, err := readCLIData(vaultPath)
+	if err != nil {
+		return "", err
+	}
+
+	accountId, err := cliActiveAccountId(data)
+	if err != nil {
+		return "", err
+	}
+
+	userKey, err := cliSessionUserKey(data, accountId, sessionKey)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(userKey.Key), nil
+}
+
+func cliSessionUserKey(data map[string]json.RawMessage, accountId, sessionKey string) (*symmetrickey.Key, error) {
+	var protectedUserKey string
+	if raw, ok := data[cliProtectedKeyPrefix+accountId+cliUserKeyAutoSuffix]; !ok {
+		return nil, fmt.Errorf("no user key protected by a session key found for account '%s', run 'bw unlock' first", accountId)
+	} else if err := json.Unmarshal(raw, &protectedUserKey); err != nil {
+		return nil, fmt.Errorf("error reading protected user key: %w", err)
+	}
+
+	key, err := parseUserKey(sessionKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session key: %w", err)
+	}
+
+	rawUserKey, err := decryptCLIProtectedValue(protectedUserKey, *key)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting user key with session key: %w", err)
+	}
+	return symmetrickey.NewFromRawBytes(rawUserKey)
+}
+
+func readCLIData(vaultPath string) (map[string]json.RawMessage, error) {
+	content, err := os.ReadFile(filepath.Join(vaultPath, cliDataFile))
+	if err != nil {
+		return nil, fmt.Errorf("error reading bw CLI data: %w", err)
+	}
+
+	data := map[string]json.RawMessage{}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("error parsing bw CLI data: %w", err)
+	}
+	return data, nil
+}
+
+func cliActiveAccountId(data map[string]json.RawMessage) (string, error) {
+	for _, key := range []string{cliActiveAccountIdKey, cliLegacyActiveAccountIdKey} {
+		raw, ok := data[key]
+		if !ok {
+			continue
+		}
+
+		var accountId string
+		if err := json.Unmarshal(raw, &accountId); err != nil {
+			return "", fmt.Errorf("error reading active account: %w", err)
+		}
+		if len(accountId) > 0 {
+			return accountId, nil
+		}
+	}
+	return "", fmt.Errorf("no active account found in bw CLI data, run 'bw login' first")
+}
+
+// decryptCLIProtectedValue decrypts a value protected by a session key, which
+// the CLI stores as a base64-encoded encrypted buffer.
+func decryptCLIProtectedValue(protectedValue string, sessionKey symmetrickey.Key) ([]byte, error) {
+	encBytes, err := base64.StdEncoding.DecodeString(protectedValue)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding protected value: %w", err)
+	}
+	if len(encBytes) == 0 {
+		return nil, fmt.Errorf("empty protected value")
+	}
+
+	encString, err := encryptedstring.NewFromEncryptedBuffer(encBytes)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Decrypt(encString, &sessionKey)
+}
@@ -0,0 +1,73 @@
+//go:build offline
+
+package embedded
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/symmetrickey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserKeyFromCLISession(t *testing.T) {
+	accountSecrets, err := decryptAccountSecrets(AccountPbkdf2, TestPassword)
+	require.NoError(t, err)
+
+	sessionKey, rawSessionKey := newTestSessionKey(t)
+	encUserKey, err := crypto.Encrypt(accountSecrets.MainKey.Key, rawSessionKey)
+	require.NoError(t, err)
+	encBuffer, err := encUserKey.ToEncryptedBuffer()
+	require.NoError(t, err)
+
+	vaultPath := t.TempDir()
+	writeCLIData(t, vaultPath, map[string]interface{}{
+		cliActiveAccountIdKey: AccountPbkdf2.AccountUUID,
+		cliProtectedKeyPrefix + AccountPbkdf2.AccountUUID + cliUserKeyAutoSuffix: base64.StdEncoding.EncodeToString(encBuffer),
+	})
+
+	userKey, err := UserKeyFromCLISession(vaultPath, sessionKey)
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(accountSecrets.MainKey.Key), userKey)
+
+	otherSessionKey, _ := newTestSessionKey(t)
+	_, err = UserKeyFromCLISession(vaultPath, otherSessionKey)
+	assert.ErrorContains(t, err, "error decrypting user key with session key")
+}
+
+func TestUserKeyFromCLISession_Locked(t *testing.T) {
+	vaultPath := t.TempDir()
+	writeCLIData(t, vaultPath, map[string]interface{}{
+		cliActiveAccountIdKey: AccountPbkdf2.AccountUUID,
+	})
+
+	sessionKey, _ := newTestSessionKey(t)
+	_, err := UserKeyFromCLISession(vaultPath, sessionKey)
+	assert.ErrorContains(t, err, "bw unlock")
+}
+
+func newTestSessionKey(t *testing.T) (string, symmetrickey.Key) {
+	t.Helper()
+
+	rawKey := make([]byte, 64)
+	_, err := rand.Read(rawKey)
+	require.NoError(t, err)
+
+	key, err := symmetrickey.NewFromRawBytes(rawKey)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(rawKey), *key
+}
+
+func writeCLIData(t *testing.T, vaultPath string, data map[string]interface{}) {
+	t.Helper()
+
+	content, err := json.Marshal(data)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(vaultPath, cliDataFile), content, 0600))
+}
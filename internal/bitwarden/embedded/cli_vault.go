@@ -0,0 +1,210 @@
+package embedded
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/webapi"
+)
+
+const (
+	cliAccountsKey = "global_account_accounts"
+
+	// Per-account entries are stored as "user_<accountId>_<suffix>".
+	cliUserKeyPrefix             = "user_"
+	cliKdfConfigSuffix           = "_kdfConfig_kdfConfig"
+	cliProtectedPrivateKeySuffix = "_crypto_privateKey"
+	cliOrganizationKeysSuffix    = "_crypto_organizationKeys"
+	cliOrganizationsSuffix       = "_organizations_organizations"
+	cliCiphersSuffix             = "_ciphers_ciphers"
+	cliFoldersSuffix             = "_folder_folders"
+	cliCollectionsSuffix         = "_collection_collections"
+)
+
+type cliAccount struct {
+	Email string `json:"email"`
+}
+
+type cliKdfConfig struct {
+	KdfType     models.KdfType `json:"kdfType"`
+	Iterations  int            `json:"iterations"`
+	Memory      int            `json:"memory"`
+	Parallelism int            `json:"parallelism"`
+}
+
+type cliOrganizationKey struct {
+	Type string `json:"type"`
+	Key  string `json:"key"`
+}
+
+type cliOrganization struct {
+	Name string `json:"name"`
+}
+
+// cliVault is the content of the bw CLI's data.json for a single account.
+type cliVault struct {
+	account       Account
+	organizations []webapi.Organization
+	cachedObjects webapi.SyncResponse
+	data          map[string]json.RawMessage
+}
+
+// LoadCLIVaultWithSessionKey loads the account and objects cached by the bw
+// CLI in vaultPath, unlocking them with the session key returned by
+// 'bw unlock'. The server is never contacted, and the vault remains read-only
+// until logged in.
+func (v *webAPIVault) LoadCLIVaultWithSessionKey(ctx context.Context, vaultPath, sessionKey string) error {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	if v.loginAccount.LoggedIn() {
+		return models.ErrAlreadyLoggedIn
+	}
+
+	vault, err := readCLIVault(vaultPath)
+	if err != nil {
+		return err
+	}
+
+	userKey, err := cliSessionUserKey(vault.data, vault.account.AccountUUID, sessionKey)
+	if err != nil {
+		return err
+	}
+
+	accountSecrets, err := decryptAccountSecretsWithUserKey(vault.account, *userKey)
+	if err != nil {
+		return fmt.Errorf("error decrypting account secrets: %w", err)
+	}
+
+	err = loadOrganizationSecrets(*accountSecrets, vault.organizations)
+	if err != nil {
+		return fmt.Errorf("error loading organization secrets: %w", err)
+	}
+
+	v.loginAccount = vault.account
+	v.loginAccount.Secrets = *accountSecrets
+
+	return v.loadObjectMap(ctx, vault.cachedObjects)
+}
+
+func readCLIVault(vaultPath string) (*cliVault, error) {
+	data, err := readCLIData(vaultPath)
+	if err != nil {
+		return nil, err
+	}
+
+	accountId, err := cliActiveAccountId(data)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := map[string]cliAccount{}
+	if err := readCLIEntry(data, cliAccountsKey, &accounts, true); err != nil {
+		return nil, err
+	}
+	account, ok := accounts[accountId]
+	if !ok {
+		return nil, fmt.Errorf("active account '%s' not found in bw CLI data", accountId)
+	}
+
+	userEntry := func(suffix string, out interface{}, required bool) error {
+		return readCLIEntry(data, cliUserKeyPrefix+accountId+suffix, out, required)
+	}
+
+	kdfConfig := cliKdfConfig{}
+	protectedPrivateKey := ""
+	organizationKeys := map[string]cliOrganizationKey{}
+	organizations := map[string]cliOrganization{}
+	ciphers := map[string]models.Item{}
+	folders := map[string]models.Folder{}
+	collections := map[string]webapi.Collection{}
+
+	for _, entry := range []struct {
+		suffix   string
+		out      interface{}
+		required bool
+	}{
+		{cliKdfConfigSuffix, &kdfConfig, true},
+		{cliProtectedPrivateKeySuffix, &protectedPrivateKey, true},
+		{cliOrganizationKeysSuffix, &organizationKeys, false},
+		{cliOrganizationsSuffix, &organizations, false},
+		{cliCiphersSuffix, &ciphers, false},
+		{cliFoldersSuffix, &folders, false},
+		{cliCollectionsSuffix, &collections, false},
+	} {
+		if err := userEntry(entry.suffix, entry.out, entry.required); err != nil {
+			return nil, err
+		}
+	}
+
+	vault := &cliVault{
+		account: Account{
+			AccountUUID: accountId,
+			Email:       account.Email,
+			VaultFormat: "CLI",
+			KdfConfig: models.KdfConfiguration{
+				KdfType:        kdfConfig.KdfType,
+				KdfIterations:  kdfConfig.Iterations,
+				KdfMemory:      kdfConfig.Memory,
+				KdfParallelism: kdfConfig.Parallelism,
+			},
+			ProtectedRSAPrivateKey: protectedPrivateKey,
+		},
+		cachedObjects: webapi.SyncResponse{
+			Ciphers:     sortedValues(ciphers),
+			Folders:     sortedValues(folders),
+			Collections: sortedValues(collections),
+		},
+		data: data,
+	}
+
+	for orgId, orgKey := range organizationKeys {
+		// Provider keys are wrapped differently and don't grant access to
+		// any cipher on their own.
+		if orgKey.Type != "organization" {
+			continue
+		}
+		vault.organizations = append(vault.organizations, webapi.Organization{
+			Id:   orgId,
+			Key:  orgKey.Key,
+			Name: organizations[orgId].Name,
+		})
+	}
+	sort.Slice(vault.organizations, func(i, j int) bool {
+		return vault.organizations[i].Id < vault.organizations[j].Id
+	})
+
+	return vault, nil
+}
+
+func readCLIEntry(data map[string]json.RawMessage, key string, out interface{}, required bool) error {
+	raw, ok := data[key]
+	if !ok || string(raw) == "null" {
+		if required {
+			return fmt.Errorf("'%s' not found in bw CLI data, run 'bw sync' with a recent version of the CLI first", key)
+		}
+		return nil
+	}
+
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("error reading '%s' from bw CLI data: %w", key, err)
+	}
+	return nil
+}
+
+func sortedValues[T any](objects map[string]T) []T {
+	keys := make([]string, 0, len(objects))
+	for k := range objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	values := make([]T, 0, len(objects))
+	for _, k := range keys {
+		values = append(values, objects[k])
+	}
+	return values
+}
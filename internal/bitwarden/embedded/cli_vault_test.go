@@ -0,0 +1,153 @@
+//go:build offline
+
+package embedded
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/webapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCLIVaultMatchesAPILogin(t *testing.T) {
+	apiVault, reset := newMockedPasswordManager(MockedClient(t, Pdkdf2Mocks))
+	defer reset()
+	require.NoError(t, apiVault.LoginWithPassword(t.Context(), AccountPbkdf2.Email, TestPassword))
+
+	vaultPath := t.TempDir()
+	sessionKey := writeUnlockedCLIData(t, vaultPath)
+
+	cliVault := newCLIVaultForTest()
+	require.NoError(t, cliVault.LoadCLIVaultWithSessionKey(t.Context(), vaultPath, sessionKey))
+
+	assert.Equal(t, "CLI", cliVault.loginAccount.VaultFormat)
+	assert.Equal(t, AccountPbkdf2.Email, cliVault.loginAccount.Email)
+	assert.True(t, cliVault.loginAccount.SecretsLoaded())
+	assert.NotEmpty(t, cliVault.objectStore)
+	assert.Equal(t, apiVault.objectStore, cliVault.objectStore)
+
+	err := cliVault.LoadCLIVaultWithSessionKey(t.Context(), vaultPath, sessionKey)
+	assert.ErrorIs(t, err, models.ErrAlreadyLoggedIn)
+}
+
+func TestLoadCLIVaultWrongSessionKey(t *testing.T) {
+	vaultPath := t.TempDir()
+	writeUnlockedCLIData(t, vaultPath)
+	otherSessionKey, _ := newTestSessionKey(t)
+
+	vault := newCLIVaultForTest()
+	err := vault.LoadCLIVaultWithSessionKey(t.Context(), vaultPath, otherSessionKey)
+	assert.ErrorContains(t, err, "error decrypting user key with session key")
+	assert.False(t, vault.loginAccount.LoggedIn())
+}
+
+func TestLoadCLIVaultLocked(t *testing.T) {
+	vaultPath := t.TempDir()
+	writeCLIData(t, vaultPath, cliDataFromSyncFixture(t, "fixtures/test-pbkdf_GET_api_sync.json"))
+	sessionKey, _ := newTestSessionKey(t)
+
+	vault := newCLIVaultForTest()
+	err := vault.LoadCLIVaultWithSessionKey(t.Context(), vaultPath, sessionKey)
+	assert.ErrorContains(t, err, "run 'bw unlock' first")
+}
+
+func TestLoadCLIVaultMissingData(t *testing.T) {
+	sessionKey, _ := newTestSessionKey(t)
+
+	vault := newCLIVaultForTest()
+	err := vault.LoadCLIVaultWithSessionKey(t.Context(), t.TempDir(), sessionKey)
+	assert.ErrorContains(t, err, "error reading bw CLI data")
+}
+
+// writeUnlockedCLIData writes the data.json of a vault the bw CLI synced and
+// unlocked, and returns the session key it printed.
+func writeUnlockedCLIData(t *testing.T, vaultPath string) string {
+	t.Helper()
+
+	accountSecrets, err := decryptAccountSecrets(AccountPbkdf2, TestPassword)
+	require.NoError(t, err)
+
+	sessionKey, rawSessionKey := newTestSessionKey(t)
+	encUserKey, err := crypto.Encrypt(accountSecrets.MainKey.Key, rawSessionKey)
+	require.NoError(t, err)
+	encBuffer, err := encUserKey.ToEncryptedBuffer()
+	require.NoError(t, err)
+
+	data := cliDataFromSyncFixture(t, "fixtures/test-pbkdf_GET_api_sync.json")
+	data[cliProtectedKeyPrefix+AccountPbkdf2.AccountUUID+cliUserKeyAutoSuffix] = base64.StdEncoding.EncodeToString(encBuffer)
+	writeCLIData(t, vaultPath, data)
+	return sessionKey
+}
+
+func newCLIVaultForTest() *webAPIVault {
+	return &webAPIVault{
+		baseVault: baseVault{
+			collectionDetailsLoadedForOrg: map[string]bool{},
+			objectStore:                   make(map[string]interface{}),
+		},
+	}
+}
+
+// cliDataFromSyncFixture lays out the content of a sync response the way
+// the bw CLI stores it in data.json.
+func cliDataFromSyncFixture(t *testing.T, fixture string) map[string]interface{} {
+	t.Helper()
+
+	content, err := os.ReadFile(fixture)
+	require.NoError(t, err)
+
+	var resp webapi.SyncResponse
+	require.NoError(t, json.Unmarshal(content, &resp))
+
+	accountId := resp.Profile.Id
+	userKey := func(suffix string) string {
+		return cliUserKeyPrefix + accountId + suffix
+	}
+
+	// Ciphers are kept as-is, the CLI stores them as received from the server.
+	var rawResp struct {
+		Ciphers []map[string]interface{} `json:"ciphers"`
+	}
+	require.NoError(t, json.Unmarshal(content, &rawResp))
+	ciphers := map[string]interface{}{}
+	for _, c := range rawResp.Ciphers {
+		ciphers[c["id"].(string)] = c
+	}
+	folders := map[string]models.Folder{}
+	for _, f := range resp.Folders {
+		folders[f.ID] = f
+	}
+	collections := map[string]webapi.Collection{}
+	for _, c := range resp.Collections {
+		collections[c.Id] = c
+	}
+	organizationKeys := map[string]cliOrganizationKey{}
+	organizations := map[string]cliOrganization{}
+	for _, o := range resp.Profile.Organizations {
+		organizationKeys[o.Id] = cliOrganizationKey{Type: "organization", Key: o.Key}
+		organizations[o.Id] = cliOrganization{Name: o.Name}
+	}
+
+	return map[string]interface{}{
+		cliActiveAccountIdKey: accountId,
+		cliAccountsKey: map[string]cliAccount{
+			accountId: {Email: resp.Profile.Email},
+		},
+		userKey(cliKdfConfigSuffix): map[string]interface{}{
+			"kdfType":    models.KdfTypePBKDF2_SHA256,
+			"iterations": 1000,
+		},
+		userKey(cliProtectedPrivateKeySuffix): resp.Profile.PrivateKey,
+		userKey(cliOrganizationKeysSuffix):    organizationKeys,
+		userKey(cliOrganizationsSuffix):       organizations,
+		userKey(cliCiphersSuffix):             ciphers,
+		userKey(cliFoldersSuffix):             folders,
+		userKey(cliCollectionsSuffix):         collections,
+	}
+}
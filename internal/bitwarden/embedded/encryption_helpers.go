@@ -2,18 +2,48 @@ package embedded
 
 import (
 	"crypto/rsa"
+	"encoding/base64"
 	"fmt"
 
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/encryptedstring"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/keybuilder"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/symmetrickey"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
 )
 
+// accountSecretsDecrypter unlocks the protected keys of an account.
+type accountSecretsDecrypter func(account Account) (*AccountSecrets, error)
+
+func withMasterPassword(password string) accountSecretsDecrypter {
+	return func(account Account) (*AccountSecrets, error) {
+		return decryptAccountSecrets(account, password)
+	}
+}
+
+func withUserKey(userKey symmetrickey.Key) accountSecretsDecrypter {
+	return func(account Account) (*AccountSecrets, error) {
+		return decryptAccountSecretsWithUserKey(account, userKey)
+	}
+}
+
+func parseUserKey(userKey string) (*symmetrickey.Key, error) {
+	rawKey, err := base64.StdEncoding.DecodeString(userKey)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding user key: %w", err)
+	}
+
+	key, err := symmetrickey.NewFromRawBytes(rawKey)
+	if err != nil {
+		return nil, fmt.Errorf("error loading user key: %w", err)
+	}
+	return key, nil
+}
+
 func decryptOrganizationKey(key string, RSAPrivateKey *rsa.PrivateKey) (*symmetrickey.Key, error) {
 	devV, err := keybuilder.RSADecrypt(key, RSAPrivateKey)
 	if err != nil {
-		return nil, fmt.Errorf("error decrypting organization key: %w", err)
+		return nil, models.Errorf(models.ErrorKindCryptoFailure, "error decrypting organization key: %w", err)
 	}
 
 	return symmetrickey.NewFromRawBytes(devV)
@@ -22,16 +52,20 @@ func decryptOrganizationKey(key string, RSAPrivateKey *rsa.PrivateKey) (*symmetr
 func decryptStringAsBytes(cipherText string, key symmetrickey.Key) ([]byte, error) {
 	encStr, err := encryptedstring.NewFromEncryptedValue(cipherText)
 	if err != nil {
-		return nil, fmt.Errorf("error creating encrypted string from '%s': %v", cipherText, err)
+		return nil, models.Errorf(models.ErrorKindCryptoFailure, "error creating encrypted string from '%s': %v", cipherText, err)
 	}
 
-	return crypto.Decrypt(encStr, &key)
+	decrypted, err := crypto.Decrypt(encStr, &key)
+	if err != nil {
+		return nil, models.NewError(models.ErrorKindCryptoFailure, err)
+	}
+	return decrypted, nil
 }
 
 func decryptStringAsKey(cipherText string, key symmetrickey.Key) (*symmetrickey.Key, error) {
 	decStr, err := decryptStringAsBytes(cipherText, key)
 	if err != nil {
-		return nil, fmt.Errorf("error decrypting string as bytes: %v", err)
+		return nil, fmt.Errorf("error decrypting string as bytes: %w", err)
 	}
 
 	return symmetrickey.NewFromRawBytes(decStr)
@@ -55,7 +89,7 @@ func encryptAsStringIfNotEmpty(plainValue string, key symmetrickey.Key) (string,
 	}
 	res, err := crypto.Encrypt([]byte(plainValue), key)
 	if err != nil {
-		return "", err
+		return "", models.NewError(models.ErrorKindCryptoFailure, err)
 	}
 	return res.String(), nil
 }
@@ -0,0 +1,228 @@
+package embedded
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/keybuilder"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+)
+
+var errExportFileReadOnly = fmt.Errorf("%w: it was loaded from a Bitwarden export", models.ErrReadOnlyVault)
+
+// exportFile is the content of a Bitwarden JSON export. Password-protected
+// exports only have the encryption parameters and the encrypted Data, which
+// holds an unencrypted export once decrypted.
+type exportFile struct {
+	Encrypted         bool               `json:"encrypted"`
+	PasswordProtected bool               `json:"passwordProtected"`
+	Salt              string             `json:"salt"`
+	KdfType           models.KdfType     `json:"kdfType"`
+	KdfIterations     int                `json:"kdfIterations"`
+	KdfMemory         int                `json:"kdfMemory"`
+	KdfParallelism    int                `json:"kdfParallelism"`
+	EncKeyValidation  string             `json:"encKeyValidation_DO_NOT_EDIT"`
+	Data              string             `json:"data"`
+	Collections       []exportCollection `json:"collections"`
+	Folders           []models.Folder    `json:"folders"`
+	Items             []models.Item      `json:"items"`
+}
+
+type exportCollection struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	OrganizationID string `json:"organizationId"`
+}
+
+// exportFileVault serves the content of a Bitwarden JSON export. It never
+// contacts a server and rejects any write.
+type exportFileVault struct {
+	baseVault
+}
+
+// LoadExportFile returns a read-only Password Manager serving the items,
+// folders and collections of the Bitwarden JSON export at filePath.
+// Password-protected exports are decrypted with password, which is ignored
+// for unencrypted exports.
+func LoadExportFile(ctx context.Context, filePath, password string) (bitwarden.PasswordManager, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading Bitwarden export: %w", err)
+	}
+
+	export, err := parseExportFile(content, password)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &exportFileVault{
+		baseVault: baseVault{
+			objectStore: make(map[string]interface{}),
+		},
+	}
+
+	for _, collection := range export.Collections {
+		v.storeObject(ctx, models.OrgCollection{
+			ID:             collection.ID,
+			Name:           collection.Name,
+			Object:         models.ObjectTypeOrgCollection,
+			OrganizationID: collection.OrganizationID,
+		})
+	}
+
+	for _, folder := range export.Folders {
+		folder.Object = models.ObjectTypeFolder
+		v.storeObject(ctx, folder)
+	}
+
+	for _, item := range export.Items {
+		item.Object = models.ObjectTypeItem
+		v.storeObject(ctx, item)
+	}
+
+	return v, nil
+}
+
+func parseExportFile(content []byte, password string) (*exportFile, error) {
+	export := exportFile{}
+	if err := json.Unmarshal(content, &export); err != nil {
+		return nil, fmt.Errorf("error parsing Bitwarden export: %w", err)
+	}
+
+	if !export.Encrypted {
+		return &export, nil
+	}
+
+	if !export.PasswordProtected {
+		return nil, fmt.Errorf("exports encrypted with the account's key are not supported, use an unencrypted or password-protected export instead")
+	}
+
+	if len(password) == 0 {
+		return nil, fmt.Errorf("a password is required to decrypt a password-protected export")
+	}
+
+	key, err := keybuilder.BuildExportKey(password, export.Salt, models.KdfConfiguration{
+		KdfType:        export.KdfType,
+		KdfIterations:  export.KdfIterations,
+		KdfMemory:      export.KdfMemory,
+		KdfParallelism: export.KdfParallelism,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error building export key: %w", err)
+	}
+
+	// The validation value is a random string, only there to detect a wrong
+	// password before decrypting the whole export.
+	if _, err := decryptStringAsBytes(export.EncKeyValidation, *key); err != nil {
+		return nil, fmt.Errorf("error decrypting export, the password is likely wrong: %w", err)
+	}
+
+	data, err := decryptStringAsBytes(export.Data, *key)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting export data: %w", err)
+	}
+
+	decryptedExport := exportFile{}
+	if err := json.Unmarshal(data, &decryptedExport); err != nil {
+		return nil, fmt.Errorf("error parsing decrypted Bitwarden export: %w", err)
+	}
+	return &decryptedExport, nil
+}
+
+func (v *exportFileVault) FindOrganizationCollection(ctx context.Context, options ...bitwarden.ListObjectsOption) (*models.OrgCollection, error) {
+	v.vaultOperationMutex.RLock()
+	defer v.vaultOperationMutex.RUnlock()
+
+	return findObject[models.OrgCollection](ctx, v.objectStore, models.ObjectTypeOrgCollection, options...)
+}
+
+func (v *exportFileVault) FindOrganizationGroup(ctx context.Context, options ...bitwarden.ListObjectsOption) (*models.OrgGroup, error) {
+	return nil, fmt.Errorf("organization groups are not part of Bitwarden exports")
+}
+
+func (v *exportFileVault) FindOrganizationMember(ctx context.Context, options ...bitwarden.ListObjectsOption) (*models.OrgMember, error) {
+	return nil, fmt.Errorf("organization members are not part of Bitwarden exports")
+}
+
+func (v *exportFileVault) GetAttachment(ctx context.Context, itemId, attachmentId string) ([]byte, error) {
+	return nil, fmt.Errorf("attachment content is not part of Bitwarden exports")
+}
+
+func (v *exportFileVault) GetOrganizationGroup(ctx context.Context, obj models.OrgGroup) (*models.OrgGroup, error) {
+	return nil, fmt.Errorf("organization groups are not part of Bitwarden exports")
+}
+
+func (v *exportFileVault) GetOrganizationMember(ctx context.Context, obj models.OrgMember) (*models.OrgMember, error) {
+	return nil, fmt.Errorf("organization members are not part of Bitwarden exports")
+}
+
+func (v *exportFileVault) LoginWithAPIKey(ctx context.Context, password, clientId, clientSecret string) error {
+	return models.ErrAlreadyLoggedIn
+}
+
+func (v *exportFileVault) LoginWithPassword(ctx context.Context, username, password string) error {
+	return models.ErrAlreadyLoggedIn
+}
+
+func (v *exportFileVault) Sync(ctx context.Context) error {
+	return nil
+}
+
+func (v *exportFileVault) CreateAttachmentFromContent(ctx context.Context, itemId, filename string, content []byte) (*models.Attachment, error) {
+	return nil, errExportFileReadOnly
+}
+
+func (v *exportFileVault) CreateAttachmentFromFile(ctx context.Context, itemId, filePath string) (*models.Attachment, error) {
+	return nil, errExportFileReadOnly
+}
+
+func (v *exportFileVault) CreateFolder(ctx context.Context, obj models.Folder) (*models.Folder, error) {
+	return nil, errExportFileReadOnly
+}
+
+func (v *exportFileVault) CreateItem(ctx context.Context, obj models.Item) (*models.Item, error) {
+	return nil, errExportFileReadOnly
+}
+
+func (v *exportFileVault) CreateOrganizationCollection(ctx context.Context, obj models.OrgCollection) (*models.OrgCollection, error) {
+	return nil, errExportFileReadOnly
+}
+
+func (v *exportFileVault) CreateOrganizationGroup(ctx context.Context, obj models.OrgGroup) (*models.OrgGroup, error) {
+	return nil, errExportFileReadOnly
+}
+
+func (v *exportFileVault) DeleteAttachment(ctx context.Context, itemId, attachmentId string) error {
+	return errExportFileReadOnly
+}
+
+func (v *exportFileVault) DeleteFolder(ctx context.Context, obj models.Folder) error {
+	return errExportFileReadOnly
+}
+
+func (v *exportFileVault) DeleteItem(ctx context.Context, obj models.Item) error {
+	return errExportFileReadOnly
+}
+
+func (v *exportFileVault) DeleteOrganizationCollection(ctx context.Context, obj models.OrgCollection) error {
+	return errExportFileReadOnly
+}
+
+func (v *exportFileVault) DeleteOrganizationGroup(ctx context.Context, obj models.OrgGroup) error {
+	return errExportFileReadOnly
+}
+
+func (v *exportFileVault) EditFolder(ctx context.Context, obj models.Folder) (*models.Folder, error) {
+	return nil, errExportFileReadOnly
+}
+
+func (v *exportFileVault) EditItem(ctx context.Context, obj models.Item) (*models.Item, error) {
+	return nil, errExportFileReadOnly
+}
+
+func (v *exportFileVault) EditOrganizationCollection(ctx context.Context, obj models.OrgCollection) (*models.OrgCollection, error) {
+	return nil, errExportFileReadOnly
+}
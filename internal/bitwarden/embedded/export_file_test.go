@@ -0,0 +1,146 @@
+//go:build offline
+
+package embedded
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/keybuilder"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testUnencryptedExport = `{
+  "encrypted": false,
+  "collections": [
+    {"id": "collection-id", "organizationId": "org-id", "name": "Infrastructure", "externalId": null}
+  ],
+  "folders": [
+    {"id": "folder-id", "name": "Databases"}
+  ],
+  "items": [
+    {
+      "id": "item-id",
+      "organizationId": "org-id",
+      "folderId": "folder-id",
+      "type": 1,
+      "reprompt": 0,
+      "name": "Postgres",
+      "notes": "primary",
+      "favorite": false,
+      "login": {
+        "uris": [{"match": null, "uri": "https://db.example.com"}],
+        "username": "admin",
+        "password": "exported-password",
+        "totp": null
+      },
+      "collectionIds": ["collection-id"],
+      "attachments": [
+        {"id": "attachment-id", "fileName": "cert.pem", "size": "12", "sizeName": "12 Bytes", "url": "https://example.com/a"}
+      ]
+    }
+  ]
+}`
+
+func TestLoadExportFileUnencrypted(t *testing.T) {
+	vault, err := LoadExportFile(t.Context(), writeExportFile(t, testUnencryptedExport), "")
+	require.NoError(t, err)
+
+	item, err := vault.GetItem(t.Context(), models.Item{ID: "item-id", Type: models.ItemTypeLogin})
+	require.NoError(t, err)
+	assert.Equal(t, "Postgres", item.Name)
+	assert.Equal(t, "exported-password", item.Login.Password)
+	if assert.Len(t, item.Attachments, 1) {
+		assert.Equal(t, "cert.pem", item.Attachments[0].FileName)
+	}
+
+	item, err = vault.FindItem(t.Context(), bitwarden.WithSearch("Postgres"), bitwarden.WithItemType(int(models.ItemTypeLogin)))
+	require.NoError(t, err)
+	assert.Equal(t, "item-id", item.ID)
+
+	folder, err := vault.FindFolder(t.Context(), bitwarden.WithSearch("Databases"))
+	require.NoError(t, err)
+	assert.Equal(t, "folder-id", folder.ID)
+
+	collection, err := vault.FindOrganizationCollection(t.Context(), bitwarden.WithSearch("Infra"), bitwarden.WithOrganizationID("org-id"))
+	require.NoError(t, err)
+	assert.Equal(t, "collection-id", collection.ID)
+
+	_, err = vault.GetAttachment(t.Context(), "item-id", "attachment-id")
+	assert.ErrorContains(t, err, "not part of Bitwarden exports")
+}
+
+func TestLoadExportFileRejectsWrites(t *testing.T) {
+	vault, err := LoadExportFile(t.Context(), writeExportFile(t, testUnencryptedExport), "")
+	require.NoError(t, err)
+
+	_, err = vault.CreateItem(t.Context(), models.Item{Name: "new"})
+	assert.ErrorIs(t, err, models.ErrReadOnlyVault)
+
+	_, err = vault.EditFolder(t.Context(), models.Folder{ID: "folder-id"})
+	assert.ErrorIs(t, err, models.ErrReadOnlyVault)
+
+	err = vault.DeleteItem(t.Context(), models.Item{ID: "item-id"})
+	assert.ErrorIs(t, err, models.ErrReadOnlyVault)
+}
+
+func TestLoadExportFilePasswordProtected(t *testing.T) {
+	kdfConfig := models.KdfConfiguration{
+		KdfType:       models.KdfTypePBKDF2_SHA256,
+		KdfIterations: 1000,
+	}
+	salt := "c2FsdC1mb3ItdGhlLWV4cG9ydA=="
+
+	key, err := keybuilder.BuildExportKey("export-password", salt, kdfConfig)
+	require.NoError(t, err)
+	encValidation, err := crypto.EncryptAsString([]byte("validation"), *key)
+	require.NoError(t, err)
+	encData, err := crypto.EncryptAsString([]byte(testUnencryptedExport), *key)
+	require.NoError(t, err)
+
+	content, err := json.Marshal(map[string]interface{}{
+		"encrypted":                    true,
+		"passwordProtected":            true,
+		"salt":                         salt,
+		"kdfType":                      kdfConfig.KdfType,
+		"kdfIterations":                kdfConfig.KdfIterations,
+		"kdfMemory":                    nil,
+		"kdfParallelism":               nil,
+		"encKeyValidation_DO_NOT_EDIT": encValidation,
+		"data":                         encData,
+	})
+	require.NoError(t, err)
+	exportPath := writeExportFile(t, string(content))
+
+	vault, err := LoadExportFile(t.Context(), exportPath, "export-password")
+	require.NoError(t, err)
+
+	item, err := vault.GetItem(t.Context(), models.Item{ID: "item-id", Type: models.ItemTypeLogin})
+	require.NoError(t, err)
+	assert.Equal(t, "exported-password", item.Login.Password)
+
+	_, err = LoadExportFile(t.Context(), exportPath, "wrong-password")
+	assert.ErrorContains(t, err, "the password is likely wrong")
+
+	_, err = LoadExportFile(t.Context(), exportPath, "")
+	assert.ErrorContains(t, err, "a password is required")
+}
+
+func TestLoadExportFileAccountRestricted(t *testing.T) {
+	_, err := LoadExportFile(t.Context(), writeExportFile(t, `{"encrypted": true, "encKeyValidation_DO_NOT_EDIT": "2.xx", "items": []}`), "")
+	assert.ErrorContains(t, err, "exports encrypted with the account's key are not supported")
+}
+
+func writeExportFile(t *testing.T, content string) string {
+	t.Helper()
+
+	exportPath := filepath.Join(t.TempDir(), "export.json")
+	require.NoError(t, os.WriteFile(exportPath, []byte(content), 0600))
+	return exportPath
+}
@@ -0,0 +1,752 @@
+package embedded
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+)
+
+// MemoryVault is both a Password Manager and a Secrets Manager, so that a
+// single instance can back the two clients of the provider.
+type MemoryVault interface {
+	bitwarden.PasswordManager
+	bitwarden.SecretsManager
+}
+
+// memoryFixture is the content of the JSON file a memory vault can be seeded
+// with. Items, folders and collections use the same format as an unencrypted
+// Bitwarden export.
+type memoryFixture struct {
+	// SecretsManagerOrganizationID is the organization projects and secrets
+	// are created in, like the one of a machine account's access token.
+	SecretsManagerOrganizationID string                    `json:"secretsManagerOrganizationId"`
+	Organizations                []models.Organization     `json:"organizations"`
+	Collections                  []models.OrgCollection    `json:"collections"`
+	Groups                       []models.OrgGroup         `json:"groups"`
+	Members                      []models.OrgMember        `json:"members"`
+	Folders                      []models.Folder           `json:"folders"`
+	Items                        []models.Item             `json:"items"`
+	Attachments                  []memoryFixtureAttachment `json:"attachments"`
+	Projects                     []models.Project          `json:"projects"`
+	Secrets                      []models.Secret           `json:"secrets"`
+}
+
+type memoryFixtureAttachment struct {
+	ItemID   string `json:"itemId"`
+	FileName string `json:"fileName"`
+	Content  string `json:"content"`
+}
+
+// memoryVault keeps every object in memory and never contacts a server. It
+// mimics the semantics of a real server closely enough for modules to be
+// tested end to end: objects get IDs and revision dates, references to
+// folders, organizations, collections and projects are checked, and missing
+// objects are reported with the same errors as the other clients.
+type memoryVault struct {
+	baseVault
+
+	// attachmentContents holds the content of attachments, indexed by item
+	// and attachment ID.
+	attachmentContents map[string][]byte
+	groups             map[string]models.OrgGroup
+	members            map[string]models.OrgMember
+	projects           map[string]models.Project
+	secrets            map[string]models.Secret
+
+	secretsManagerOrganizationID string
+}
+
+// NewMemoryVault returns an empty vault living in memory, or one seeded with
+// the objects of the JSON fixture at fixturePath when it isn't empty.
+func NewMemoryVault(ctx context.Context, fixturePath string) (MemoryVault, error) {
+	v := &memoryVault{
+		baseVault: baseVault{
+			objectStore: make(map[string]interface{}),
+		},
+		attachmentContents:           make(map[string][]byte),
+		groups:                       make(map[string]models.OrgGroup),
+		members:                      make(map[string]models.OrgMember),
+		projects:                     make(map[string]models.Project),
+		secrets:                      make(map[string]models.Secret),
+		secretsManagerOrganizationID: uuid.New().String(),
+	}
+
+	if len(fixturePath) == 0 {
+		return v, nil
+	}
+
+	content, err := os.ReadFile(fixturePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading memory vault fixture: %w", err)
+	}
+
+	fixture := memoryFixture{}
+	if err := json.Unmarshal(content, &fixture); err != nil {
+		return nil, fmt.Errorf("error parsing memory vault fixture: %w", err)
+	}
+
+	if err := v.seed(ctx, fixture); err != nil {
+		return nil, fmt.Errorf("error seeding memory vault: %w", err)
+	}
+	return v, nil
+}
+
+func (v *memoryVault) seed(ctx context.Context, fixture memoryFixture) error {
+	seededAt := time.Now().UTC()
+
+	if len(fixture.SecretsManagerOrganizationID) > 0 {
+		v.secretsManagerOrganizationID = fixture.SecretsManagerOrganizationID
+	}
+
+	for _, org := range fixture.Organizations {
+		org.ID = idOrNew(org.ID)
+		org.Object = models.ObjectTypeOrganization
+		v.storeObject(ctx, org)
+	}
+
+	for _, collection := range fixture.Collections {
+		collection.ID = idOrNew(collection.ID)
+		collection.Object = models.ObjectTypeOrgCollection
+		v.storeObject(ctx, collection)
+	}
+
+	for _, group := range fixture.Groups {
+		group.ID = idOrNew(group.ID)
+		v.groups[group.ID] = group
+	}
+
+	for _, member := range fixture.Members {
+		member.ID = idOrNew(member.ID)
+		v.members[member.ID] = member
+	}
+
+	for _, folder := range fixture.Folders {
+		folder.ID = idOrNew(folder.ID)
+		folder.Object = models.ObjectTypeFolder
+		folder.RevisionDate = &seededAt
+		v.storeObject(ctx, folder)
+	}
+
+	for _, item := range fixture.Items {
+		item.ID = idOrNew(item.ID)
+		item.Object = models.ObjectTypeItem
+		item.Edit = true
+		item.ViewPassword = true
+		if item.CreationDate == nil {
+			item.CreationDate = &seededAt
+		}
+		if item.RevisionDate == nil {
+			item.RevisionDate = &seededAt
+		}
+		if err := v.checkItemReferences(item); err != nil {
+			return fmt.Errorf("item '%s': %w", item.ID, err)
+		}
+		v.storeObject(ctx, item)
+	}
+
+	for _, attachment := range fixture.Attachments {
+		if _, err := v.createAttachment(ctx, attachment.ItemID, attachment.FileName, []byte(attachment.Content)); err != nil {
+			return fmt.Errorf("attachment '%s': %w", attachment.FileName, err)
+		}
+	}
+
+	for _, project := range fixture.Projects {
+		project.ID = idOrNew(project.ID)
+		if len(project.OrganizationID) == 0 {
+			project.OrganizationID = v.secretsManagerOrganizationID
+		}
+		project.Object = string(models.ObjectProject)
+		project.CreationDate = seededAt
+		project.RevisionDate = seededAt
+		v.projects[project.ID] = project
+	}
+
+	for _, secret := range fixture.Secrets {
+		if _, ok := v.projects[secret.ProjectID]; !ok {
+			return fmt.Errorf("secret '%s': project '%s': %w", secret.Key, secret.ProjectID, models.ErrObjectNotFound)
+		}
+		secret.ID = idOrNew(secret.ID)
+		if len(secret.OrganizationID) == 0 {
+			secret.OrganizationID = v.secretsManagerOrganizationID
+		}
+		secret.Object = models.ObjectSecret
+		secret.CreationDate = seededAt
+		secret.RevisionDate = seededAt
+		v.secrets[secret.ID] = secret
+	}
+	return nil
+}
+
+func (v *memoryVault) CreateAttachmentFromContent(ctx context.Context, itemId, filename string, content []byte) (*models.Attachment, error) {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	return v.createAttachment(ctx, itemId, filename, content)
+}
+
+func (v *memoryVault) CreateAttachmentFromFile(ctx context.Context, itemId, filePath string) (*models.Attachment, error) {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading attachment file: %w", err)
+	}
+
+	return v.createAttachment(ctx, itemId, filepath.Base(filePath), data)
+}
+
+func (v *memoryVault) createAttachment(ctx context.Context, itemId, filename string, content []byte) (*models.Attachment, error) {
+	item, err := getObject(v.objectStore, models.Item{ID: itemId})
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := models.Attachment{
+		ID:       uuid.New().String(),
+		FileName: filename,
+		Size:     fmt.Sprintf("%d", len(content)),
+		SizeName: fmt.Sprintf("%d Bytes", len(content)),
+		Object:   models.ObjectTypeAttachment,
+	}
+
+	item.Attachments = append(slices.Clone(item.Attachments), attachment)
+	item.RevisionDate = revisionDate()
+	v.storeObject(ctx, *item)
+	v.attachmentContents[attachmentContentKey(itemId, attachment.ID)] = slices.Clone(content)
+
+	return &attachment, nil
+}
+
+func (v *memoryVault) CreateFolder(ctx context.Context, obj models.Folder) (*models.Folder, error) {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	obj.ID = uuid.New().String()
+	obj.Object = models.ObjectTypeFolder
+	obj.RevisionDate = revisionDate()
+
+	v.storeObject(ctx, obj)
+	return &obj, nil
+}
+
+func (v *memoryVault) CreateItem(ctx context.Context, obj models.Item) (*models.Item, error) {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	if err := v.checkItemReferences(obj); err != nil {
+		return nil, fmt.Errorf("error creating item: %w", err)
+	}
+
+	obj.ID = uuid.New().String()
+	obj.Object = models.ObjectTypeItem
+	obj.Attachments = nil
+	obj.DeletedDate = nil
+	obj.Edit = true
+	obj.ViewPassword = true
+	obj.CreationDate = revisionDate()
+	obj.RevisionDate = obj.CreationDate
+
+	v.storeObject(ctx, obj)
+	return &obj, nil
+}
+
+func (v *memoryVault) CreateOrganizationCollection(ctx context.Context, obj models.OrgCollection) (*models.OrgCollection, error) {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	if _, err := getObject(v.objectStore, models.Organization{ID: obj.OrganizationID}); err != nil {
+		return nil, fmt.Errorf("error creating collection, organization '%s': %w", obj.OrganizationID, err)
+	}
+
+	obj.ID = uuid.New().String()
+	obj.Object = models.ObjectTypeOrgCollection
+
+	v.storeObject(ctx, obj)
+	return &obj, nil
+}
+
+func (v *memoryVault) CreateOrganizationGroup(ctx context.Context, obj models.OrgGroup) (*models.OrgGroup, error) {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	if _, err := getObject(v.objectStore, models.Organization{ID: obj.OrganizationID}); err != nil {
+		return nil, fmt.Errorf("error creating group, organization '%s': %w", obj.OrganizationID, err)
+	}
+
+	obj.ID = uuid.New().String()
+	v.groups[obj.ID] = obj
+	return &obj, nil
+}
+
+func (v *memoryVault) DeleteAttachment(ctx context.Context, itemId, attachmentId string) error {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	item, err := getObject(v.objectStore, models.Item{ID: itemId})
+	if err != nil {
+		return err
+	}
+
+	attachments := slices.DeleteFunc(slices.Clone(item.Attachments), func(a models.Attachment) bool {
+		return a.ID == attachmentId
+	})
+	if len(attachments) == len(item.Attachments) {
+		return models.ErrAttachmentNotFound
+	}
+
+	item.Attachments = attachments
+	item.RevisionDate = revisionDate()
+	v.storeObject(ctx, *item)
+	delete(v.attachmentContents, attachmentContentKey(itemId, attachmentId))
+	return nil
+}
+
+func (v *memoryVault) DeleteFolder(ctx context.Context, obj models.Folder) error {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	if _, err := getObject(v.objectStore, obj); err != nil {
+		return err
+	}
+
+	// Like on a real server, items of a deleted folder are moved out of it.
+	v.updateItems(ctx, func(item *models.Item) bool {
+		if item.FolderID != obj.ID {
+			return false
+		}
+		item.FolderID = ""
+		return true
+	})
+
+	v.deleteObjectFromStore(ctx, obj)
+	return nil
+}
+
+func (v *memoryVault) DeleteItem(ctx context.Context, obj models.Item) error {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	item, err := getObject(v.objectStore, models.Item{ID: obj.ID})
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range item.Attachments {
+		delete(v.attachmentContents, attachmentContentKey(item.ID, attachment.ID))
+	}
+
+	v.deleteObjectFromStore(ctx, obj)
+	return nil
+}
+
+func (v *memoryVault) DeleteOrganizationCollection(ctx context.Context, obj models.OrgCollection) error {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	if _, err := getObject(v.objectStore, obj); err != nil {
+		return err
+	}
+
+	// Items remain in the organization, only their membership is removed.
+	v.updateItems(ctx, func(item *models.Item) bool {
+		if !slices.Contains(item.CollectionIds, obj.ID) {
+			return false
+		}
+		item.CollectionIds = slices.DeleteFunc(slices.Clone(item.CollectionIds), func(id string) bool {
+			return id == obj.ID
+		})
+		return true
+	})
+
+	v.deleteObjectFromStore(ctx, obj)
+	return nil
+}
+
+func (v *memoryVault) DeleteOrganizationGroup(ctx context.Context, obj models.OrgGroup) error {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	if group, ok := v.groups[obj.ID]; !ok || group.OrganizationID != obj.OrganizationID {
+		return models.ErrObjectNotFound
+	}
+
+	delete(v.groups, obj.ID)
+	return nil
+}
+
+func (v *memoryVault) EditFolder(ctx context.Context, obj models.Folder) (*models.Folder, error) {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	if _, err := getObject(v.objectStore, obj); err != nil {
+		return nil, err
+	}
+
+	obj.Object = models.ObjectTypeFolder
+	obj.RevisionDate = revisionDate()
+
+	v.storeObject(ctx, obj)
+	return &obj, nil
+}
+
+func (v *memoryVault) EditItem(ctx context.Context, obj models.Item) (*models.Item, error) {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	storedObj, err := getObject(v.objectStore, models.Item{ID: obj.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := v.checkItemReferences(obj); err != nil {
+		return nil, fmt.Errorf("error editing item: %w", err)
+	}
+
+	// Attachments are managed through their own endpoints and are kept
+	// as they are.
+	obj.Object = models.ObjectTypeItem
+	obj.Attachments = storedObj.Attachments
+	obj.CreationDate = storedObj.CreationDate
+	obj.DeletedDate = nil
+	obj.Edit = true
+	obj.ViewPassword = true
+	obj.RevisionDate = revisionDate()
+
+	v.storeObject(ctx, obj)
+	return &obj, nil
+}
+
+func (v *memoryVault) EditOrganizationCollection(ctx context.Context, obj models.OrgCollection) (*models.OrgCollection, error) {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	storedObj, err := getObject(v.objectStore, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	obj.Object = models.ObjectTypeOrgCollection
+	obj.OrganizationID = storedObj.OrganizationID
+
+	v.storeObject(ctx, obj)
+	return &obj, nil
+}
+
+func (v *memoryVault) FindOrganizationCollection(ctx context.Context, options ...bitwarden.ListObjectsOption) (*models.OrgCollection, error) {
+	v.vaultOperationMutex.RLock()
+	defer v.vaultOperationMutex.RUnlock()
+
+	return findObject[models.OrgCollection](ctx, v.objectStore, models.ObjectTypeOrgCollection, options...)
+}
+
+func (v *memoryVault) FindOrganizationGroup(ctx context.Context, options ...bitwarden.ListObjectsOption) (*models.OrgGroup, error) {
+	v.vaultOperationMutex.RLock()
+	defer v.vaultOperationMutex.RUnlock()
+
+	filter := bitwarden.ListObjectsOptionsToFilterOptions(options...)
+	if !filter.HasSearchFilter() {
+		return nil, fmt.Errorf("missing search filter")
+	}
+
+	return findUnique(v.groups, func(group models.OrgGroup) bool {
+		return group.OrganizationID == filter.OrganizationFilter && group.Name == filter.SearchFilter
+	})
+}
+
+func (v *memoryVault) FindOrganizationMember(ctx context.Context, options ...bitwarden.ListObjectsOption) (*models.OrgMember, error) {
+	v.vaultOperationMutex.RLock()
+	defer v.vaultOperationMutex.RUnlock()
+
+	filter := bitwarden.ListObjectsOptionsToFilterOptions(options...)
+	if !filter.HasSearchFilter() {
+		return nil, fmt.Errorf("missing search filter")
+	}
+
+	return findUnique(v.members, func(member models.OrgMember) bool {
+		return member.OrganizationId == filter.OrganizationFilter && member.Email == filter.SearchFilter
+	})
+}
+
+func (v *memoryVault) GetAttachment(ctx context.Context, itemId, attachmentId string) ([]byte, error) {
+	v.vaultOperationMutex.RLock()
+	defer v.vaultOperationMutex.RUnlock()
+
+	// Check if the item exists beforehand, in order to differentiate between
+	// missing attachments, and missing items.
+	if _, err := getObject(v.objectStore, models.Item{ID: itemId}); err != nil {
+		return nil, err
+	}
+
+	content, ok := v.attachmentContents[attachmentContentKey(itemId, attachmentId)]
+	if !ok {
+		return nil, models.ErrAttachmentNotFound
+	}
+	return slices.Clone(content), nil
+}
+
+func (v *memoryVault) GetOrganizationGroup(ctx context.Context, obj models.OrgGroup) (*models.OrgGroup, error) {
+	v.vaultOperationMutex.RLock()
+	defer v.vaultOperationMutex.RUnlock()
+
+	group, ok := v.groups[obj.ID]
+	if !ok || group.OrganizationID != obj.OrganizationID {
+		return nil, models.ErrObjectNotFound
+	}
+	return &group, nil
+}
+
+func (v *memoryVault) GetOrganizationMember(ctx context.Context, obj models.OrgMember) (*models.OrgMember, error) {
+	v.vaultOperationMutex.RLock()
+	defer v.vaultOperationMutex.RUnlock()
+
+	member, ok := v.members[obj.ID]
+	if !ok || member.OrganizationId != obj.OrganizationId {
+		return nil, models.ErrObjectNotFound
+	}
+	return &member, nil
+}
+
+func (v *memoryVault) LoginWithAPIKey(ctx context.Context, password, clientId, clientSecret string) error {
+	return models.ErrAlreadyLoggedIn
+}
+
+func (v *memoryVault) LoginWithPassword(ctx context.Context, username, password string) error {
+	return models.ErrAlreadyLoggedIn
+}
+
+func (v *memoryVault) Sync(ctx context.Context) error {
+	return nil
+}
+
+func (v *memoryVault) CreateProject(ctx context.Context, project models.Project) (*models.Project, error) {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	project.ID = uuid.New().String()
+	project.OrganizationID = v.secretsManagerOrganizationID
+	project.Object = string(models.ObjectProject)
+	project.CreationDate = *revisionDate()
+	project.RevisionDate = project.CreationDate
+
+	v.projects[project.ID] = project
+	return &project, nil
+}
+
+func (v *memoryVault) CreateSecret(ctx context.Context, secret models.Secret) (*models.Secret, error) {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	if _, ok := v.projects[secret.ProjectID]; !ok {
+		return nil, models.ErrObjectNotFound
+	}
+
+	secret.ID = uuid.New().String()
+	secret.OrganizationID = v.secretsManagerOrganizationID
+	secret.Object = models.ObjectSecret
+	secret.CreationDate = *revisionDate()
+	secret.RevisionDate = secret.CreationDate
+
+	v.secrets[secret.ID] = secret
+	return &secret, nil
+}
+
+func (v *memoryVault) DeleteProject(ctx context.Context, project models.Project) error {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	if _, ok := v.projects[project.ID]; !ok {
+		return models.ErrObjectNotFound
+	}
+
+	// Secrets aren't deleted with their project on a real server either, but
+	// they can't be accessed anymore by a machine account.
+	delete(v.projects, project.ID)
+	return nil
+}
+
+func (v *memoryVault) DeleteSecret(ctx context.Context, secret models.Secret) error {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	if _, ok := v.secrets[secret.ID]; !ok {
+		return models.ErrObjectNotFound
+	}
+
+	delete(v.secrets, secret.ID)
+	return nil
+}
+
+func (v *memoryVault) EditProject(ctx context.Context, project models.Project) (*models.Project, error) {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	storedProject, ok := v.projects[project.ID]
+	if !ok {
+		return nil, models.ErrObjectNotFound
+	}
+
+	storedProject.Name = project.Name
+	storedProject.RevisionDate = *revisionDate()
+
+	v.projects[project.ID] = storedProject
+	return &storedProject, nil
+}
+
+func (v *memoryVault) EditSecret(ctx context.Context, secret models.Secret) (*models.Secret, error) {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	storedSecret, ok := v.secrets[secret.ID]
+	if !ok {
+		return nil, models.ErrObjectNotFound
+	}
+
+	if _, ok := v.projects[secret.ProjectID]; !ok {
+		return nil, models.ErrObjectNotFound
+	}
+
+	storedSecret.Key = secret.Key
+	storedSecret.Value = secret.Value
+	storedSecret.Note = secret.Note
+	storedSecret.ProjectID = secret.ProjectID
+	storedSecret.RevisionDate = *revisionDate()
+
+	v.secrets[secret.ID] = storedSecret
+	return &storedSecret, nil
+}
+
+func (v *memoryVault) GetProject(ctx context.Context, project models.Project) (*models.Project, error) {
+	v.vaultOperationMutex.RLock()
+	defer v.vaultOperationMutex.RUnlock()
+
+	storedProject, ok := v.projects[project.ID]
+	if !ok {
+		return nil, models.ErrObjectNotFound
+	}
+	return &storedProject, nil
+}
+
+func (v *memoryVault) GetSecret(ctx context.Context, secret models.Secret) (*models.Secret, error) {
+	v.vaultOperationMutex.RLock()
+	defer v.vaultOperationMutex.RUnlock()
+
+	return v.getSecret(secret.ID)
+}
+
+func (v *memoryVault) getSecret(secretId string) (*models.Secret, error) {
+	storedSecret, ok := v.secrets[secretId]
+	if !ok {
+		return nil, models.ErrObjectNotFound
+	}
+
+	if _, ok := v.projects[storedSecret.ProjectID]; !ok {
+		return nil, models.ErrObjectNotFound
+	}
+	return &storedSecret, nil
+}
+
+func (v *memoryVault) GetSecretByKey(ctx context.Context, secretKey string) (*models.Secret, error) {
+	v.vaultOperationMutex.RLock()
+	defer v.vaultOperationMutex.RUnlock()
+
+	secret, err := findUnique(v.secrets, func(secret models.Secret) bool {
+		_, projectExists := v.projects[secret.ProjectID]
+		return projectExists && secret.Key == secretKey
+	})
+	if errors.Is(err, models.ErrNoObjectFoundMatchingFilter) {
+		return nil, models.ErrObjectNotFound
+	}
+	return secret, err
+}
+
+func (v *memoryVault) LoginWithAccessToken(ctx context.Context, accessToken string) error {
+	return nil
+}
+
+// checkItemReferences verifies that the folder, organization and collections
+// an item refers to exist, as a server would refuse the item otherwise.
+func (v *memoryVault) checkItemReferences(obj models.Item) error {
+	if len(obj.FolderID) > 0 {
+		if _, err := getObject(v.objectStore, models.Folder{ID: obj.FolderID}); err != nil {
+			return fmt.Errorf("folder '%s': %w", obj.FolderID, err)
+		}
+	}
+
+	if len(obj.OrganizationID) == 0 {
+		if len(obj.CollectionIds) > 0 {
+			return fmt.Errorf("items outside of an organization can't be part of collections")
+		}
+		return nil
+	}
+
+	if _, err := getObject(v.objectStore, models.Organization{ID: obj.OrganizationID}); err != nil {
+		return fmt.Errorf("organization '%s': %w", obj.OrganizationID, err)
+	}
+
+	for _, collectionId := range obj.CollectionIds {
+		collection, err := getObject(v.objectStore, models.OrgCollection{ID: collectionId})
+		if err != nil {
+			return fmt.Errorf("collection '%s': %w", collectionId, err)
+		}
+		if collection.OrganizationID != obj.OrganizationID {
+			return fmt.Errorf("collection '%s' belongs to another organization", collectionId)
+		}
+	}
+	return nil
+}
+
+// updateItems stores back the items modified by update, and bumps their
+// revision date.
+func (v *memoryVault) updateItems(ctx context.Context, update func(item *models.Item) bool) {
+	for _, rawObj := range v.objectStore {
+		item, ok := rawObj.(models.Item)
+		if !ok || !update(&item) {
+			continue
+		}
+		item.RevisionDate = revisionDate()
+		v.storeObject(ctx, item)
+	}
+}
+
+func findUnique[T any](objects map[string]T, match func(T) bool) (*T, error) {
+	found := []T{}
+	for _, obj := range sortedValues(objects) {
+		if match(obj) {
+			found = append(found, obj)
+		}
+	}
+
+	if len(found) == 0 {
+		return nil, models.ErrNoObjectFoundMatchingFilter
+	} else if len(found) > 1 {
+		return nil, models.ErrTooManyObjectsFound
+	}
+	return &found[0], nil
+}
+
+func attachmentContentKey(itemId, attachmentId string) string {
+	return strings.Join([]string{itemId, attachmentId}, "/")
+}
+
+func idOrNew(id string) string {
+	if len(id) > 0 {
+		return id
+	}
+	return uuid.New().String()
+}
+
+func revisionDate() *time.Time {
+	t := time.Now().UTC()
+	return &t
+}
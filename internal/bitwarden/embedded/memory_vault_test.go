@@ -0,0 +1,172 @@
+//go:build offline
+
+package embedded
+
+import (
+	"testing"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMemoryFixture = `{
+  "secretsManagerOrganizationId": "sm-org-id",
+  "organizations": [{"id": "org-id", "name": "ACME"}],
+  "collections": [{"id": "collection-id", "organizationId": "org-id", "name": "Infrastructure"}],
+  "groups": [{"id": "group-id", "organizationId": "org-id", "name": "Admins"}],
+  "members": [{"id": "member-id", "organizationId": "org-id", "email": "admin@example.com"}],
+  "folders": [{"id": "folder-id", "name": "Databases"}],
+  "items": [
+    {
+      "id": "item-id",
+      "organizationId": "org-id",
+      "folderId": "folder-id",
+      "collectionIds": ["collection-id"],
+      "type": 1,
+      "name": "Postgres",
+      "login": {"username": "admin", "password": "seeded-password"}
+    }
+  ],
+  "attachments": [{"itemId": "item-id", "fileName": "cert.pem", "content": "certificate"}],
+  "projects": [{"id": "project-id", "name": "Backend"}],
+  "secrets": [{"id": "secret-id", "projectId": "project-id", "key": "DB_PASSWORD", "value": "seeded-secret"}]
+}`
+
+func TestMemoryVaultSeededFromFixture(t *testing.T) {
+	vault, err := NewMemoryVault(t.Context(), writeExportFile(t, testMemoryFixture))
+	require.NoError(t, err)
+
+	item, err := vault.FindItem(t.Context(), bitwarden.WithSearch("Postgres"), bitwarden.WithCollectionID("collection-id"))
+	require.NoError(t, err)
+	assert.Equal(t, "seeded-password", item.Login.Password)
+	assert.NotNil(t, item.RevisionDate)
+	if assert.Len(t, item.Attachments, 1) {
+		content, err := vault.GetAttachment(t.Context(), "item-id", item.Attachments[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "certificate", string(content))
+	}
+
+	group, err := vault.FindOrganizationGroup(t.Context(), bitwarden.WithOrganizationID("org-id"), bitwarden.WithSearch("Admins"))
+	require.NoError(t, err)
+	assert.Equal(t, "group-id", group.ID)
+
+	member, err := vault.GetOrganizationMember(t.Context(), models.OrgMember{ID: "member-id", OrganizationId: "org-id"})
+	require.NoError(t, err)
+	assert.Equal(t, "admin@example.com", member.Email)
+
+	secret, err := vault.GetSecretByKey(t.Context(), "DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "seeded-secret", secret.Value)
+	assert.Equal(t, "sm-org-id", secret.OrganizationID)
+}
+
+func TestMemoryVaultFixtureWithMissingReference(t *testing.T) {
+	_, err := NewMemoryVault(t.Context(), writeExportFile(t, `{"items": [{"id": "item-id", "folderId": "missing", "type": 2}]}`))
+	assert.ErrorIs(t, err, models.ErrObjectNotFound)
+}
+
+func TestMemoryVaultItemLifecycle(t *testing.T) {
+	vault, err := NewMemoryVault(t.Context(), writeExportFile(t, testMemoryFixture))
+	require.NoError(t, err)
+
+	created, err := vault.CreateItem(t.Context(), models.Item{
+		Name:           "Postgres replica",
+		Type:           models.ItemTypeLogin,
+		OrganizationID: "org-id",
+		CollectionIds:  []string{"collection-id"},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, created.CreationDate, created.RevisionDate)
+
+	_, err = vault.FindItem(t.Context(), bitwarden.WithSearch("Postgres"))
+	assert.ErrorIs(t, err, models.ErrTooManyObjectsFound)
+
+	_, err = vault.GetItem(t.Context(), models.Item{ID: created.ID, Type: models.ItemTypeSecureNote})
+	assert.ErrorIs(t, err, models.ErrItemTypeMismatch)
+
+	created.Name = "Postgres standby"
+	edited, err := vault.EditItem(t.Context(), *created)
+	require.NoError(t, err)
+	assert.Equal(t, created.CreationDate, edited.CreationDate)
+	assert.False(t, edited.RevisionDate.Before(*created.RevisionDate))
+
+	_, err = vault.CreateItem(t.Context(), models.Item{Name: "orphan", Type: models.ItemTypeLogin, FolderID: "missing"})
+	assert.ErrorIs(t, err, models.ErrObjectNotFound)
+
+	require.NoError(t, vault.DeleteOrganizationCollection(t.Context(), models.OrgCollection{ID: "collection-id"}))
+	item, err := vault.GetItem(t.Context(), models.Item{ID: created.ID})
+	require.NoError(t, err)
+	assert.Empty(t, item.CollectionIds)
+
+	require.NoError(t, vault.DeleteItem(t.Context(), *created))
+	_, err = vault.GetItem(t.Context(), *created)
+	assert.ErrorIs(t, err, models.ErrObjectNotFound)
+	assert.ErrorIs(t, vault.DeleteItem(t.Context(), *created), models.ErrObjectNotFound)
+}
+
+func TestMemoryVaultAttachments(t *testing.T) {
+	vault, err := NewMemoryVault(t.Context(), "")
+	require.NoError(t, err)
+
+	item, err := vault.CreateItem(t.Context(), models.Item{Name: "note", Type: models.ItemTypeSecureNote})
+	require.NoError(t, err)
+
+	attachment, err := vault.CreateAttachmentFromContent(t.Context(), item.ID, "hello.txt", []byte("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "5", attachment.Size)
+
+	content, err := vault.GetAttachment(t.Context(), item.ID, attachment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+
+	// Editing an item doesn't drop its attachments.
+	_, err = vault.EditItem(t.Context(), *item)
+	require.NoError(t, err)
+	item, err = vault.GetItem(t.Context(), *item)
+	require.NoError(t, err)
+	assert.Len(t, item.Attachments, 1)
+
+	require.NoError(t, vault.DeleteAttachment(t.Context(), item.ID, attachment.ID))
+	_, err = vault.GetAttachment(t.Context(), item.ID, attachment.ID)
+	assert.ErrorIs(t, err, models.ErrAttachmentNotFound)
+	assert.ErrorIs(t, vault.DeleteAttachment(t.Context(), item.ID, attachment.ID), models.ErrAttachmentNotFound)
+
+	_, err = vault.GetAttachment(t.Context(), "missing", attachment.ID)
+	assert.ErrorIs(t, err, models.ErrObjectNotFound)
+}
+
+func TestMemoryVaultSecrets(t *testing.T) {
+	vault, err := NewMemoryVault(t.Context(), "")
+	require.NoError(t, err)
+
+	_, err = vault.CreateSecret(t.Context(), models.Secret{Key: "KEY", ProjectID: "missing"})
+	assert.ErrorIs(t, err, models.ErrObjectNotFound)
+
+	project, err := vault.CreateProject(t.Context(), models.Project{Name: "Backend"})
+	require.NoError(t, err)
+
+	secret, err := vault.CreateSecret(t.Context(), models.Secret{Key: "KEY", Value: "v1", ProjectID: project.ID})
+	require.NoError(t, err)
+	assert.Equal(t, project.OrganizationID, secret.OrganizationID)
+
+	_, err = vault.GetSecretByKey(t.Context(), "OTHER")
+	assert.ErrorIs(t, err, models.ErrObjectNotFound)
+
+	_, err = vault.CreateSecret(t.Context(), models.Secret{Key: "KEY", Value: "v2", ProjectID: project.ID})
+	require.NoError(t, err)
+	_, err = vault.GetSecretByKey(t.Context(), "KEY")
+	assert.ErrorIs(t, err, models.ErrTooManyObjectsFound)
+
+	secret.Value = "v3"
+	edited, err := vault.EditSecret(t.Context(), *secret)
+	require.NoError(t, err)
+	assert.Equal(t, "v3", edited.Value)
+	assert.Equal(t, secret.CreationDate, edited.CreationDate)
+
+	require.NoError(t, vault.DeleteSecret(t.Context(), *secret))
+	_, err = vault.GetSecret(t.Context(), *secret)
+	assert.ErrorIs(t, err, models.ErrObjectNotFound)
+}
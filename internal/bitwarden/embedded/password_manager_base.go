@@ -98,15 +98,17 @@ func getObject[T any](store map[string]interface{}, obj T) (*T, error) {
 		itemType = itemObj.Type
 	}
 
-	storedObj, ok := store[objKey(obj)]
+	key := objKey(obj)
+	objectType, objectID, _ := strings.Cut(key, "___")
+	storedObj, ok := store[key]
 	if !ok {
-		return nil, models.ErrObjectNotFound
+		return nil, models.WithObject(models.ErrObjectNotFound, models.ObjectType(objectType), objectID)
 	}
 
 	switch itemObj := any(storedObj).(type) {
 	case models.Item:
 		if itemObj.DeletedDate != nil {
-			return nil, models.ErrObjectNotFound
+			return nil, models.WithObject(models.ErrObjectNotFound, models.ObjectType(objectType), objectID)
 		}
 		if itemType > 0 && itemObj.Type != itemType {
 			return nil, models.ErrItemTypeMismatch
@@ -222,7 +224,9 @@ func decryptAccountSecrets(account Account, password string) (*AccountSecrets, e
 		return nil, fmt.Errorf("error building prelogin key: %w", err)
 	}
 
-	generatedKeys, err := decryptStringAsKey(account.ProtectedSymmetricKey, masterKey.StretchKey())
+	// Legacy accounts protect their key with the master key itself, while
+	// recent ones use a stretched master key.
+	generatedKeys, err := crypto.DecryptEncryptionKey(account.ProtectedSymmetricKey, *masterKey)
 	if err != nil {
 		return nil, models.ErrWrongMasterPassword
 	}
@@ -239,6 +243,21 @@ func decryptAccountSecrets(account Account, password string) (*AccountSecrets, e
 	}, nil
 }
 
+// decryptAccountSecretsWithUserKey unlocks an account with an already derived
+// user key, skipping the KDF. The resulting secrets have no master password
+// hash.
+func decryptAccountSecretsWithUserKey(account Account, userKey symmetrickey.Key) (*AccountSecrets, error) {
+	rsaPrivateKey, err := crypto.DecryptPrivateKey(account.ProtectedRSAPrivateKey, userKey)
+	if err != nil {
+		return nil, models.ErrWrongUserKey
+	}
+	return &AccountSecrets{
+		OrganizationSecrets: map[string]OrganizationSecret{},
+		MainKey:             userKey,
+		RSAPrivateKey:       rsaPrivateKey,
+	}, nil
+}
+
 func decryptOrgCollection(obj webapi.Collection, secret AccountSecrets) (*models.OrgCollection, error) {
 	orgKey, err := secret.GetOrganizationKey(obj.OrganizationId)
 	if err != nil {
@@ -680,7 +699,7 @@ func encryptItem(ctx context.Context, obj models.Item, secret AccountSecrets, ve
 			return nil, fmt.Errorf("error decrypting item for verification: %w", err)
 		}
 
-		if objectKeyEncryption {
+		if objectKeyEncryption && !hasCipherKey {
 			actualObj.Key = ""
 		}
 
@@ -808,6 +827,56 @@ func getObjectKey(obj models.Item, secret AccountSecrets) (*symmetrickey.Key, er
 	return decryptedObjectKey, nil
 }
 
+// withNewItemKey returns a copy of a decrypted item using a newly generated
+// object key. Attachment keys are wrapped by the object key, so they're
+// re-encrypted with the new one.
+func withNewItemKey(obj models.Item, secret AccountSecrets) (*models.Item, error) {
+	mainKey, err := getMainKeyForObject(obj, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	previousKey := mainKey
+	if len(obj.Key) > 0 {
+		previousKey, err = symmetrickey.NewFromRawBytesWithEncryptionType([]byte(obj.Key), symmetrickey.AesCbc256_HmacSha256_B64)
+		if err != nil {
+			return nil, fmt.Errorf("error reading current object key: %w", err)
+		}
+	}
+
+	newKey, err := keybuilder.CreateObjectKey()
+	if err != nil {
+		return nil, fmt.Errorf("error creating object key: %w", err)
+	}
+
+	attachments := make([]models.Attachment, len(obj.Attachments))
+	for k, f := range obj.Attachments {
+		// Attachments without a key of their own are encrypted with the object
+		// key directly, and would need to be uploaded again.
+		if len(f.Key) == 0 {
+			return nil, fmt.Errorf("attachment '%s' has no key of its own and can't be moved to a new object key", f.ID)
+		}
+
+		attachmentKey, err := decryptStringAsKey(f.Key, *previousKey)
+		if err != nil {
+			return nil, fmt.Errorf("error decrypting attachment key: %w", err)
+		}
+
+		encAttachmentKey, err := crypto.EncryptAsString(attachmentKey.Key, *newKey)
+		if err != nil {
+			return nil, fmt.Errorf("error encrypting attachment key: %w", err)
+		}
+
+		attachments[k] = f
+		attachments[k].Key = encAttachmentKey
+	}
+
+	rotatedObj := obj
+	rotatedObj.Attachments = attachments
+	rotatedObj.Key = string(newKey.Key)
+	return &rotatedObj, nil
+}
+
 func objKey(obj any) string {
 	switch itemObj := any(obj).(type) {
 	case models.Item:
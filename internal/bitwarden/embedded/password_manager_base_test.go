@@ -11,6 +11,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/keybuilder"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/symmetrickey"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
 	"github.com/stretchr/testify/assert"
@@ -416,6 +418,76 @@ func TestEncryptItem(t *testing.T) {
 	assert.NotContains(t, string(newOut), "sensitive")
 }
 
+func TestWithNewItemKey(t *testing.T) {
+	accountSecrets := computeTestAccountSecrets(t)
+
+	attachmentKey, err := keybuilder.CreateObjectKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encAttachmentKey, err := crypto.EncryptAsString(attachmentKey.Key, accountSecrets.MainKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Start from an item without a key of its own, whose attachment key is
+	// wrapped by the account's key.
+	objectToRotate := testFullyFilledItem()
+	objectToRotate.OrganizationID = ""
+	objectToRotate.Attachments[0].Key = encAttachmentKey
+
+	rotatedObj, err := withNewItemKey(objectToRotate, *accountSecrets)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Empty(t, objectToRotate.Key)
+	assert.NotEmpty(t, rotatedObj.Key)
+
+	encObj, err := encryptItem(t.Context(), *rotatedObj, *accountSecrets, true, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	newKey, err := getObjectKey(*encObj, *accountSecrets)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []byte(rotatedObj.Key), newKey.Key)
+	assertEncryptedValueOf(t, "sensitive-password", encObj.Login.Password, *newKey)
+	assertEncryptedValueOf(t, "sensitive-filename", encObj.Attachments[0].FileName, *newKey)
+	assertEncryptedValueOf(t, string(attachmentKey.Key), encObj.Attachments[0].Key, *newKey)
+
+	decObj, err := decryptItem(*encObj, *accountSecrets)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, objectToRotate.PasswordHistory, decObj.PasswordHistory)
+
+	// Rotating again replaces the item's own key.
+	rerotatedObj, err := withNewItemKey(*decObj, *accountSecrets)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEqual(t, decObj.Key, rerotatedObj.Key)
+
+	rerotatedKey, err := symmetrickey.NewFromRawBytes([]byte(rerotatedObj.Key))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assertEncryptedValueOf(t, string(attachmentKey.Key), rerotatedObj.Attachments[0].Key, *rerotatedKey)
+}
+
+func TestWithNewItemKeyRequiresAttachmentKeys(t *testing.T) {
+	accountSecrets := computeTestAccountSecrets(t)
+
+	objectToRotate := testFullyFilledItem()
+	objectToRotate.OrganizationID = ""
+	objectToRotate.Attachments[0].Key = ""
+
+	_, err := withNewItemKey(objectToRotate, *accountSecrets)
+	assert.ErrorContains(t, err, "attachment 'public-id' has no key of its own")
+}
+
 func assertEncryptedValueOf(t *testing.T, expected, value string, k symmetrickey.Key) {
 	out, err := decryptStringAsBytes(value, k)
 	assert.Nil(t, err)
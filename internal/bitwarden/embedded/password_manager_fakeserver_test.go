@@ -0,0 +1,242 @@
+//go:build offline
+
+package embedded
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/fakeserver"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/webapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fakeServerKdfConfig = models.KdfConfiguration{
+	KdfType:       models.KdfTypePBKDF2_SHA256,
+	KdfIterations: 1000,
+}
+
+func newFakeServer(t *testing.T, opts ...fakeserver.Option) (*fakeserver.Server, string) {
+	t.Helper()
+
+	server := fakeserver.New(append([]fakeserver.Option{fakeserver.WithKdfConfig(fakeServerKdfConfig)}, opts...)...)
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return server, httpServer.URL
+}
+
+func newFakeServerPasswordManager(serverURL string) PasswordManagerClient {
+	return NewPasswordManagerClient(serverURL, testDeviceIdentifer, testDeviceVersion, WithPasswordManagerHttpOptions(webapi.DisableRetries()))
+}
+
+func TestFakeServerPasswordManagerLifecycle(t *testing.T) {
+	_, serverURL := newFakeServer(t)
+	ctx := t.Context()
+
+	vault := newFakeServerPasswordManager(serverURL)
+	require.NoError(t, vault.RegisterUser(ctx, "Alice", "alice@example.com", TestPassword, fakeServerKdfConfig))
+	require.NoError(t, vault.LoginWithPassword(ctx, "alice@example.com", TestPassword))
+
+	orgID, err := vault.CreateOrganization(ctx, "ACME", "Infrastructure", "alice@example.com")
+	require.NoError(t, err)
+
+	folder, err := vault.CreateFolder(ctx, models.Folder{Name: "Databases", Object: models.ObjectTypeFolder})
+	require.NoError(t, err)
+
+	collection, err := vault.CreateOrganizationCollection(ctx, models.OrgCollection{Name: "Production", OrganizationID: orgID, Object: models.ObjectTypeOrgCollection, Users: []models.OrgCollectionMember{}, Groups: []models.OrgCollectionMember{}})
+	require.NoError(t, err)
+
+	item, err := vault.CreateItem(ctx, models.Item{
+		Name:           "Postgres",
+		Type:           models.ItemTypeLogin,
+		Object:         models.ObjectTypeItem,
+		FolderID:       folder.ID,
+		OrganizationID: orgID,
+		CollectionIds:  []string{collection.ID},
+		Login:          models.Login{Username: "admin", Password: "secret"},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, item.Key, "items should get a cipher key of their own")
+
+	item.Login.Password = "rotated"
+	item, err = vault.EditItem(ctx, *item)
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", item.Login.Password)
+
+	attachment, err := vault.CreateAttachmentFromContent(ctx, item.ID, "cert.pem", []byte("certificate"))
+	require.NoError(t, err)
+
+	content, err := vault.GetAttachment(ctx, item.ID, attachment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "certificate", string(content))
+
+	// Rotating the key of the item is applied along with the edition, and
+	// keeps its attachments readable.
+	previousKey := item.Key
+	item.Login.Password = "rotated-again"
+	item, err = vault.RotateItemKey(ctx, *item)
+	require.NoError(t, err)
+	assert.NotEqual(t, previousKey, item.Key)
+	assert.Equal(t, "rotated-again", item.Login.Password)
+
+	content, err = vault.GetAttachment(ctx, item.ID, attachment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "certificate", string(content))
+
+	require.NoError(t, vault.DeleteAttachment(ctx, item.ID, attachment.ID))
+	_, err = vault.GetAttachment(ctx, item.ID, attachment.ID)
+	assert.ErrorIs(t, err, models.ErrAttachmentNotFound)
+
+	// A fresh client only sees what the server persisted.
+	otherVault := newFakeServerPasswordManager(serverURL)
+	require.NoError(t, otherVault.LoginWithPassword(ctx, "alice@example.com", TestPassword))
+	found, err := otherVault.FindItem(ctx, bitwarden.WithSearch("Postgres"), bitwarden.WithCollectionID(collection.ID))
+	require.NoError(t, err)
+	assert.Equal(t, "rotated-again", found.Login.Password)
+	assert.Equal(t, folder.ID, found.FolderID)
+
+	require.NoError(t, vault.DeleteItem(ctx, *item))
+	_, err = vault.GetItem(ctx, *item)
+	assert.ErrorIs(t, err, models.ErrObjectNotFound)
+}
+
+func TestFakeServerWrongPassword(t *testing.T) {
+	server, serverURL := newFakeServer(t)
+	_, err := server.RegisterAccount("alice@example.com", TestPassword)
+	require.NoError(t, err)
+
+	vault := newFakeServerPasswordManager(serverURL)
+	err = vault.LoginWithPassword(t.Context(), "alice@example.com", "not-the-password")
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+func TestFakeServerOrganizationMembership(t *testing.T) {
+	server, serverURL := newFakeServer(t)
+	ctx := t.Context()
+
+	owner, err := server.RegisterAccount("owner@example.com", TestPassword)
+	require.NoError(t, err)
+	member, err := server.RegisterAccount("member@example.com", TestPassword)
+	require.NoError(t, err)
+	orgID, err := server.CreateOrganization(owner.Email, "ACME")
+	require.NoError(t, err)
+
+	ownerVault := newFakeServerPasswordManager(serverURL)
+	require.NoError(t, ownerVault.LoginWithAPIKey(ctx, owner.Password, owner.ClientID, owner.ClientSecret))
+	require.NoError(t, ownerVault.InviteUser(ctx, orgID, member.Email, models.OrgMemberRoleTypeUser))
+	_, err = ownerVault.ConfirmInvite(ctx, orgID, member.Email)
+	require.NoError(t, err)
+
+	collection, err := ownerVault.FindOrganizationCollection(ctx, bitwarden.WithOrganizationID(orgID), bitwarden.WithSearch("Default collection"))
+	require.NoError(t, err)
+
+	item, err := ownerVault.CreateItem(ctx, models.Item{
+		Name:           "Shared",
+		Type:           models.ItemTypeSecureNote,
+		Object:         models.ObjectTypeItem,
+		OrganizationID: orgID,
+		CollectionIds:  []string{collection.ID},
+		Notes:          "shared with the member",
+	})
+	require.NoError(t, err)
+
+	// The member unwraps the organization key with their own private key.
+	memberVault := newFakeServerPasswordManager(serverURL)
+	require.NoError(t, memberVault.LoginWithPassword(ctx, member.Email, member.Password))
+	shared, err := memberVault.GetItem(ctx, *item)
+	require.NoError(t, err)
+	assert.Equal(t, "shared with the member", shared.Notes)
+
+	_, err = memberVault.CreateOrganizationGroup(ctx, models.OrgGroup{Name: "Admins", OrganizationID: orgID})
+	assert.ErrorContains(t, err, "403")
+}
+
+func TestFakeServerSecretsManager(t *testing.T) {
+	server, serverURL := newFakeServer(t)
+	ctx := t.Context()
+
+	owner, err := server.RegisterAccount("owner@example.com", TestPassword)
+	require.NoError(t, err)
+	orgID, err := server.CreateOrganization(owner.Email, "ACME")
+	require.NoError(t, err)
+	accessToken, err := server.CreateMachineAccount(orgID)
+	require.NoError(t, err)
+
+	secretsManager := NewSecretsManagerClient(serverURL, testDeviceIdentifer, testDeviceVersion, WithSecretsManagerHttpOptions(webapi.DisableRetries()))
+	require.NoError(t, secretsManager.LoginWithAccessToken(ctx, accessToken))
+
+	project, err := secretsManager.CreateProject(ctx, models.Project{Name: "Backend"})
+	require.NoError(t, err)
+	assert.Equal(t, orgID, project.OrganizationID)
+
+	secret, err := secretsManager.CreateSecret(ctx, models.Secret{Key: "DB_PASSWORD", Value: "v1", ProjectID: project.ID})
+	require.NoError(t, err)
+
+	secret.Value = "v2"
+	_, err = secretsManager.EditSecret(ctx, *secret)
+	require.NoError(t, err)
+
+	found, err := secretsManager.GetSecretByKey(ctx, "DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", found.Value)
+	assert.Equal(t, project.ID, found.ProjectID)
+
+	_, err = secretsManager.CreateSecret(ctx, models.Secret{Key: "OTHER", ProjectID: "missing"})
+	assert.ErrorIs(t, err, models.ErrObjectNotFound)
+
+	require.NoError(t, secretsManager.DeleteSecret(ctx, *secret))
+	_, err = secretsManager.GetSecret(ctx, *secret)
+	assert.ErrorIs(t, err, models.ErrObjectNotFound)
+}
+
+func TestFakeServerOutdatedItemEditionLeavesCollectionsUnchanged(t *testing.T) {
+	_, serverURL := newFakeServer(t)
+	ctx := t.Context()
+
+	vault := newFakeServerPasswordManager(serverURL)
+	require.NoError(t, vault.RegisterUser(ctx, "Alice", "alice@example.com", TestPassword, fakeServerKdfConfig))
+	require.NoError(t, vault.LoginWithPassword(ctx, "alice@example.com", TestPassword))
+
+	orgID, err := vault.CreateOrganization(ctx, "ACME", "Infrastructure", "alice@example.com")
+	require.NoError(t, err)
+	production, err := vault.CreateOrganizationCollection(ctx, models.OrgCollection{Name: "Production", OrganizationID: orgID, Object: models.ObjectTypeOrgCollection, Users: []models.OrgCollectionMember{}, Groups: []models.OrgCollectionMember{}})
+	require.NoError(t, err)
+	staging, err := vault.CreateOrganizationCollection(ctx, models.OrgCollection{Name: "Staging", OrganizationID: orgID, Object: models.ObjectTypeOrgCollection, Users: []models.OrgCollectionMember{}, Groups: []models.OrgCollectionMember{}})
+	require.NoError(t, err)
+
+	item, err := vault.CreateItem(ctx, models.Item{
+		Name:           "Postgres",
+		Type:           models.ItemTypeLogin,
+		Object:         models.ObjectTypeItem,
+		OrganizationID: orgID,
+		CollectionIds:  []string{production.ID},
+	})
+	require.NoError(t, err)
+
+	// The edition is based on a revision older than the one of the server.
+	outdated := *item
+	outdatedRevisionDate := item.RevisionDate.Add(-time.Hour)
+	outdated.RevisionDate = &outdatedRevisionDate
+	outdated.CollectionIds = []string{staging.ID}
+	_, err = vault.EditItem(ctx, outdated)
+	assert.ErrorIs(t, err, models.ErrorKindConflict)
+
+	otherVault := newFakeServerPasswordManager(serverURL)
+	require.NoError(t, otherVault.LoginWithPassword(ctx, "alice@example.com", TestPassword))
+	found, err := otherVault.GetItem(ctx, *item)
+	require.NoError(t, err)
+	assert.Equal(t, []string{production.ID}, found.CollectionIds)
+
+	// Up to date editions change both.
+	edited := *item
+	edited.CollectionIds = []string{staging.ID}
+	edited.Login.Username = "admin"
+	editedObj, err := vault.EditItem(ctx, edited)
+	require.NoError(t, err)
+	assert.Equal(t, []string{staging.ID}, editedObj.CollectionIds)
+	assert.Equal(t, "admin", editedObj.Login.Username)
+}
@@ -20,6 +20,8 @@ import (
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/keybuilder"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/webapi"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type PasswordManagerClient interface {
@@ -51,10 +53,13 @@ type PasswordManagerClient interface {
 	GetOrganizationMember(context.Context, models.OrgMember) (*models.OrgMember, error)
 	InviteUser(ctx context.Context, orgId, userEmail string, memberRoleType models.OrgMemberRoleType) error
 	IsSyncAfterWriteVerificationDisabled() bool
+	LoadCLIVaultWithSessionKey(ctx context.Context, vaultPath, sessionKey string) error
 	LoginWithAPIKey(ctx context.Context, password, clientId, clientSecret string) error
+	LoginWithAPIKeyAndUserKey(ctx context.Context, userKey, clientId, clientSecret string) error
 	LoginWithPassword(ctx context.Context, username, password string) error
 	Logout(ctx context.Context) error
 	RegisterUser(ctx context.Context, name, username, password string, kdfConfig models.KdfConfiguration) error
+	RotateItemKey(ctx context.Context, obj models.Item) (*models.Item, error)
 	Sync(ctx context.Context) error
 	Unlock(ctx context.Context, password string) error
 }
@@ -101,6 +106,14 @@ func WithPasswordManagerHttpOptions(opts ...webapi.Options) PasswordManagerOptio
 	}
 }
 
+// EnableItemKeyMigration gives a key of their own to the items created or edited
+// by this client, even if the server doesn't enable cipher key encryption yet.
+func EnableItemKeyMigration() PasswordManagerOptions {
+	return func(c bitwarden.PasswordManager) {
+		c.(*webAPIVault).migrateToItemKeys = true
+	}
+}
+
 // Panic on error is useful for debugging, but should not be used in production.
 func EnablePanicOnEncryptionError() PasswordManagerOptions {
 	return func(c bitwarden.PasswordManager) {
@@ -137,6 +150,12 @@ func NewDeviceIdentifier() string {
 	return uuid.New().String()
 }
 
+// DeviceIdentifierFor returns a device identifier derived from seed, which is
+// the same every time for the same seed.
+func DeviceIdentifierFor(seed string) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(seed)).String()
+}
+
 type webAPIVault struct {
 	baseVault
 	client       webapi.Client
@@ -147,6 +166,7 @@ type webAPIVault struct {
 
 	syncAfterWrite                   bool
 	failOnSyncAfterWriteVerification bool
+	migrateToItemKeys                bool
 	serverURL                        string
 }
 
@@ -330,7 +350,7 @@ func (v *webAPIVault) CreateItem(ctx context.Context, obj models.Item) (*models.
 	}
 
 	var resObj *models.Item
-	encObj, err := encryptItem(ctx, obj, v.loginAccount.Secrets, v.verifyObjectEncryption, v.serverConfig.disableCipherKeyEncryption)
+	encObj, err := encryptItem(ctx, obj, v.loginAccount.Secrets, v.verifyObjectEncryption, v.serverConfig.disableCipherKeyEncryption || v.migrateToItemKeys)
 	if err != nil {
 		return nil, fmt.Errorf("error encrypting item for creation: %w", err)
 	}
@@ -784,22 +804,50 @@ func (v *webAPIVault) EditItem(ctx context.Context, obj models.Item) (*models.It
 		return nil, models.ErrVaultLocked
 	}
 
-	// Special handling for collections identifiers changes, since you need to
-	// call a different endpoint to update them.
+	return v.editItem(ctx, obj, false)
+}
+
+// RotateItemKey edits an item and re-encrypts it with a newly generated key of
+// its own. Its content, password history and the keys of its attachments are
+// all re-encrypted in a single edition. Items that didn't have a key of their
+// own are migrated to one.
+func (v *webAPIVault) RotateItemKey(ctx context.Context, obj models.Item) (*models.Item, error) {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	if !v.objectsLoaded() {
+		return nil, models.ErrVaultLocked
+	}
+
+	return v.editItem(ctx, obj, true)
+}
+
+func (v *webAPIVault) editItem(ctx context.Context, obj models.Item, rotateItemKey bool) (*models.Item, error) {
 	currentObj, err := getObject(v.objectStore, obj)
 	if err != nil {
 		return nil, fmt.Errorf("error getting item prior to edition: %w", err)
 	}
 
-	if !slices.Equal(currentObj.CollectionIds, obj.CollectionIds) {
-		_, err = v.client.EditItemCollections(ctx, obj.ID, obj.CollectionIds)
+	// Items keep their own key across editions, unless it's rotated. Items
+	// without one get a new key when the server or the provider ask for it.
+	objectKeyEncryption := v.serverConfig.disableCipherKeyEncryption || v.migrateToItemKeys
+	if len(obj.Key) == 0 {
+		obj.Key = currentObj.Key
+	}
+	if rotateItemKey || (objectKeyEncryption && len(obj.Key) == 0) {
+		// The keys of the attachments are only known to the vault, and are
+		// re-encrypted with the new key before anything is written.
+		keyedObj := obj
+		keyedObj.Attachments = currentObj.Attachments
+		rotatedObj, err := withNewItemKey(keyedObj, v.loginAccount.Secrets)
 		if err != nil {
-			return nil, fmt.Errorf("error editing item collections: %w", err)
+			return nil, fmt.Errorf("error rotating item key: %w", err)
 		}
+		obj = *rotatedObj
 	}
 
 	var resObj *models.Item
-	encObj, err := encryptItem(ctx, obj, v.loginAccount.Secrets, v.verifyObjectEncryption, v.serverConfig.disableCipherKeyEncryption)
+	encObj, err := encryptItem(ctx, obj, v.loginAccount.Secrets, v.verifyObjectEncryption, objectKeyEncryption)
 	if err != nil {
 		return nil, fmt.Errorf("error encrypting item for edition: %w", err)
 	}
@@ -814,6 +862,22 @@ func (v *webAPIVault) EditItem(ctx context.Context, obj models.Item) (*models.It
 		return nil, fmt.Errorf("error decrypting item after edition: %w", err)
 	}
 
+	// Special handling for collections identifiers changes, since you need to
+	// call a different endpoint to update them. They're changed after the item
+	// itself, for the server to first reject editions of items modified in
+	// the meantime.
+	if !slices.Equal(currentObj.CollectionIds, obj.CollectionIds) {
+		collectionsObj, err := v.client.EditItemCollections(ctx, obj.ID, obj.CollectionIds)
+		if err != nil {
+			return nil, fmt.Errorf("error editing item collections: %w", err)
+		}
+
+		resObj.CollectionIds = obj.CollectionIds
+		if collectionsObj != nil && collectionsObj.RevisionDate != nil {
+			resObj.RevisionDate = collectionsObj.RevisionDate
+		}
+	}
+
 	v.storeObject(ctx, *resObj)
 
 	if v.syncAfterWrite {
@@ -1018,7 +1082,30 @@ func (v *webAPIVault) LoginWithAPIKey(ctx context.Context, password, clientId, c
 		return fmt.Errorf("error login with api key: %w", err)
 	}
 
-	return v.continueLoginWithTokens(ctx, *tokenResp, password)
+	return v.continueLoginWithTokens(ctx, *tokenResp, withMasterPassword(password))
+}
+
+// LoginWithAPIKeyAndUserKey logs in with a personal API key and unlocks the
+// vault with a base64-encoded user key instead of the master password.
+func (v *webAPIVault) LoginWithAPIKeyAndUserKey(ctx context.Context, userKey, clientId, clientSecret string) error {
+	v.vaultOperationMutex.Lock()
+	defer v.vaultOperationMutex.Unlock()
+
+	if v.loginAccount.LoggedIn() {
+		return models.ErrAlreadyLoggedIn
+	}
+
+	key, err := parseUserKey(userKey)
+	if err != nil {
+		return err
+	}
+
+	tokenResp, err := v.client.LoginWithAPIKey(ctx, clientId, clientSecret)
+	if err != nil {
+		return fmt.Errorf("error login with api key: %w", err)
+	}
+
+	return v.continueLoginWithTokens(ctx, *tokenResp, withUserKey(*key))
 }
 
 func (v *webAPIVault) LoginWithPassword(ctx context.Context, username, password string) error {
@@ -1046,7 +1133,7 @@ func (v *webAPIVault) LoginWithPassword(ctx context.Context, username, password
 		return fmt.Errorf("error login with username/password: %w", err)
 	}
 
-	return v.continueLoginWithTokens(ctx, *tokenResp, password)
+	return v.continueLoginWithTokens(ctx, *tokenResp, withMasterPassword(password))
 }
 
 func (v *webAPIVault) Logout(ctx context.Context) error {
@@ -1099,7 +1186,10 @@ func (v *webAPIVault) Sync(ctx context.Context) error {
 	return v.sync(ctx)
 }
 
-func (v *webAPIVault) sync(ctx context.Context) error {
+func (v *webAPIVault) sync(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "sync")
+	defer func() { tracing.End(span, err) }()
+
 	if !v.loginAccount.LoggedIn() {
 		return models.ErrLoggedOut
 	} else if !v.loginAccount.SecretsLoaded() {
@@ -1127,10 +1217,10 @@ func (v *webAPIVault) Unlock(ctx context.Context, password string) error {
 	v.vaultOperationMutex.Lock()
 	defer v.vaultOperationMutex.Unlock()
 
-	return v.unlock(ctx, password)
+	return v.unlock(ctx, withMasterPassword(password))
 }
 
-func (v *webAPIVault) unlock(ctx context.Context, password string) error {
+func (v *webAPIVault) unlock(ctx context.Context, decryptSecrets accountSecretsDecrypter) error {
 	if !v.loginAccount.LoggedIn() {
 		return models.ErrLoggedOut
 	}
@@ -1143,7 +1233,9 @@ func (v *webAPIVault) unlock(ctx context.Context, password string) error {
 	v.loginAccount.Email = profile.Email
 	v.loginAccount.AccountUUID = profile.Id
 
-	accountSecrets, err := decryptAccountSecrets(v.loginAccount, password)
+	_, span := tracing.Start(ctx, "decrypt account secrets")
+	accountSecrets, err := decryptSecrets(v.loginAccount)
+	tracing.End(span, err)
 	if err != nil {
 		return fmt.Errorf("error decrypting account secrets: %w", err)
 	}
@@ -1152,7 +1244,7 @@ func (v *webAPIVault) unlock(ctx context.Context, password string) error {
 	return nil
 }
 
-func (v *webAPIVault) continueLoginWithTokens(ctx context.Context, tokenResp webapi.TokenResponse, password string) error {
+func (v *webAPIVault) continueLoginWithTokens(ctx context.Context, tokenResp webapi.TokenResponse, decryptSecrets accountSecretsDecrypter) error {
 	v.loginAccount = Account{
 		VaultFormat: "API",
 		KdfConfig: models.KdfConfiguration{
@@ -1165,7 +1257,7 @@ func (v *webAPIVault) continueLoginWithTokens(ctx context.Context, tokenResp web
 		ProtectedSymmetricKey:  tokenResp.Key,
 	}
 
-	err := v.unlock(ctx, password)
+	err := v.unlock(ctx, decryptSecrets)
 	if err != nil {
 		return fmt.Errorf("error unlocking after login: %w", err)
 	}
@@ -1278,7 +1370,14 @@ func (v *webAPIVault) prepareAttachmentCreationRequest(_ context.Context, itemId
 	return &req, encDataBuffer, nil
 }
 
-func (v *webAPIVault) loadObjectMap(ctx context.Context, cipherMap webapi.SyncResponse) error {
+func (v *webAPIVault) loadObjectMap(ctx context.Context, cipherMap webapi.SyncResponse) (err error) {
+	ctx, span := tracing.Start(ctx, "decrypt vault",
+		attribute.Int("bitwarden.vault.items", len(cipherMap.Ciphers)),
+		attribute.Int("bitwarden.vault.folders", len(cipherMap.Folders)),
+		attribute.Int("bitwarden.vault.collections", len(cipherMap.Collections)),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	v.clearObjectStore(ctx)
 
 	v.storeOrganizationSecrets(ctx)
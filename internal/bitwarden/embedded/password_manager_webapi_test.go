@@ -3,6 +3,7 @@
 package embedded
 
 import (
+	"encoding/base64"
 	"testing"
 
 	"github.com/jarcoal/httpmock"
@@ -47,6 +48,36 @@ func TestLoginAsAPILoadsAccountInformationForPbkdf2(t *testing.T) {
 	assert.Equal(t, AccountPbkdf2.ProtectedSymmetricKey, vault.loginAccount.ProtectedSymmetricKey)
 }
 
+func TestLoginAsAPIWithUserKey(t *testing.T) {
+	accountSecrets, err := decryptAccountSecrets(AccountPbkdf2, TestPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userKey := base64.StdEncoding.EncodeToString(accountSecrets.MainKey.Key)
+
+	vault, reset := newMockedPasswordManager(MockedClient(t, Pdkdf2Mocks))
+	defer reset()
+
+	ctx := t.Context()
+	err = vault.LoginWithAPIKeyAndUserKey(ctx, userKey, "user.aaf15bd1-4f51-4ba0-ade8-9dc2ec0fd2c3", "ZTXHHyPY6bNlNq1diDA2nM1GROboP3")
+	if err != nil {
+		t.Fatalf("vault unlock failed: %v", err)
+	}
+
+	assert.Equal(t, AccountPbkdf2.Email, vault.loginAccount.Email)
+	assert.True(t, vault.loginAccount.SecretsLoaded())
+	assert.Empty(t, vault.loginAccount.Secrets.MasterPasswordHash)
+}
+
+func TestLoginAsAPIWithWrongUserKey(t *testing.T) {
+	vault, reset := newMockedPasswordManager(MockedClient(t, Pdkdf2Mocks))
+	defer reset()
+
+	wrongKey := base64.StdEncoding.EncodeToString(make([]byte, 64))
+	err := vault.LoginWithAPIKeyAndUserKey(t.Context(), wrongKey, "user.aaf15bd1-4f51-4ba0-ade8-9dc2ec0fd2c3", "ZTXHHyPY6bNlNq1diDA2nM1GROboP3")
+	assert.ErrorIs(t, err, models.ErrWrongUserKey)
+}
+
 func TestLoginAsPasswordLoadsAccountInformationForArgon2(t *testing.T) {
 	vault, reset := newMockedPasswordManager(MockedClient(t, Argon2Mocks))
 	defer reset()
@@ -198,7 +198,7 @@ func (v *secretsManager) GetProject(ctx context.Context, project models.Project)
 	rawProject, err := v.client.GetProject(ctx, project.ID)
 	if err != nil {
 		if httpErr, ok := webapi.IsHTTPError(err); ok && httpErr.GetStatusCode() == 404 {
-			return nil, models.ErrObjectNotFound
+			return nil, models.WithObject(models.ErrObjectNotFound, models.ObjectProject, project.ID)
 		}
 		return nil, fmt.Errorf("error getting project '%s': %w", project.ID, err)
 	}
@@ -219,7 +219,7 @@ func (v *secretsManager) GetSecret(ctx context.Context, secret models.Secret) (*
 	rawSecret, err := v.client.GetSecret(ctx, secret.ID)
 	if err != nil {
 		if httpErr, ok := webapi.IsHTTPError(err); ok && httpErr.GetStatusCode() == 404 {
-			return nil, models.ErrObjectNotFound
+			return nil, models.WithObject(models.ErrObjectNotFound, models.ObjectSecret, secret.ID)
 		}
 		return nil, fmt.Errorf("error getting secret '%s': %w", secret.ID, err)
 	}
@@ -0,0 +1,442 @@
+package fakeserver
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/keybuilder"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/webapi"
+)
+
+const (
+	apiKeyClientIDPrefix = "user."
+	tokenIssuer          = "fakeserver"
+)
+
+type account struct {
+	id                  string
+	email               string
+	name                string
+	masterPasswordHash  string
+	kdfConfig           models.KdfConfiguration
+	key                 string
+	publicKey           string
+	encryptedPrivateKey string
+	apiKey              string
+	revisionDate        *time.Time
+}
+
+// Account holds the credentials of an account created with RegisterAccount.
+type Account struct {
+	ID           string
+	Email        string
+	Password     string
+	ClientID     string
+	ClientSecret string
+}
+
+// tokenResponse mirrors the casing of the official identity server, which
+// differs from field to field.
+type tokenResponse struct {
+	AccessToken         string         `json:"access_token"`
+	ExpiresIn           int            `json:"expires_in"`
+	TokenType           string         `json:"token_type"`
+	Scope               string         `json:"scope"`
+	Key                 string         `json:"Key,omitempty"`
+	PrivateKey          string         `json:"PrivateKey,omitempty"`
+	Kdf                 models.KdfType `json:"Kdf"`
+	KdfIterations       int            `json:"KdfIterations"`
+	KdfMemory           int            `json:"KdfMemory,omitempty"`
+	KdfParallelism      int            `json:"KdfParallelism,omitempty"`
+	ResetMasterPassword bool           `json:"ResetMasterPassword"`
+	ForcePasswordReset  bool           `json:"ForcePasswordReset"`
+	EncryptedPayload    string         `json:"encrypted_payload,omitempty"`
+}
+
+// RegisterAccount creates an account the way a client would: the keys are
+// derived from the password with the server's KDF settings, and only their
+// protected forms are stored.
+func (s *Server) RegisterAccount(email, password string) (*Account, error) {
+	preloginKey, err := keybuilder.BuildPreloginKey(password, email, s.kdfConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error building prelogin key: %w", err)
+	}
+
+	encryptionKey, encryptedEncryptionKey, err := keybuilder.GenerateEncryptionKey(*preloginKey)
+	if err != nil {
+		return nil, fmt.Errorf("error generating encryption key: %w", err)
+	}
+
+	publicKey, encryptedPrivateKey, err := keybuilder.GenerateEncryptedRSAKeyPair(*encryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("error generating key pair: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc, err := s.createAccount(webapi.SignupRequest{
+		Email:              email,
+		Name:               email,
+		MasterPasswordHash: crypto.HashPassword(password, *preloginKey, false),
+		Key:                encryptedEncryptionKey,
+		Kdf:                s.kdfConfig.KdfType,
+		KdfIterations:      s.kdfConfig.KdfIterations,
+		KdfMemory:          s.kdfConfig.KdfMemory,
+		KdfParallelism:     s.kdfConfig.KdfParallelism,
+		Keys: webapi.KeyPair{
+			PublicKey:           publicKey,
+			EncryptedPrivateKey: encryptedPrivateKey,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Account{
+		ID:           acc.id,
+		Email:        acc.email,
+		Password:     password,
+		ClientID:     apiKeyClientIDPrefix + acc.id,
+		ClientSecret: acc.apiKey,
+	}, nil
+}
+
+func (s *Server) createAccount(req webapi.SignupRequest) (*account, error) {
+	if len(req.Email) == 0 || len(req.MasterPasswordHash) == 0 || len(req.Key) == 0 {
+		return nil, fmt.Errorf("email, master password hash and key are required")
+	}
+	if s.findAccountByEmail(req.Email) != nil {
+		return nil, fmt.Errorf("email '%s' is already taken", req.Email)
+	}
+
+	acc := &account{
+		id:                 uuid.New().String(),
+		email:              strings.ToLower(req.Email),
+		name:               req.Name,
+		masterPasswordHash: req.MasterPasswordHash,
+		kdfConfig: models.KdfConfiguration{
+			KdfType:        req.Kdf,
+			KdfIterations:  req.KdfIterations,
+			KdfMemory:      req.KdfMemory,
+			KdfParallelism: req.KdfParallelism,
+		},
+		key:                 req.Key,
+		publicKey:           req.Keys.PublicKey,
+		encryptedPrivateKey: req.Keys.EncryptedPrivateKey,
+		apiKey:              newSecret(),
+		revisionDate:        revisionDate(),
+	}
+	s.accounts[acc.id] = acc
+
+	// Accounts can be invited before they exist.
+	for _, orgUser := range s.orgUsers {
+		if orgUser.email == acc.email && orgUser.status == orgUserStatusInvited {
+			orgUser.userID = acc.id
+			orgUser.status = orgUserStatusAccepted
+		}
+	}
+	return acc, nil
+}
+
+func (s *Server) findAccountByEmail(email string) *account {
+	for _, acc := range s.accounts {
+		if strings.EqualFold(acc.email, email) {
+			return acc
+		}
+	}
+	return nil
+}
+
+func (s *Server) handlePrelogin(w http.ResponseWriter, r *http.Request) {
+	var req webapi.PreloginRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Like official servers, don't reveal whether an account exists.
+	kdfConfig := s.kdfConfig
+	if acc := s.findAccountByEmail(req.Email); acc != nil {
+		kdfConfig = acc.kdfConfig
+	}
+
+	writeJSON(w, http.StatusOK, webapi.PreloginResponse{
+		Kdf:            kdfConfig.KdfType,
+		KdfIterations:  kdfConfig.KdfIterations,
+		KdfMemory:      kdfConfig.KdfMemory,
+		KdfParallelism: kdfConfig.KdfParallelism,
+	})
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeTokenError(w, "invalid_request", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch grantType := r.PostForm.Get("grant_type"); grantType {
+	case "password":
+		acc := s.findAccountByEmail(r.PostForm.Get("username"))
+		if acc == nil || !secretsEqual(acc.masterPasswordHash, r.PostForm.Get("password")) {
+			writeTokenError(w, "invalid_grant", "Username or password is incorrect. Try again.")
+			return
+		}
+		s.writeUserToken(w, acc, r.PostForm.Get("scope"))
+
+	case "client_credentials":
+		clientID := r.PostForm.Get("client_id")
+		clientSecret := r.PostForm.Get("client_secret")
+
+		switch scope := r.PostForm.Get("scope"); scope {
+		case "api":
+			acc := s.accounts[strings.TrimPrefix(clientID, apiKeyClientIDPrefix)]
+			if !strings.HasPrefix(clientID, apiKeyClientIDPrefix) || acc == nil || !secretsEqual(acc.apiKey, clientSecret) {
+				writeTokenError(w, "invalid_client", "Invalid client credentials.")
+				return
+			}
+			s.writeUserToken(w, acc, scope)
+		case "api.secrets":
+			machineAccount := s.machineAccounts[clientID]
+			if machineAccount == nil || !secretsEqual(machineAccount.secret, clientSecret) {
+				writeTokenError(w, "invalid_client", "Invalid client credentials.")
+				return
+			}
+			s.writeMachineAccountToken(w, machineAccount)
+		default:
+			writeTokenError(w, "invalid_scope", fmt.Sprintf("Unsupported scope '%s'.", scope))
+		}
+
+	default:
+		writeTokenError(w, "unsupported_grant_type", fmt.Sprintf("Unsupported grant type '%s'.", grantType))
+	}
+}
+
+func (s *Server) writeUserToken(w http.ResponseWriter, acc *account, scope string) {
+	accessToken, err := s.issueToken(session{userID: acc.id}, jwt.MapClaims{
+		"sub":     acc.id,
+		"email":   acc.email,
+		"name":    acc.name,
+		"premium": true,
+		"scope":   strings.Fields(scope),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokenResponse{
+		AccessToken:    accessToken,
+		ExpiresIn:      int(accessTokenLifetime.Seconds()),
+		TokenType:      "Bearer",
+		Scope:          scope,
+		Key:            acc.key,
+		PrivateKey:     acc.encryptedPrivateKey,
+		Kdf:            acc.kdfConfig.KdfType,
+		KdfIterations:  acc.kdfConfig.KdfIterations,
+		KdfMemory:      acc.kdfConfig.KdfMemory,
+		KdfParallelism: acc.kdfConfig.KdfParallelism,
+	})
+}
+
+func (s *Server) writeMachineAccountToken(w http.ResponseWriter, machineAccount *machineAccount) {
+	accessToken, err := s.issueToken(session{machineAccountID: machineAccount.id}, jwt.MapClaims{
+		"sub":          machineAccount.id,
+		"client_id":    machineAccount.id,
+		"organization": machineAccount.organizationID,
+		"scope":        []string{"api.secrets"},
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webapi.MachineTokenResponse{
+		AccessToken:      accessToken,
+		ExpireIn:         int(accessTokenLifetime.Seconds()),
+		TokenType:        "Bearer",
+		Scope:            "api.secrets",
+		EncryptedPayload: machineAccount.encryptedPayload,
+	})
+}
+
+func (s *Server) issueToken(sess session, claims jwt.MapClaims) (string, error) {
+	now := time.Now()
+	sess.expiresAt = now.Add(accessTokenLifetime)
+
+	claims["iss"] = tokenIssuer
+	claims["iat"] = now.Unix()
+	claims["nbf"] = now.Unix()
+	claims["exp"] = sess.expiresAt.Unix()
+	claims["jti"] = uuid.New().String()
+
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.signingKey)
+	if err != nil {
+		return "", fmt.Errorf("error signing access token: %w", err)
+	}
+
+	s.sessions[accessToken] = sess
+	return accessToken, nil
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	url := baseURL(r)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"version": Version,
+		"gitHash": "fakeserver",
+		"server":  nil,
+		"environment": map[string]string{
+			"vault":         url,
+			"api":           url + "/api",
+			"identity":      url + "/identity",
+			"notifications": url + "/notifications",
+			"sso":           "",
+		},
+		"featureStates": map[string]bool{
+			"cipher-key-encryption": s.cipherKeyEncryption,
+		},
+		"object": "config",
+	})
+}
+
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var req webapi.SignupRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.createAccount(req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, webapi.RegistrationResponse{Object: "register"})
+}
+
+func (s *Server) handleAPIKey(w http.ResponseWriter, r *http.Request, sess session) {
+	acc, ok := s.requireUser(w, sess)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		MasterPasswordHash string `json:"masterPasswordHash"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !secretsEqual(acc.masterPasswordHash, req.MasterPasswordHash) {
+		writeError(w, http.StatusBadRequest, "Invalid password.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webapi.ApiKey{
+		ApiKey:       acc.apiKey,
+		Object:       "apiKey",
+		RevisionDate: acc.revisionDate,
+	})
+}
+
+func (s *Server) handleProfile(w http.ResponseWriter, _ *http.Request, sess session) {
+	acc, ok := s.requireUser(w, sess)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, s.profile(acc))
+}
+
+func (s *Server) handleRevisionDate(w http.ResponseWriter, _ *http.Request, sess session) {
+	acc, ok := s.requireUser(w, sess)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, acc.revisionDate.UnixMilli())
+}
+
+func (s *Server) handleUserPublicKey(w http.ResponseWriter, r *http.Request, _ session) {
+	acc := s.accounts[r.PathValue("id")]
+	if acc == nil {
+		writeNotFound(w, "User")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webapi.UserPublicKeyResponse{
+		Object:    models.ObjectUserKey,
+		PublicKey: acc.publicKey,
+		UserId:    acc.id,
+	})
+}
+
+func (s *Server) profile(acc *account) webapi.Profile {
+	organizations := []webapi.Organization{}
+	for _, orgUser := range sortedValues(s.orgUsers) {
+		if orgUser.userID != acc.id || orgUser.status != orgUserStatusConfirmed {
+			continue
+		}
+		organizations = append(organizations, webapi.Organization{
+			Id:   orgUser.organizationID,
+			Key:  orgUser.key,
+			Name: s.organizations[orgUser.organizationID].name,
+		})
+	}
+
+	return webapi.Profile{
+		Email:         acc.email,
+		Id:            acc.id,
+		Key:           acc.key,
+		Name:          acc.name,
+		Object:        models.ObjectTypeProfile,
+		Organizations: organizations,
+		PrivateKey:    acc.encryptedPrivateKey,
+	}
+}
+
+// requireUser rejects requests authenticated as a machine account, which
+// only have access to the Secrets Manager endpoints.
+func (s *Server) requireUser(w http.ResponseWriter, sess session) (*account, bool) {
+	acc := s.accounts[sess.userID]
+	if acc == nil {
+		writeError(w, http.StatusForbidden, "This endpoint is only available to users.")
+		return nil, false
+	}
+	return acc, true
+}
+
+// writeTokenError answers like the identity server, which uses the OAuth2
+// error format instead of the API one.
+func writeTokenError(w http.ResponseWriter, code, description string) {
+	writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"error":             code,
+		"error_description": description,
+		"ErrorModel": map[string]string{
+			"Message": description,
+			"Object":  "error",
+		},
+	})
+}
+
+func secretsEqual(expected, actual string) bool {
+	return len(expected) > 0 && subtle.ConstantTimeCompare([]byte(expected), []byte(actual)) == 1
+}
+
+func encodeKey(key []byte) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
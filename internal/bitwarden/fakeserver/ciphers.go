@@ -0,0 +1,529 @@
+package fakeserver
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/webapi"
+)
+
+const maxAttachmentSize = 100 << 20
+
+// cipher is an item as encrypted by the client. Personal items belong to a
+// user, organization items to every confirmed member of their organization.
+type cipher struct {
+	item   models.Item
+	userID string
+}
+
+type folder struct {
+	folder models.Folder
+	userID string
+}
+
+func (s *Server) handleSync(w http.ResponseWriter, r *http.Request, sess session) {
+	acc, ok := s.requireUser(w, sess)
+	if !ok {
+		return
+	}
+
+	ciphers := []models.Item{}
+	for _, c := range sortedValues(s.ciphers) {
+		if s.canAccessCipher(acc, c) {
+			ciphers = append(ciphers, s.cipherResponse(r, c))
+		}
+	}
+
+	folders := []models.Folder{}
+	for _, f := range sortedValues(s.folders) {
+		if f.userID == acc.id {
+			folders = append(folders, f.folder)
+		}
+	}
+
+	collections := []webapi.Collection{}
+	for _, collection := range sortedValues(s.collections) {
+		membership := s.membership(sess, collection.OrganizationId)
+		if membership == nil || !s.canSeeCollection(membership, collection) {
+			continue
+		}
+		collections = append(collections, webapi.Collection{
+			ExternalId:     collection.ExternalId,
+			Id:             collection.Id,
+			Manage:         canManageCollections(membership),
+			Name:           collection.Name,
+			Object:         models.ObjectTypeCollectionDetails,
+			OrganizationId: collection.OrganizationId,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, webapi.SyncResponse{
+		Ciphers:     ciphers,
+		Collections: collections,
+		Folders:     folders,
+		Object:      models.ObjectTypeSync,
+		Profile:     s.profile(acc),
+	})
+}
+
+func (s *Server) handleCreateFolder(w http.ResponseWriter, r *http.Request, sess session) {
+	acc, ok := s.requireUser(w, sess)
+	if !ok {
+		return
+	}
+
+	var req models.Folder
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	f := &folder{
+		folder: models.Folder{
+			ID:           uuid.New().String(),
+			Name:         req.Name,
+			Object:       models.ObjectTypeFolder,
+			RevisionDate: revisionDate(),
+		},
+		userID: acc.id,
+	}
+	s.folders[f.folder.ID] = f
+
+	writeJSON(w, http.StatusOK, f.folder)
+}
+
+func (s *Server) handleEditFolder(w http.ResponseWriter, r *http.Request, sess session) {
+	acc, ok := s.requireUser(w, sess)
+	if !ok {
+		return
+	}
+
+	f := s.folders[r.PathValue("id")]
+	if f == nil || f.userID != acc.id {
+		writeNotFound(w, "Folder")
+		return
+	}
+
+	var req models.Folder
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	f.folder.Name = req.Name
+	f.folder.RevisionDate = revisionDate()
+
+	writeJSON(w, http.StatusOK, f.folder)
+}
+
+func (s *Server) handleDeleteFolder(w http.ResponseWriter, r *http.Request, sess session) {
+	acc, ok := s.requireUser(w, sess)
+	if !ok {
+		return
+	}
+
+	f := s.folders[r.PathValue("id")]
+	if f == nil || f.userID != acc.id {
+		writeNotFound(w, "Folder")
+		return
+	}
+
+	delete(s.folders, f.folder.ID)
+	for _, c := range s.ciphers {
+		if c.item.FolderID == f.folder.ID {
+			c.item.FolderID = ""
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleCreateCipher(w http.ResponseWriter, r *http.Request, sess session) {
+	acc, ok := s.requireUser(w, sess)
+	if !ok {
+		return
+	}
+
+	var req models.Item
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(req.OrganizationID) > 0 {
+		writeError(w, http.StatusBadRequest, "You must select at least one collection.")
+		return
+	}
+
+	s.createCipher(w, r, acc, req, nil)
+}
+
+func (s *Server) handleCreateOrganizationCipher(w http.ResponseWriter, r *http.Request, sess session) {
+	acc, ok := s.requireUser(w, sess)
+	if !ok {
+		return
+	}
+
+	var req webapi.CreateCipherRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(req.Cipher.OrganizationID) == 0 || len(req.CollectionIds) == 0 {
+		writeError(w, http.StatusBadRequest, "You must select at least one collection.")
+		return
+	}
+
+	s.createCipher(w, r, acc, req.Cipher, req.CollectionIds)
+}
+
+func (s *Server) createCipher(w http.ResponseWriter, r *http.Request, acc *account, item models.Item, collectionIds []string) {
+	if err := s.checkCipherReferences(acc, item, collectionIds); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	now := revisionDate()
+	item.ID = uuid.New().String()
+	item.Attachments = nil
+	item.CollectionIds = collectionIds
+	item.CreationDate = now
+	item.DeletedDate = nil
+	item.RevisionDate = now
+
+	c := &cipher{item: item}
+	if len(item.OrganizationID) == 0 {
+		c.userID = acc.id
+	}
+	s.ciphers[item.ID] = c
+
+	writeJSON(w, http.StatusOK, s.cipherResponse(r, c))
+}
+
+func (s *Server) handleEditCipher(w http.ResponseWriter, r *http.Request, sess session) {
+	acc, ok := s.requireUser(w, sess)
+	if !ok {
+		return
+	}
+
+	c := s.ciphers[r.PathValue("id")]
+	if c == nil || !s.canAccessCipher(acc, c) {
+		writeNotFound(w, "Cipher")
+		return
+	}
+
+	var req webapi.EditItemRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Like official servers, editions of items revised more than a second
+	// after the revision the client knows are rejected.
+	if req.LastKnownRevisionDate != nil && c.item.RevisionDate != nil && c.item.RevisionDate.Sub(*req.LastKnownRevisionDate) > time.Second {
+		writeError(w, http.StatusBadRequest, "The cipher you are updating is out of date. Please save your work, sync your vault, and try again.")
+		return
+	}
+	if req.OrganizationID != c.item.OrganizationID {
+		writeError(w, http.StatusBadRequest, "Moving an item to another organization requires sharing it.")
+		return
+	}
+	if err := s.checkCipherReferences(acc, req.Item, c.item.CollectionIds); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Like official servers, attachments are only updated from 'attachments2'.
+	attachments := slices.Clone(c.item.Attachments)
+	for i, attachment := range attachments {
+		if update, ok := req.Attachments2[attachment.ID]; ok {
+			attachments[i].FileName = update.FileName
+			attachments[i].Key = update.Key
+		}
+	}
+
+	item := req.Item
+	item.ID = c.item.ID
+	item.Attachments = attachments
+	item.CollectionIds = c.item.CollectionIds
+	item.CreationDate = c.item.CreationDate
+	item.DeletedDate = c.item.DeletedDate
+	item.RevisionDate = revisionDate()
+	c.item = item
+
+	writeJSON(w, http.StatusOK, s.cipherResponse(r, c))
+}
+
+func (s *Server) handleDeleteCipher(w http.ResponseWriter, r *http.Request, sess session) {
+	acc, ok := s.requireUser(w, sess)
+	if !ok {
+		return
+	}
+
+	c := s.ciphers[r.PathValue("id")]
+	if c == nil || !s.canAccessCipher(acc, c) {
+		writeNotFound(w, "Cipher")
+		return
+	}
+
+	// Deleted items go to the trash, where they remain visible in syncs.
+	c.item.DeletedDate = revisionDate()
+	c.item.RevisionDate = c.item.DeletedDate
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleEditCipherCollections(w http.ResponseWriter, r *http.Request, sess session) {
+	acc, ok := s.requireUser(w, sess)
+	if !ok {
+		return
+	}
+
+	c := s.ciphers[r.PathValue("id")]
+	if c == nil || !s.canAccessCipher(acc, c) {
+		writeNotFound(w, "Cipher")
+		return
+	}
+	if len(c.item.OrganizationID) == 0 {
+		writeError(w, http.StatusBadRequest, "Cipher must belong to an organization.")
+		return
+	}
+
+	var req struct {
+		CollectionIds []string `json:"collectionIds"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.checkCipherReferences(acc, c.item, req.CollectionIds); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.item.CollectionIds = req.CollectionIds
+	c.item.RevisionDate = revisionDate()
+
+	writeJSON(w, http.StatusOK, s.cipherResponse(r, c))
+}
+
+func (s *Server) handleCreateAttachment(w http.ResponseWriter, r *http.Request, sess session) {
+	acc, ok := s.requireUser(w, sess)
+	if !ok {
+		return
+	}
+
+	c := s.ciphers[r.PathValue("id")]
+	if c == nil || !s.canAccessCipher(acc, c) {
+		writeNotFound(w, "Cipher")
+		return
+	}
+
+	var req webapi.AttachmentRequestData
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.FileSize <= 0 || req.FileSize > maxAttachmentSize {
+		writeError(w, http.StatusBadRequest, "Invalid file size.")
+		return
+	}
+
+	attachment := models.Attachment{
+		ID:       newSecret()[:26],
+		FileName: req.FileName,
+		Key:      req.Key,
+		Object:   models.ObjectTypeAttachment,
+		Size:     strconv.Itoa(req.FileSize),
+		SizeName: sizeName(req.FileSize),
+	}
+	c.item.Attachments = append(c.item.Attachments, attachment)
+	c.item.RevisionDate = revisionDate()
+
+	writeJSON(w, http.StatusOK, webapi.CreateObjectAttachmentResponse{
+		AttachmentId:   attachment.ID,
+		CipherResponse: s.cipherResponse(r, c),
+		FileUploadType: models.FileUploadTypeDirect,
+		Object:         models.ObjectAttachmentFileUpload,
+		Url:            fmt.Sprintf("%s/api/ciphers/%s/attachment/%s", baseURL(r), c.item.ID, attachment.ID),
+	})
+}
+
+func (s *Server) handleUploadAttachment(w http.ResponseWriter, r *http.Request, sess session) {
+	acc, ok := s.requireUser(w, sess)
+	if !ok {
+		return
+	}
+
+	c := s.ciphers[r.PathValue("id")]
+	if c == nil || !s.canAccessCipher(acc, c) {
+		writeNotFound(w, "Cipher")
+		return
+	}
+	attachment := findAttachment(c, r.PathValue("attachmentId"))
+	if attachment == nil {
+		writeNotFound(w, "Attachment")
+		return
+	}
+
+	file, _, err := r.FormFile("data")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid multipart body: %v", err))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxAttachmentSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if strconv.Itoa(len(data)) != attachment.Size {
+		writeError(w, http.StatusBadRequest, "File received does not match the declared file size.")
+		return
+	}
+
+	s.attachmentData[attachmentDataKey(c.item.ID, attachment.ID)] = data
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleGetAttachment(w http.ResponseWriter, r *http.Request, sess session) {
+	acc, ok := s.requireUser(w, sess)
+	if !ok {
+		return
+	}
+
+	c := s.ciphers[r.PathValue("id")]
+	if c == nil || !s.canAccessCipher(acc, c) {
+		writeNotFound(w, "Cipher")
+		return
+	}
+	attachment := findAttachment(c, r.PathValue("attachmentId"))
+	if attachment == nil {
+		writeNotFound(w, "Attachment")
+		return
+	}
+
+	res := *attachment
+	res.Url = attachmentURL(r, c.item.ID, attachment.ID)
+	writeJSON(w, http.StatusOK, res)
+}
+
+// handleDownloadAttachment serves the encrypted content of attachments. On
+// official servers, this is a pre-signed URL of a storage bucket that doesn't
+// need the bearer token.
+func (s *Server) handleDownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.attachmentData[attachmentDataKey(r.PathValue("id"), r.PathValue("attachmentId"))]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = w.Write(data)
+}
+
+func (s *Server) handleDeleteAttachment(w http.ResponseWriter, r *http.Request, sess session) {
+	acc, ok := s.requireUser(w, sess)
+	if !ok {
+		return
+	}
+
+	c := s.ciphers[r.PathValue("id")]
+	if c == nil || !s.canAccessCipher(acc, c) {
+		writeNotFound(w, "Cipher")
+		return
+	}
+	attachmentID := r.PathValue("attachmentId")
+	if findAttachment(c, attachmentID) == nil {
+		writeNotFound(w, "Attachment")
+		return
+	}
+
+	c.item.Attachments = slices.DeleteFunc(c.item.Attachments, func(a models.Attachment) bool { return a.ID == attachmentID })
+	c.item.RevisionDate = revisionDate()
+	delete(s.attachmentData, attachmentDataKey(c.item.ID, attachmentID))
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"cipher": s.cipherResponse(r, c),
+		"object": "deleteAttachment",
+	})
+}
+
+func (s *Server) canAccessCipher(acc *account, c *cipher) bool {
+	if len(c.item.OrganizationID) == 0 {
+		return c.userID == acc.id
+	}
+	return s.membership(session{userID: acc.id}, c.item.OrganizationID) != nil
+}
+
+func (s *Server) checkCipherReferences(acc *account, item models.Item, collectionIds []string) error {
+	if len(item.FolderID) > 0 {
+		if f := s.folders[item.FolderID]; f == nil || f.userID != acc.id {
+			return fmt.Errorf("folder '%s' doesn't exist", item.FolderID)
+		}
+	}
+	if len(item.OrganizationID) > 0 && s.membership(session{userID: acc.id}, item.OrganizationID) == nil {
+		return fmt.Errorf("organization '%s' doesn't exist", item.OrganizationID)
+	}
+	for _, collectionID := range collectionIds {
+		if collection := s.collections[collectionID]; collection == nil || collection.OrganizationId != item.OrganizationID {
+			return fmt.Errorf("collection '%s' doesn't exist in organization '%s'", collectionID, item.OrganizationID)
+		}
+	}
+	return nil
+}
+
+// cipherResponse renders a cipher like official servers do in sync and write
+// responses.
+func (s *Server) cipherResponse(r *http.Request, c *cipher) models.Item {
+	item := c.item
+	item.CollectionIds = slices.Clone(c.item.CollectionIds)
+	item.Edit = true
+	item.Object = models.ObjectCipherDetails
+	item.OrganizationUseTotp = len(item.OrganizationID) > 0
+	item.ViewPassword = true
+
+	item.Attachments = make([]models.Attachment, len(c.item.Attachments))
+	for i, attachment := range c.item.Attachments {
+		attachment.Url = attachmentURL(r, c.item.ID, attachment.ID)
+		item.Attachments[i] = attachment
+	}
+	return item
+}
+
+func findAttachment(c *cipher, attachmentID string) *models.Attachment {
+	for i := range c.item.Attachments {
+		if c.item.Attachments[i].ID == attachmentID {
+			return &c.item.Attachments[i]
+		}
+	}
+	return nil
+}
+
+func attachmentURL(r *http.Request, cipherID, attachmentID string) string {
+	return fmt.Sprintf("%s/attachments/%s/%s", baseURL(r), cipherID, attachmentID)
+}
+
+func attachmentDataKey(cipherID, attachmentID string) string {
+	return cipherID + "/" + attachmentID
+}
+
+func sizeName(size int) string {
+	units := []string{"Bytes", "KB", "MB", "GB"}
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d %s", size, units[0])
+	}
+	return fmt.Sprintf("%.2f %s", value, units[unit])
+}
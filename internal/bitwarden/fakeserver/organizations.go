@@ -0,0 +1,543 @@
+package fakeserver
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/keybuilder"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/symmetrickey"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/webapi"
+)
+
+const defaultCollectionName = "Default collection"
+
+type orgUserStatus int
+
+const (
+	orgUserStatusInvited   orgUserStatus = 0
+	orgUserStatusAccepted  orgUserStatus = 1
+	orgUserStatusConfirmed orgUserStatus = 2
+)
+
+type organization struct {
+	id                  string
+	name                string
+	billingEmail        string
+	publicKey           string
+	encryptedPrivateKey string
+
+	// key is only known for organizations created with CreateOrganization,
+	// and is needed to issue access tokens for machine accounts.
+	key *symmetrickey.Key
+}
+
+type orgUser struct {
+	id             string
+	organizationID string
+	userID         string
+	email          string
+	role           models.OrgMemberRoleType
+	status         orgUserStatus
+
+	// key is the organization key wrapped with the user's public key, set when
+	// the membership is confirmed.
+	key string
+}
+
+// CreateOrganization creates an organization owned by an existing account, and
+// a default collection in it. The organization key is wrapped with the owner's
+// public key like a client would, but the server remembers it in order to
+// issue access tokens with CreateMachineAccount.
+func (s *Server) CreateOrganization(ownerEmail, name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	owner := s.findAccountByEmail(ownerEmail)
+	if owner == nil {
+		return "", fmt.Errorf("account '%s' not found", ownerEmail)
+	}
+
+	publicKey, err := parsePublicKey(owner.publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	encOrgKey, orgKey, err := keybuilder.GenerateSharedKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("error generating organization key: %w", err)
+	}
+
+	orgPublicKey, orgEncryptedPrivateKey, err := keybuilder.GenerateEncryptedRSAKeyPair(*orgKey)
+	if err != nil {
+		return "", fmt.Errorf("error generating organization key pair: %w", err)
+	}
+
+	collectionName, err := crypto.EncryptAsString([]byte(defaultCollectionName), *orgKey)
+	if err != nil {
+		return "", fmt.Errorf("error encrypting collection name: %w", err)
+	}
+
+	org := s.createOrganization(owner, webapi.CreateOrganizationRequest{
+		Name:           name,
+		BillingEmail:   owner.email,
+		CollectionName: collectionName,
+		Key:            encOrgKey,
+		Keys: webapi.KeyPair{
+			PublicKey:           orgPublicKey,
+			EncryptedPrivateKey: orgEncryptedPrivateKey,
+		},
+	})
+	org.key = orgKey
+	return org.id, nil
+}
+
+func (s *Server) createOrganization(owner *account, req webapi.CreateOrganizationRequest) *organization {
+	org := &organization{
+		id:                  uuid.New().String(),
+		name:                req.Name,
+		billingEmail:        req.BillingEmail,
+		publicKey:           req.Keys.PublicKey,
+		encryptedPrivateKey: req.Keys.EncryptedPrivateKey,
+	}
+	s.organizations[org.id] = org
+
+	ownerMembership := &orgUser{
+		id:             uuid.New().String(),
+		organizationID: org.id,
+		userID:         owner.id,
+		email:          owner.email,
+		role:           models.OrgMemberRoleTypeOwner,
+		status:         orgUserStatusConfirmed,
+		key:            req.Key,
+	}
+	s.orgUsers[ownerMembership.id] = ownerMembership
+
+	if len(req.CollectionName) > 0 {
+		collection := &webapi.Collection{
+			Id:             uuid.New().String(),
+			Name:           req.CollectionName,
+			OrganizationId: org.id,
+			Users: []webapi.CollectionMember{
+				{Id: ownerMembership.id, Manage: true},
+			},
+			Groups: []webapi.CollectionMember{},
+		}
+		s.collections[collection.Id] = collection
+	}
+	return org
+}
+
+func (s *Server) handleCreateOrganization(w http.ResponseWriter, r *http.Request, sess session) {
+	acc, ok := s.requireUser(w, sess)
+	if !ok {
+		return
+	}
+
+	var req webapi.CreateOrganizationRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(req.Name) == 0 || len(req.Key) == 0 {
+		writeError(w, http.StatusBadRequest, "The Name and Key fields are required.")
+		return
+	}
+
+	org := s.createOrganization(acc, req)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":           org.id,
+		"name":         org.name,
+		"billingEmail": org.billingEmail,
+		"object":       "organization",
+	})
+}
+
+func (s *Server) handleListCollections(w http.ResponseWriter, r *http.Request, sess session) {
+	membership, ok := s.requireOrganizationRole(w, sess, r.PathValue("orgId"))
+	if !ok {
+		return
+	}
+
+	collections := []webapi.Collection{}
+	for _, collection := range sortedValues(s.collections) {
+		if collection.OrganizationId != membership.organizationID || !s.canSeeCollection(membership, collection) {
+			continue
+		}
+
+		details := *collection
+		details.Users = nonNilMembers(collection.Users)
+		details.Groups = nonNilMembers(collection.Groups)
+		details.Assigned = true
+		details.Manage = canManageCollections(membership)
+		details.Object = "collectionAccessDetails"
+		collections = append(collections, details)
+	}
+
+	writeJSON(w, http.StatusOK, webapi.CollectionAccessResponse{
+		Data:   collections,
+		Object: models.ObjectTypeList,
+	})
+}
+
+func (s *Server) handleCreateCollection(w http.ResponseWriter, r *http.Request, sess session) {
+	membership, ok := s.requireOrganizationRole(w, sess, r.PathValue("orgId"), models.OrgMemberRoleTypeOwner, models.OrgMemberRoleTypeAdmin, models.OrgMemberRoleTypeManager)
+	if !ok {
+		return
+	}
+
+	var req webapi.Collection
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.checkCollectionMembers(membership.organizationID, req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	collection := &webapi.Collection{
+		Id:             uuid.New().String(),
+		ExternalId:     req.ExternalId,
+		Name:           req.Name,
+		OrganizationId: membership.organizationID,
+		Users:          req.Users,
+		Groups:         req.Groups,
+	}
+	s.collections[collection.Id] = collection
+
+	writeJSON(w, http.StatusOK, collectionResponse(collection))
+}
+
+func (s *Server) handleEditCollection(w http.ResponseWriter, r *http.Request, sess session) {
+	membership, ok := s.requireOrganizationRole(w, sess, r.PathValue("orgId"), models.OrgMemberRoleTypeOwner, models.OrgMemberRoleTypeAdmin, models.OrgMemberRoleTypeManager)
+	if !ok {
+		return
+	}
+
+	collection := s.collections[r.PathValue("id")]
+	if collection == nil || collection.OrganizationId != membership.organizationID {
+		writeNotFound(w, "Collection")
+		return
+	}
+
+	var req webapi.Collection
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.checkCollectionMembers(membership.organizationID, req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	collection.ExternalId = req.ExternalId
+	collection.Name = req.Name
+	collection.Users = req.Users
+	collection.Groups = req.Groups
+
+	writeJSON(w, http.StatusOK, collectionResponse(collection))
+}
+
+func (s *Server) handleDeleteCollection(w http.ResponseWriter, r *http.Request, sess session) {
+	membership, ok := s.requireOrganizationRole(w, sess, r.PathValue("orgId"), models.OrgMemberRoleTypeOwner, models.OrgMemberRoleTypeAdmin, models.OrgMemberRoleTypeManager)
+	if !ok {
+		return
+	}
+
+	collection := s.collections[r.PathValue("id")]
+	if collection == nil || collection.OrganizationId != membership.organizationID {
+		writeNotFound(w, "Collection")
+		return
+	}
+
+	delete(s.collections, collection.Id)
+	for _, c := range s.ciphers {
+		c.item.CollectionIds = slices.DeleteFunc(c.item.CollectionIds, func(id string) bool { return id == collection.Id })
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleListGroups(w http.ResponseWriter, r *http.Request, sess session) {
+	membership, ok := s.requireOrganizationRole(w, sess, r.PathValue("orgId"))
+	if !ok {
+		return
+	}
+
+	groups := []webapi.OrganizationGroupDetails{}
+	for _, group := range sortedValues(s.groups) {
+		if group.OrganizationID != membership.organizationID {
+			continue
+		}
+		groups = append(groups, webapi.OrganizationGroupDetails{
+			AccessAll:      group.AccessAll,
+			Id:             group.ID,
+			Name:           group.Name,
+			Object:         "group",
+			OrganizationId: group.OrganizationID,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, webapi.OrganizationGroupList{
+		Data:   groups,
+		Object: models.ObjectTypeList,
+	})
+}
+
+func (s *Server) handleCreateGroup(w http.ResponseWriter, r *http.Request, sess session) {
+	membership, ok := s.requireOrganizationRole(w, sess, r.PathValue("orgId"), models.OrgMemberRoleTypeOwner, models.OrgMemberRoleTypeAdmin)
+	if !ok {
+		return
+	}
+
+	var group models.OrgGroup
+	if err := decodeBody(r, &group); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(group.Name) == 0 {
+		writeError(w, http.StatusBadRequest, "The Name field is required.")
+		return
+	}
+
+	group.ID = uuid.New().String()
+	group.OrganizationID = membership.organizationID
+	s.groups[group.ID] = &group
+
+	writeJSON(w, http.StatusOK, group)
+}
+
+func (s *Server) handleGetGroup(w http.ResponseWriter, r *http.Request, sess session) {
+	membership, ok := s.requireOrganizationRole(w, sess, r.PathValue("orgId"))
+	if !ok {
+		return
+	}
+
+	group := s.groups[r.PathValue("id")]
+	if group == nil || group.OrganizationID != membership.organizationID {
+		writeNotFound(w, "Group")
+		return
+	}
+	writeJSON(w, http.StatusOK, group)
+}
+
+func (s *Server) handleDeleteGroup(w http.ResponseWriter, r *http.Request, sess session) {
+	membership, ok := s.requireOrganizationRole(w, sess, r.PathValue("orgId"), models.OrgMemberRoleTypeOwner, models.OrgMemberRoleTypeAdmin)
+	if !ok {
+		return
+	}
+
+	group := s.groups[r.PathValue("id")]
+	if group == nil || group.OrganizationID != membership.organizationID {
+		writeNotFound(w, "Group")
+		return
+	}
+
+	delete(s.groups, group.ID)
+	for _, collection := range s.collections {
+		collection.Groups = slices.DeleteFunc(collection.Groups, func(m webapi.CollectionMember) bool { return m.Id == group.ID })
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleListOrganizationUsers(w http.ResponseWriter, r *http.Request, sess session) {
+	membership, ok := s.requireOrganizationRole(w, sess, r.PathValue("orgId"))
+	if !ok {
+		return
+	}
+
+	users := []webapi.OrganizationUserDetails{}
+	for _, orgUser := range sortedValues(s.orgUsers) {
+		if orgUser.organizationID != membership.organizationID {
+			continue
+		}
+
+		details := webapi.OrganizationUserDetails{
+			Email:  orgUser.email,
+			Id:     orgUser.id,
+			Object: "organizationUserUserMiniDetails",
+			Status: int(orgUser.status),
+			Type:   int(orgUser.role),
+			UserId: orgUser.userID,
+		}
+		if acc := s.accounts[orgUser.userID]; acc != nil {
+			details.Name = acc.name
+		}
+		users = append(users, details)
+	}
+
+	writeJSON(w, http.StatusOK, webapi.OrganizationUserList{
+		Data:   users,
+		Object: models.ObjectTypeList,
+	})
+}
+
+func (s *Server) handleInviteUsers(w http.ResponseWriter, r *http.Request, sess session) {
+	membership, ok := s.requireOrganizationRole(w, sess, r.PathValue("orgId"), models.OrgMemberRoleTypeOwner, models.OrgMemberRoleTypeAdmin)
+	if !ok {
+		return
+	}
+
+	var req webapi.InviteUserRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	for _, email := range req.Emails {
+		email = strings.ToLower(email)
+		for _, orgUser := range s.orgUsers {
+			if orgUser.organizationID == membership.organizationID && orgUser.email == email {
+				writeError(w, http.StatusBadRequest, "This user has already been invited.")
+				return
+			}
+		}
+	}
+
+	// Invitations are accepted right away for existing accounts, since there
+	// is no mailbox to click a link in.
+	for _, email := range req.Emails {
+		invited := &orgUser{
+			id:             uuid.New().String(),
+			organizationID: membership.organizationID,
+			email:          strings.ToLower(email),
+			role:           req.Type,
+			status:         orgUserStatusInvited,
+		}
+		if acc := s.findAccountByEmail(email); acc != nil {
+			invited.userID = acc.id
+			invited.status = orgUserStatusAccepted
+		}
+		s.orgUsers[invited.id] = invited
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleConfirmUser(w http.ResponseWriter, r *http.Request, sess session) {
+	membership, ok := s.requireOrganizationRole(w, sess, r.PathValue("orgId"), models.OrgMemberRoleTypeOwner, models.OrgMemberRoleTypeAdmin)
+	if !ok {
+		return
+	}
+
+	invited := s.orgUsers[r.PathValue("id")]
+	if invited == nil || invited.organizationID != membership.organizationID {
+		writeNotFound(w, "User")
+		return
+	}
+	if invited.status != orgUserStatusAccepted {
+		writeError(w, http.StatusBadRequest, "User not valid.")
+		return
+	}
+
+	var req webapi.ConfirmUserRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(req.Key) == 0 {
+		writeError(w, http.StatusBadRequest, "The Key field is required.")
+		return
+	}
+
+	invited.key = req.Key
+	invited.status = orgUserStatusConfirmed
+	w.WriteHeader(http.StatusOK)
+}
+
+// requireOrganizationRole checks that the session belongs to a confirmed
+// member of the organization, with one of the given roles if any.
+func (s *Server) requireOrganizationRole(w http.ResponseWriter, sess session, orgID string, roles ...models.OrgMemberRoleType) (*orgUser, bool) {
+	membership := s.membership(sess, orgID)
+	if membership == nil {
+		writeNotFound(w, "Organization")
+		return nil, false
+	}
+	if len(roles) > 0 && !slices.Contains(roles, membership.role) {
+		writeError(w, http.StatusForbidden, "You do not have permission to perform this action.")
+		return nil, false
+	}
+	return membership, true
+}
+
+func (s *Server) membership(sess session, orgID string) *orgUser {
+	for _, orgUser := range s.orgUsers {
+		if orgUser.organizationID == orgID && orgUser.userID == sess.userID && len(sess.userID) > 0 && orgUser.status == orgUserStatusConfirmed {
+			return orgUser
+		}
+	}
+	return nil
+}
+
+func (s *Server) canSeeCollection(membership *orgUser, collection *webapi.Collection) bool {
+	if canManageCollections(membership) {
+		return true
+	}
+	for _, user := range collection.Users {
+		if user.Id == membership.id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) checkCollectionMembers(orgID string, collection webapi.Collection) error {
+	for _, user := range collection.Users {
+		if orgUser := s.orgUsers[user.Id]; orgUser == nil || orgUser.organizationID != orgID {
+			return fmt.Errorf("organization user '%s' doesn't exist", user.Id)
+		}
+	}
+	for _, g := range collection.Groups {
+		if group := s.groups[g.Id]; group == nil || group.OrganizationID != orgID {
+			return fmt.Errorf("group '%s' doesn't exist", g.Id)
+		}
+	}
+	return nil
+}
+
+func canManageCollections(membership *orgUser) bool {
+	return membership.role != models.OrgMemberRoleTypeUser
+}
+
+// collectionResponse is what official servers return on collection writes:
+// the memberships are left out, and only available on the details endpoint.
+func collectionResponse(collection *webapi.Collection) webapi.Collection {
+	return webapi.Collection{
+		ExternalId:     collection.ExternalId,
+		Id:             collection.Id,
+		Name:           collection.Name,
+		Object:         models.ObjectTypeCollection,
+		OrganizationId: collection.OrganizationId,
+	}
+}
+
+func nonNilMembers(members []webapi.CollectionMember) []webapi.CollectionMember {
+	if members == nil {
+		return []webapi.CollectionMember{}
+	}
+	return members
+}
+
+func parsePublicKey(encodedKey string) (*rsa.PublicKey, error) {
+	decodedKey, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding public key: %w", err)
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(decodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing public key: %w", err)
+	}
+
+	rsaPublicKey, ok := publicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is not an RSA key")
+	}
+	return rsaPublicKey, nil
+}
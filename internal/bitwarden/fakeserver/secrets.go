@@ -0,0 +1,344 @@
+package fakeserver
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/keybuilder"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/webapi"
+)
+
+const accessTokenVersion = "0"
+
+// machineAccount is a Secrets Manager identity. The organization key it gives
+// access to is encrypted with a key derived from the access token, which the
+// server never stores.
+type machineAccount struct {
+	id               string
+	secret           string
+	organizationID   string
+	encryptedPayload string
+}
+
+type bulkDeleteResponse struct {
+	Data   []bulkDeleteResult `json:"data"`
+	Object string             `json:"object"`
+}
+
+type bulkDeleteResult struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// CreateMachineAccount creates a machine account in an organization created
+// with CreateOrganization, and returns its access token.
+func (s *Server) CreateMachineAccount(orgID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	org := s.organizations[orgID]
+	if org == nil {
+		return "", fmt.Errorf("organization '%s' not found", orgID)
+	}
+	if org.key == nil {
+		return "", fmt.Errorf("key of organization '%s' is unknown: it needs to be created with CreateOrganization", orgID)
+	}
+
+	accessTokenKey := make([]byte, 16)
+	if _, err := rand.Read(accessTokenKey); err != nil {
+		return "", fmt.Errorf("error generating access token key: %w", err)
+	}
+
+	payloadKey, err := keybuilder.DeriveFromAccessTokenEncryptionKey(accessTokenKey)
+	if err != nil {
+		return "", fmt.Errorf("error deriving payload key: %w", err)
+	}
+
+	payload, err := json.Marshal(webapi.MachineTokenEncryptedPayload{EncryptionKey: encodeKey(org.key.Key)})
+	if err != nil {
+		return "", fmt.Errorf("error marshalling payload: %w", err)
+	}
+
+	encryptedPayload, err := crypto.EncryptAsString(payload, *payloadKey)
+	if err != nil {
+		return "", fmt.Errorf("error encrypting payload: %w", err)
+	}
+
+	machineAccount := &machineAccount{
+		id:               uuid.New().String(),
+		secret:           newSecret(),
+		organizationID:   org.id,
+		encryptedPayload: encryptedPayload,
+	}
+	s.machineAccounts[machineAccount.id] = machineAccount
+
+	return fmt.Sprintf("%s.%s.%s:%s", accessTokenVersion, machineAccount.id, machineAccount.secret, encodeKey(accessTokenKey)), nil
+}
+
+func (s *Server) handleListProjects(w http.ResponseWriter, r *http.Request, sess session) {
+	orgID := r.PathValue("orgId")
+	if !s.requireSecretsAccess(w, sess, orgID) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webapi.Projects{
+		Data:   s.organizationProjects(orgID),
+		Object: string(models.ObjectTypeList),
+	})
+}
+
+func (s *Server) handleCreateProject(w http.ResponseWriter, r *http.Request, sess session) {
+	orgID := r.PathValue("orgId")
+	if !s.requireSecretsAccess(w, sess, orgID) {
+		return
+	}
+
+	var req webapi.CreateProjectRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	now := time.Now().UTC()
+	project := &models.Project{
+		ID:             uuid.New().String(),
+		OrganizationID: orgID,
+		Name:           req.Name,
+		CreationDate:   now,
+		RevisionDate:   now,
+		Read:           true,
+		Write:          true,
+		Object:         string(models.ObjectProject),
+	}
+	s.projects[project.ID] = project
+
+	writeJSON(w, http.StatusOK, project)
+}
+
+func (s *Server) handleGetProject(w http.ResponseWriter, r *http.Request, sess session) {
+	project := s.projects[r.PathValue("id")]
+	if project == nil || !s.hasSecretsAccess(sess, project.OrganizationID) {
+		writeNotFound(w, "Project")
+		return
+	}
+	writeJSON(w, http.StatusOK, project)
+}
+
+func (s *Server) handleEditProject(w http.ResponseWriter, r *http.Request, sess session) {
+	project := s.projects[r.PathValue("id")]
+	if project == nil || !s.hasSecretsAccess(sess, project.OrganizationID) {
+		writeNotFound(w, "Project")
+		return
+	}
+
+	var req webapi.CreateProjectRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	project.Name = req.Name
+	project.RevisionDate = time.Now().UTC()
+
+	writeJSON(w, http.StatusOK, project)
+}
+
+func (s *Server) handleDeleteProjects(w http.ResponseWriter, r *http.Request, sess session) {
+	var ids []string
+	if err := decodeBody(r, &ids); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	results := []bulkDeleteResult{}
+	for _, id := range ids {
+		project := s.projects[id]
+		if project == nil || !s.hasSecretsAccess(sess, project.OrganizationID) {
+			results = append(results, bulkDeleteResult{ID: id, Error: "not found"})
+			continue
+		}
+
+		delete(s.projects, id)
+		for _, secret := range s.secrets {
+			secret.Projects = slices.DeleteFunc(secret.Projects, func(p models.Project) bool { return p.ID == id })
+		}
+		results = append(results, bulkDeleteResult{ID: id})
+	}
+
+	writeJSON(w, http.StatusOK, bulkDeleteResponse{Data: results, Object: string(models.ObjectTypeList)})
+}
+
+func (s *Server) handleListSecrets(w http.ResponseWriter, r *http.Request, sess session) {
+	orgID := r.PathValue("orgId")
+	if !s.requireSecretsAccess(w, sess, orgID) {
+		return
+	}
+
+	summaries := []webapi.SecretSummary{}
+	for _, secret := range sortedValues(s.secrets) {
+		if secret.OrganizationID == orgID {
+			summaries = append(summaries, s.secretResponse(secret).SecretSummary)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, webapi.SecretsWithProjectsList{
+		Secrets:  summaries,
+		Projects: s.organizationProjects(orgID),
+		Object:   "SecretsWithProjectsList",
+	})
+}
+
+func (s *Server) handleCreateSecret(w http.ResponseWriter, r *http.Request, sess session) {
+	orgID := r.PathValue("orgId")
+	if !s.requireSecretsAccess(w, sess, orgID) {
+		return
+	}
+
+	var req webapi.CreateSecretRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	projects, ok := s.secretProjects(orgID, req.ProjectIDs)
+	if !ok {
+		writeNotFound(w, "Project")
+		return
+	}
+
+	now := time.Now().UTC()
+	secret := &webapi.Secret{
+		SecretSummary: webapi.SecretSummary{
+			ID:             uuid.New().String(),
+			OrganizationID: orgID,
+			Key:            req.Key,
+			CreationDate:   now,
+			RevisionDate:   now,
+			Projects:       projects,
+			Read:           true,
+			Write:          true,
+		},
+		Value:  req.Value,
+		Note:   req.Note,
+		Object: string(models.ObjectSecret),
+	}
+	s.secrets[secret.ID] = secret
+
+	writeJSON(w, http.StatusOK, s.secretResponse(secret))
+}
+
+func (s *Server) handleGetSecret(w http.ResponseWriter, r *http.Request, sess session) {
+	secret := s.secrets[r.PathValue("id")]
+	if secret == nil || !s.hasSecretsAccess(sess, secret.OrganizationID) {
+		writeNotFound(w, "Secret")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.secretResponse(secret))
+}
+
+func (s *Server) handleEditSecret(w http.ResponseWriter, r *http.Request, sess session) {
+	secret := s.secrets[r.PathValue("id")]
+	if secret == nil || !s.hasSecretsAccess(sess, secret.OrganizationID) {
+		writeNotFound(w, "Secret")
+		return
+	}
+
+	var req webapi.CreateSecretRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	projects, ok := s.secretProjects(secret.OrganizationID, req.ProjectIDs)
+	if !ok {
+		writeNotFound(w, "Project")
+		return
+	}
+
+	secret.Key = req.Key
+	secret.Value = req.Value
+	secret.Note = req.Note
+	secret.Projects = projects
+	secret.RevisionDate = time.Now().UTC()
+
+	writeJSON(w, http.StatusOK, s.secretResponse(secret))
+}
+
+func (s *Server) handleDeleteSecrets(w http.ResponseWriter, r *http.Request, sess session) {
+	var ids []string
+	if err := decodeBody(r, &ids); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	results := []bulkDeleteResult{}
+	for _, id := range ids {
+		secret := s.secrets[id]
+		if secret == nil || !s.hasSecretsAccess(sess, secret.OrganizationID) {
+			results = append(results, bulkDeleteResult{ID: id, Error: "not found"})
+			continue
+		}
+
+		delete(s.secrets, id)
+		results = append(results, bulkDeleteResult{ID: id})
+	}
+
+	writeJSON(w, http.StatusOK, bulkDeleteResponse{Data: results, Object: string(models.ObjectTypeList)})
+}
+
+// hasSecretsAccess accepts machine accounts of the organization, as well as
+// its confirmed members.
+func (s *Server) hasSecretsAccess(sess session, orgID string) bool {
+	if machineAccount := s.machineAccounts[sess.machineAccountID]; machineAccount != nil {
+		return machineAccount.organizationID == orgID
+	}
+	return s.membership(sess, orgID) != nil
+}
+
+func (s *Server) requireSecretsAccess(w http.ResponseWriter, sess session, orgID string) bool {
+	if !s.hasSecretsAccess(sess, orgID) {
+		writeNotFound(w, "Organization")
+		return false
+	}
+	return true
+}
+
+func (s *Server) organizationProjects(orgID string) []models.Project {
+	projects := []models.Project{}
+	for _, project := range sortedValues(s.projects) {
+		if project.OrganizationID == orgID {
+			projects = append(projects, *project)
+		}
+	}
+	return projects
+}
+
+// secretProjects resolves the projects a secret is attached to. Only their
+// identifiers are kept, names are filled in when rendering the secret.
+func (s *Server) secretProjects(orgID string, projectIDs []string) ([]models.Project, bool) {
+	projects := []models.Project{}
+	for _, id := range projectIDs {
+		project := s.projects[id]
+		if project == nil || project.OrganizationID != orgID {
+			return nil, false
+		}
+		projects = append(projects, models.Project{ID: id})
+	}
+	return projects, true
+}
+
+func (s *Server) secretResponse(secret *webapi.Secret) webapi.Secret {
+	res := *secret
+	res.Projects = make([]models.Project, len(secret.Projects))
+	for i, p := range secret.Projects {
+		res.Projects[i] = models.Project{ID: p.ID, Name: s.projects[p.ID].Name}
+	}
+	return res
+}
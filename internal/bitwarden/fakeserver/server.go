@@ -0,0 +1,257 @@
+// Package fakeserver implements an in-memory Bitwarden server speaking the
+// subset of the /identity and /api endpoints used by webapi.Client.
+//
+// The server never sees clear-text data: clients derive their keys from the
+// prelogin KDF settings, wrap organization keys with RSA and encrypt ciphers
+// exactly like they would against an official server. This makes it possible
+// to exercise the provider, the embedded client and the official CLI end to
+// end without Docker or network access.
+package fakeserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/webapi"
+)
+
+const (
+	// Version is the server version advertised by the /api/config endpoint.
+	Version = "2025.6.0"
+
+	accessTokenLifetime = time.Hour
+)
+
+var defaultKdfConfig = models.KdfConfiguration{
+	KdfType:       models.KdfTypePBKDF2_SHA256,
+	KdfIterations: 600000,
+}
+
+type Option func(*Server)
+
+// WithCipherKeyEncryption controls whether the server advertises support for
+// per-item cipher keys. It is enabled by default, like on official servers.
+func WithCipherKeyEncryption(enabled bool) Option {
+	return func(s *Server) {
+		s.cipherKeyEncryption = enabled
+	}
+}
+
+// WithKdfConfig sets the KDF used by RegisterAccount and returned on prelogin
+// for unknown accounts. Tests usually lower it to keep logins fast.
+func WithKdfConfig(kdfConfig models.KdfConfiguration) Option {
+	return func(s *Server) {
+		s.kdfConfig = kdfConfig
+	}
+}
+
+// Server is an http.Handler keeping all its state in memory. It is safe for
+// concurrent use.
+type Server struct {
+	cipherKeyEncryption bool
+	kdfConfig           models.KdfConfiguration
+	mux                 *http.ServeMux
+	signingKey          []byte
+
+	mu              sync.Mutex
+	accounts        map[string]*account
+	attachmentData  map[string][]byte
+	ciphers         map[string]*cipher
+	collections     map[string]*webapi.Collection
+	folders         map[string]*folder
+	groups          map[string]*models.OrgGroup
+	machineAccounts map[string]*machineAccount
+	organizations   map[string]*organization
+	orgUsers        map[string]*orgUser
+	projects        map[string]*models.Project
+	secrets         map[string]*webapi.Secret
+	sessions        map[string]session
+}
+
+func New(opts ...Option) *Server {
+	s := &Server{
+		cipherKeyEncryption: true,
+		kdfConfig:           defaultKdfConfig,
+		mux:                 http.NewServeMux(),
+		signingKey:          []byte(newSecret()),
+
+		accounts:        map[string]*account{},
+		attachmentData:  map[string][]byte{},
+		ciphers:         map[string]*cipher{},
+		collections:     map[string]*webapi.Collection{},
+		folders:         map[string]*folder{},
+		groups:          map[string]*models.OrgGroup{},
+		machineAccounts: map[string]*machineAccount{},
+		organizations:   map[string]*organization{},
+		orgUsers:        map[string]*orgUser{},
+		projects:        map[string]*models.Project{},
+		secrets:         map[string]*webapi.Secret{},
+		sessions:        map[string]session{},
+	}
+	for _, o := range opts {
+		o(s)
+	}
+
+	s.routes()
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("POST /identity/accounts/prelogin", s.handlePrelogin)
+	s.mux.HandleFunc("POST /identity/connect/token", s.handleToken)
+
+	s.mux.HandleFunc("GET /api/config", s.handleConfig)
+	s.mux.HandleFunc("POST /api/accounts/register", s.handleRegister)
+	s.mux.HandleFunc("POST /api/accounts/api-key", s.authenticated(s.handleAPIKey))
+	s.mux.HandleFunc("GET /api/accounts/profile", s.authenticated(s.handleProfile))
+	s.mux.HandleFunc("GET /api/accounts/revision-date", s.authenticated(s.handleRevisionDate))
+	s.mux.HandleFunc("GET /api/users/{id}/public-key", s.authenticated(s.handleUserPublicKey))
+	s.mux.HandleFunc("GET /api/sync", s.authenticated(s.handleSync))
+
+	s.mux.HandleFunc("POST /api/folders", s.authenticated(s.handleCreateFolder))
+	s.mux.HandleFunc("PUT /api/folders/{id}", s.authenticated(s.handleEditFolder))
+	s.mux.HandleFunc("DELETE /api/folders/{id}", s.authenticated(s.handleDeleteFolder))
+
+	s.mux.HandleFunc("POST /api/ciphers", s.authenticated(s.handleCreateCipher))
+	s.mux.HandleFunc("POST /api/ciphers/create", s.authenticated(s.handleCreateOrganizationCipher))
+	s.mux.HandleFunc("PUT /api/ciphers/{id}", s.authenticated(s.handleEditCipher))
+	s.mux.HandleFunc("PUT /api/ciphers/{id}/delete", s.authenticated(s.handleDeleteCipher))
+	s.mux.HandleFunc("PUT /api/ciphers/{id}/collections_v2", s.authenticated(s.handleEditCipherCollections))
+	s.mux.HandleFunc("POST /api/ciphers/{id}/attachment/v2", s.authenticated(s.handleCreateAttachment))
+	s.mux.HandleFunc("POST /api/ciphers/{id}/attachment/{attachmentId}", s.authenticated(s.handleUploadAttachment))
+	s.mux.HandleFunc("GET /api/ciphers/{id}/attachment/{attachmentId}", s.authenticated(s.handleGetAttachment))
+	s.mux.HandleFunc("DELETE /api/ciphers/{id}/attachment/{attachmentId}", s.authenticated(s.handleDeleteAttachment))
+	s.mux.HandleFunc("GET /attachments/{id}/{attachmentId}", s.handleDownloadAttachment)
+
+	s.mux.HandleFunc("POST /api/organizations", s.authenticated(s.handleCreateOrganization))
+	s.mux.HandleFunc("GET /api/organizations/{orgId}/collections/details", s.authenticated(s.handleListCollections))
+	s.mux.HandleFunc("POST /api/organizations/{orgId}/collections", s.authenticated(s.handleCreateCollection))
+	s.mux.HandleFunc("PUT /api/organizations/{orgId}/collections/{id}", s.authenticated(s.handleEditCollection))
+	s.mux.HandleFunc("DELETE /api/organizations/{orgId}/collections/{id}", s.authenticated(s.handleDeleteCollection))
+	s.mux.HandleFunc("GET /api/organizations/{orgId}/groups", s.authenticated(s.handleListGroups))
+	s.mux.HandleFunc("POST /api/organizations/{orgId}/groups", s.authenticated(s.handleCreateGroup))
+	s.mux.HandleFunc("GET /api/organizations/{orgId}/groups/{id}/details", s.authenticated(s.handleGetGroup))
+	s.mux.HandleFunc("DELETE /api/organizations/{orgId}/groups/{id}", s.authenticated(s.handleDeleteGroup))
+	s.mux.HandleFunc("GET /api/organizations/{orgId}/users/mini-details", s.authenticated(s.handleListOrganizationUsers))
+	s.mux.HandleFunc("POST /api/organizations/{orgId}/users/invite", s.authenticated(s.handleInviteUsers))
+	s.mux.HandleFunc("POST /api/organizations/{orgId}/users/{id}/confirm", s.authenticated(s.handleConfirmUser))
+
+	s.mux.HandleFunc("GET /api/organizations/{orgId}/projects", s.authenticated(s.handleListProjects))
+	s.mux.HandleFunc("POST /api/organizations/{orgId}/projects", s.authenticated(s.handleCreateProject))
+	s.mux.HandleFunc("GET /api/projects/{id}", s.authenticated(s.handleGetProject))
+	s.mux.HandleFunc("PUT /api/projects/{id}", s.authenticated(s.handleEditProject))
+	s.mux.HandleFunc("POST /api/projects/delete", s.authenticated(s.handleDeleteProjects))
+	s.mux.HandleFunc("GET /api/organizations/{orgId}/secrets", s.authenticated(s.handleListSecrets))
+	s.mux.HandleFunc("POST /api/organizations/{orgId}/secrets", s.authenticated(s.handleCreateSecret))
+	s.mux.HandleFunc("GET /api/secrets/{id}", s.authenticated(s.handleGetSecret))
+	s.mux.HandleFunc("PUT /api/secrets/{id}", s.authenticated(s.handleEditSecret))
+	s.mux.HandleFunc("POST /api/secrets/delete", s.authenticated(s.handleDeleteSecrets))
+}
+
+// session is what an access token grants: either a user, or a machine account
+// of the Secrets Manager.
+type session struct {
+	userID           string
+	machineAccountID string
+	expiresAt        time.Time
+}
+
+type authenticatedHandler func(w http.ResponseWriter, r *http.Request, sess session)
+
+// authenticated resolves the bearer token of the request and holds the state
+// lock while the handler runs.
+func (s *Server) authenticated(next authenticatedHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found {
+			writeError(w, http.StatusUnauthorized, "Missing bearer token.")
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		sess, ok := s.sessions[token]
+		if !ok || time.Now().After(sess.expiresAt) {
+			writeError(w, http.StatusUnauthorized, "Invalid or expired access token.")
+			return
+		}
+		next(w, r, sess)
+	}
+}
+
+func decodeBody(r *http.Request, v interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers with the error body of official servers, which
+// webapi.Client turns into an HTTPError carrying the message.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, webapi.ErrorResponse{
+		Message: message,
+		Object:  "error",
+	})
+}
+
+func writeNotFound(w http.ResponseWriter, kind string) {
+	writeError(w, http.StatusNotFound, fmt.Sprintf("%s doesn't exist", kind))
+}
+
+func newSecret() string {
+	b := make([]byte, 15)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func revisionDate() *time.Time {
+	now := time.Now().UTC()
+	return &now
+}
+
+// baseURL reconstructs the URL the client used to reach the server, so that
+// links returned in responses go through the same listener.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, r.Host)
+}
+
+// sortedValues returns the values of a map ordered by key, so that listings
+// are stable from one request to the next.
+func sortedValues[T any](m map[string]*T) []*T {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	values := make([]*T, len(keys))
+	for i, k := range keys {
+		values[i] = m[k]
+	}
+	return values
+}
@@ -0,0 +1,60 @@
+//go:build offline
+
+package fakeserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/webapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestsWithoutTokenAreRejected(t *testing.T) {
+	httpServer := httptest.NewServer(New())
+	defer httpServer.Close()
+
+	resp, err := http.Get(httpServer.URL + "/api/sync")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestMachineAccountsOnlyAccessSecretsManager(t *testing.T) {
+	server := New(WithKdfConfig(models.KdfConfiguration{KdfType: models.KdfTypePBKDF2_SHA256, KdfIterations: 1000}))
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	account, err := server.RegisterAccount("owner@example.com", "password")
+	require.NoError(t, err)
+	orgID, err := server.CreateOrganization(account.Email, "ACME")
+	require.NoError(t, err)
+	_, err = server.CreateOrganization("missing@example.com", "ACME")
+	assert.Error(t, err)
+
+	accessToken, err := server.CreateMachineAccount(orgID)
+	require.NoError(t, err)
+	assert.Regexp(t, `^0\.[0-9a-f-]{36}\.[0-9a-f]+:[A-Za-z0-9+/=]+$`, accessToken)
+
+	client := webapi.NewClient(httpServer.URL, "device", "dev", webapi.DisableRetries())
+	_, err = client.LoginWithAccessToken(t.Context(), "unknown", "secret")
+	assert.ErrorContains(t, err, "invalid_client")
+
+	credentials, _, _ := strings.Cut(accessToken, ":")
+	parts := strings.Split(credentials, ".")
+	_, err = client.LoginWithAccessToken(t.Context(), parts[1], parts[2])
+	require.NoError(t, err)
+
+	_, err = client.GetProjects(t.Context(), orgID)
+	assert.NoError(t, err)
+
+	_, err = client.GetProjects(t.Context(), "other-org")
+	assert.ErrorContains(t, err, "404")
+
+	_, err = client.Sync(t.Context())
+	assert.ErrorContains(t, err, "403")
+}
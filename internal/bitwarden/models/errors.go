@@ -0,0 +1,115 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorKind classifies errors the same way whatever the client returning
+// them. It is an error itself, so that callers can test for a kind with
+// errors.Is(err, models.ErrorKindNotFound).
+type ErrorKind string
+
+const (
+	ErrorKindAuthFailure      ErrorKind = "authentication failure"
+	ErrorKindConflict         ErrorKind = "conflict"
+	ErrorKindCryptoFailure    ErrorKind = "cryptographic failure"
+	ErrorKindNotFound         ErrorKind = "not found"
+	ErrorKindPermissionDenied ErrorKind = "permission denied"
+	ErrorKindRateLimited      ErrorKind = "rate limited"
+	ErrorKindTransportFailure ErrorKind = "transport failure"
+	ErrorKindUnsupported      ErrorKind = "unsupported feature"
+)
+
+var errorKinds = []ErrorKind{
+	ErrorKindAuthFailure,
+	ErrorKindConflict,
+	ErrorKindCryptoFailure,
+	ErrorKindNotFound,
+	ErrorKindPermissionDenied,
+	ErrorKindRateLimited,
+	ErrorKindTransportFailure,
+	ErrorKindUnsupported,
+}
+
+func (k ErrorKind) Error() string {
+	return string(k)
+}
+
+// Error is an error classified with a kind, and optionally attached to the
+// object it is about. Its message is the one of the underlying error, so that
+// classifying an error doesn't change what users see.
+type Error struct {
+	Kind       ErrorKind
+	ObjectType ObjectType
+	ObjectID   string
+	Err        error
+}
+
+// NewError classifies an error. It returns nil when err is nil.
+func NewError(kind ErrorKind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	kind, ok := target.(ErrorKind)
+	return ok && len(e.Kind) > 0 && kind == e.Kind
+}
+
+// WithObject attaches the object an operation was about to an error, unless
+// one is already attached. It returns nil when err is nil.
+func WithObject(err error, objectType ObjectType, objectID string) error {
+	if err == nil {
+		return nil
+	}
+	if objectType, _ := ObjectOf(err); len(objectType) > 0 {
+		return err
+	}
+	return &Error{ObjectType: objectType, ObjectID: objectID, Err: err}
+}
+
+// ErrorKindOf returns the kind of an error, and false when it wasn't
+// classified.
+func ErrorKindOf(err error) (ErrorKind, bool) {
+	for _, kind := range errorKinds {
+		if errors.Is(err, kind) {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// ObjectOf returns the type and ID of the object an error is about, if any.
+func ObjectOf(err error) (ObjectType, string) {
+	for err != nil {
+		var modelErr *Error
+		if !errors.As(err, &modelErr) {
+			return "", ""
+		}
+		if len(modelErr.ObjectType) > 0 {
+			return modelErr.ObjectType, modelErr.ObjectID
+		}
+		err = modelErr.Err
+	}
+	return "", ""
+}
+
+func newSentinelError(kind ErrorKind, message string) error {
+	return &Error{Kind: kind, Err: errors.New(message)}
+}
+
+// Errorf is a shorthand for NewError(kind, fmt.Errorf(format, a...)).
+func Errorf(kind ErrorKind, format string, a ...interface{}) error {
+	return NewError(kind, fmt.Errorf(format, a...))
+}
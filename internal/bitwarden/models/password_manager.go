@@ -6,15 +6,19 @@ import (
 )
 
 var (
-	ErrObjectNotFound              = errors.New("object not found")
-	ErrAttachmentNotFound          = errors.New("attachment not found")
-	ErrVaultLocked                 = errors.New("vault is locked")
+	ErrObjectNotFound              = newSentinelError(ErrorKindNotFound, "object not found")
+	ErrAttachmentNotFound          = newSentinelError(ErrorKindNotFound, "attachment not found")
+	ErrVaultLocked                 = newSentinelError(ErrorKindAuthFailure, "vault is locked")
+	ErrReadOnlyVault               = newSentinelError(ErrorKindPermissionDenied, "vault is read-only")
+	ErrObjectModified              = newSentinelError(ErrorKindConflict, "object was modified in the Vault")
 	ErrAlreadyLoggedIn             = errors.New("you are already logged in")
-	ErrWrongMasterPassword         = errors.New("invalid master password")
-	ErrLoggedOut                   = errors.New("please login first")
+	ErrWrongMasterPassword         = newSentinelError(ErrorKindAuthFailure, "invalid master password")
+	ErrWrongUserKey                = newSentinelError(ErrorKindAuthFailure, "invalid user key")
+	ErrLoggedOut                   = newSentinelError(ErrorKindAuthFailure, "please login first")
 	ErrItemTypeMismatch            = errors.New("returned object type does not match requested object type")
 	ErrTooManyObjectsFound         = errors.New("too many objects found")
-	ErrNoObjectFoundMatchingFilter = errors.New("no object found matching the filter")
+	ErrNoObjectFoundMatchingFilter = newSentinelError(ErrorKindNotFound, "no object found matching the filter")
+	ErrItemKeyRotationUnsupported  = newSentinelError(ErrorKindUnsupported, "rotating item keys is only supported by the embedded client")
 )
 
 type ItemType int
@@ -79,6 +83,7 @@ const (
 	ObjectTypeList                ObjectType = "list"              // encapsulates collection list response
 	ObjectTypeCollectionDetails   ObjectType = "collectionDetails" // collection listed in sync
 	ObjectTypeCollection          ObjectType = "collection"        // used when refetching collections
+	ObjectTypeOrgGroup            ObjectType = "org-group"
 	ObjectTypeOrgMember           ObjectType = "org-member"
 	ObjectTypeProfile             ObjectType = "profile"
 	ObjectTypeSync                ObjectType = "sync"
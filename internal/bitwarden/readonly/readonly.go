@@ -0,0 +1,131 @@
+// Package readonly wraps clients so that they refuse every write, whatever
+// their implementation, for runs which must never modify the Vault.
+package readonly
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+)
+
+var (
+	_ bitwarden.PasswordManager = &passwordManager{}
+	_ bitwarden.SecretsManager  = &secretsManager{}
+)
+
+// passwordManager passes reads through to a Password Manager client, and
+// fails on writes.
+type passwordManager struct {
+	bitwarden.PasswordManager
+}
+
+// NewPasswordManager wraps a Password Manager client so that it refuses
+// writes.
+func NewPasswordManager(client bitwarden.PasswordManager) bitwarden.PasswordManager {
+	return &passwordManager{PasswordManager: client}
+}
+
+func (c *passwordManager) CreateAttachmentFromContent(_ context.Context, itemId, _ string, _ []byte) (*models.Attachment, error) {
+	return nil, writeError("create an attachment of", models.ObjectTypeItem, itemId)
+}
+
+func (c *passwordManager) CreateAttachmentFromFile(_ context.Context, itemId, _ string) (*models.Attachment, error) {
+	return nil, writeError("create an attachment of", models.ObjectTypeItem, itemId)
+}
+
+func (c *passwordManager) CreateFolder(_ context.Context, _ models.Folder) (*models.Folder, error) {
+	return nil, writeError("create", models.ObjectTypeFolder, "")
+}
+
+func (c *passwordManager) CreateItem(_ context.Context, _ models.Item) (*models.Item, error) {
+	return nil, writeError("create", models.ObjectTypeItem, "")
+}
+
+func (c *passwordManager) CreateOrganizationCollection(_ context.Context, _ models.OrgCollection) (*models.OrgCollection, error) {
+	return nil, writeError("create", models.ObjectTypeOrgCollection, "")
+}
+
+func (c *passwordManager) CreateOrganizationGroup(_ context.Context, _ models.OrgGroup) (*models.OrgGroup, error) {
+	return nil, writeError("create", models.ObjectTypeOrgGroup, "")
+}
+
+func (c *passwordManager) DeleteAttachment(_ context.Context, _, attachmentId string) error {
+	return writeError("delete", models.ObjectTypeAttachment, attachmentId)
+}
+
+func (c *passwordManager) DeleteFolder(_ context.Context, obj models.Folder) error {
+	return writeError("delete", models.ObjectTypeFolder, obj.ID)
+}
+
+func (c *passwordManager) DeleteItem(_ context.Context, obj models.Item) error {
+	return writeError("delete", models.ObjectTypeItem, obj.ID)
+}
+
+func (c *passwordManager) DeleteOrganizationCollection(_ context.Context, obj models.OrgCollection) error {
+	return writeError("delete", models.ObjectTypeOrgCollection, obj.ID)
+}
+
+func (c *passwordManager) DeleteOrganizationGroup(_ context.Context, obj models.OrgGroup) error {
+	return writeError("delete", models.ObjectTypeOrgGroup, obj.ID)
+}
+
+func (c *passwordManager) EditFolder(_ context.Context, obj models.Folder) (*models.Folder, error) {
+	return nil, writeError("edit", models.ObjectTypeFolder, obj.ID)
+}
+
+func (c *passwordManager) EditItem(_ context.Context, obj models.Item) (*models.Item, error) {
+	return nil, writeError("edit", models.ObjectTypeItem, obj.ID)
+}
+
+func (c *passwordManager) EditOrganizationCollection(_ context.Context, obj models.OrgCollection) (*models.OrgCollection, error) {
+	return nil, writeError("edit", models.ObjectTypeOrgCollection, obj.ID)
+}
+
+func (c *passwordManager) RotateItemKey(_ context.Context, obj models.Item) (*models.Item, error) {
+	return nil, writeError("rotate the key of", models.ObjectTypeItem, obj.ID)
+}
+
+// secretsManager passes reads through to a Secrets Manager client, and fails
+// on writes.
+type secretsManager struct {
+	bitwarden.SecretsManager
+}
+
+// NewSecretsManager wraps a Secrets Manager client so that it refuses writes.
+func NewSecretsManager(client bitwarden.SecretsManager) bitwarden.SecretsManager {
+	return &secretsManager{SecretsManager: client}
+}
+
+func (c *secretsManager) CreateProject(_ context.Context, _ models.Project) (*models.Project, error) {
+	return nil, writeError("create", models.ObjectProject, "")
+}
+
+func (c *secretsManager) CreateSecret(_ context.Context, _ models.Secret) (*models.Secret, error) {
+	return nil, writeError("create", models.ObjectSecret, "")
+}
+
+func (c *secretsManager) DeleteProject(_ context.Context, obj models.Project) error {
+	return writeError("delete", models.ObjectProject, obj.ID)
+}
+
+func (c *secretsManager) DeleteSecret(_ context.Context, obj models.Secret) error {
+	return writeError("delete", models.ObjectSecret, obj.ID)
+}
+
+func (c *secretsManager) EditProject(_ context.Context, obj models.Project) (*models.Project, error) {
+	return nil, writeError("edit", models.ObjectProject, obj.ID)
+}
+
+func (c *secretsManager) EditSecret(_ context.Context, obj models.Secret) (*models.Secret, error) {
+	return nil, writeError("edit", models.ObjectSecret, obj.ID)
+}
+
+func writeError(operation string, objectType models.ObjectType, objectID string) error {
+	err := fmt.Errorf("%w: the provider is configured with `read_only`, refusing to %s the %s", models.ErrReadOnlyVault, operation, objectType)
+	if len(objectID) == 0 {
+		return err
+	}
+	return models.WithObject(err, objectType, objectID)
+}
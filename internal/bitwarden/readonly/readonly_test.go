@@ -0,0 +1,63 @@
+//go:build offline
+
+package readonly
+
+import (
+	"testing"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/embedded"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordManagerRefusesWrites(t *testing.T) {
+	vault, err := embedded.NewMemoryVault(t.Context(), "")
+	require.NoError(t, err)
+
+	folder, err := vault.CreateFolder(t.Context(), models.Folder{Name: "Infrastructure"})
+	require.NoError(t, err)
+
+	client := NewPasswordManager(vault)
+
+	fetched, err := client.GetFolder(t.Context(), *folder)
+	require.NoError(t, err)
+	assert.Equal(t, "Infrastructure", fetched.Name)
+
+	_, err = client.CreateItem(t.Context(), models.Item{Name: "Postgres", Type: models.ItemTypeLogin})
+	assert.ErrorIs(t, err, models.ErrReadOnlyVault)
+	assert.ErrorContains(t, err, "refusing to create the item")
+
+	folder.Name = "Renamed"
+	_, err = client.EditFolder(t.Context(), *folder)
+	assert.ErrorIs(t, err, models.ErrReadOnlyVault)
+	objectType, objectID := models.ObjectOf(err)
+	assert.Equal(t, models.ObjectTypeFolder, objectType)
+	assert.Equal(t, folder.ID, objectID)
+
+	assert.ErrorIs(t, client.DeleteFolder(t.Context(), *folder), models.ErrReadOnlyVault)
+
+	fetched, err = vault.GetFolder(t.Context(), *folder)
+	require.NoError(t, err)
+	assert.Equal(t, "Infrastructure", fetched.Name)
+}
+
+func TestSecretsManagerRefusesWrites(t *testing.T) {
+	vault, err := embedded.NewMemoryVault(t.Context(), "")
+	require.NoError(t, err)
+
+	project, err := vault.CreateProject(t.Context(), models.Project{Name: "Backend"})
+	require.NoError(t, err)
+
+	client := NewSecretsManager(vault)
+
+	_, err = client.GetProject(t.Context(), *project)
+	require.NoError(t, err)
+
+	_, err = client.CreateSecret(t.Context(), models.Secret{Key: "KEY", Value: "value", ProjectID: project.ID})
+	assert.ErrorIs(t, err, models.ErrReadOnlyVault)
+	assert.ErrorIs(t, client.DeleteProject(t.Context(), *project), models.ErrReadOnlyVault)
+
+	_, err = vault.GetProject(t.Context(), *project)
+	assert.NoError(t, err)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/crypto/keybuilder"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/redact"
 )
 
 const (
@@ -85,13 +86,16 @@ type Client interface {
 }
 
 func NewClient(serverURL, deviceIdentifier, providerVersion string, opts ...Options) Client {
+	serverURL = strings.TrimSuffix(serverURL, "/")
 	c := &client{
-		device:    DeviceInformation(deviceIdentifier, providerVersion),
-		serverURL: strings.TrimSuffix(serverURL, "/"),
-		httpClient: &http.Client{
-			Transport:     NewRetryRoundTripper(maxConcurrentRequests, maxRetryAttempts, defaultAttemptTimeout, defaultDialTimeout, defaultTLSHandshakeTimeout, defaultResponseHeaderTimeout),
-			CheckRedirect: handleRedirect,
-		},
+		apiURL:      fmt.Sprintf("%s/api", serverURL),
+		device:      DeviceInformation(deviceIdentifier, providerVersion),
+		identityURL: fmt.Sprintf("%s/identity", serverURL),
+		serverURL:   serverURL,
+	}
+	c.httpClient = &http.Client{
+		Transport:     NewRetryRoundTripper(maxConcurrentRequests, maxRetryAttempts, defaultAttemptTimeout, defaultDialTimeout, defaultTLSHandshakeTimeout, defaultResponseHeaderTimeout),
+		CheckRedirect: c.handleRedirect,
 	}
 	for _, o := range opts {
 		o(c)
@@ -101,8 +105,11 @@ func NewClient(serverURL, deviceIdentifier, providerVersion string, opts ...Opti
 }
 
 type client struct {
+	apiURL             string
 	device             deviceInfoWithOfficialFallback
+	headers            map[string]string
 	httpClient         *http.Client
+	identityURL        string
 	serverURL          string
 	sessionAccessToken string
 }
@@ -112,7 +119,7 @@ func (c *client) ClearSession() {
 }
 
 func (c *client) Config(ctx context.Context) (*ConfigResponse, error) {
-	httpReq, err := c.prepareGenericRequest(ctx, "GET", fmt.Sprintf("%s/api/config", c.serverURL), nil)
+	httpReq, err := c.prepareGenericRequest(ctx, "GET", fmt.Sprintf("%s/config", c.apiURL), nil)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing organization user confirmation request: %w", err)
 	}
@@ -121,7 +128,7 @@ func (c *client) Config(ctx context.Context) (*ConfigResponse, error) {
 }
 
 func (c *client) ConfirmOrganizationUser(ctx context.Context, orgID, orgUserId, key string) error {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/api/organizations/%s/users/%s/confirm", c.serverURL, orgID, orgUserId), ConfirmUserRequest{Key: key})
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/organizations/%s/users/%s/confirm", c.apiURL, orgID, orgUserId), ConfirmUserRequest{Key: key})
 	if err != nil {
 		return fmt.Errorf("error preparing organization user confirmation request: %w", err)
 	}
@@ -131,7 +138,7 @@ func (c *client) ConfirmOrganizationUser(ctx context.Context, orgID, orgUserId,
 }
 
 func (c *client) CreateFolder(ctx context.Context, obj models.Folder) (*models.Folder, error) {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/api/folders", c.serverURL), obj)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/folders", c.apiURL), obj)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing folder create request: %w", err)
 	}
@@ -140,7 +147,7 @@ func (c *client) CreateFolder(ctx context.Context, obj models.Folder) (*models.F
 }
 
 func (c *client) CreateOrganizationGroup(ctx context.Context, obj models.OrgGroup) (*models.OrgGroup, error) {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/api/organizations/%s/groups", c.serverURL, obj.OrganizationID), obj)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/organizations/%s/groups", c.apiURL, obj.OrganizationID), obj)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing group create request: %w", err)
 	}
@@ -157,9 +164,9 @@ func (c *client) CreateItem(ctx context.Context, obj models.Item) (*models.Item,
 			Cipher:        obj,
 			CollectionIds: obj.CollectionIds,
 		}
-		httpReq, err = c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/api/ciphers/create", c.serverURL), cipherCreationRequest)
+		httpReq, err = c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/ciphers/create", c.apiURL), cipherCreationRequest)
 	} else {
-		httpReq, err = c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/api/ciphers", c.serverURL), obj)
+		httpReq, err = c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/ciphers", c.apiURL), obj)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error preparing object create request: %w", err)
@@ -169,7 +176,7 @@ func (c *client) CreateItem(ctx context.Context, obj models.Item) (*models.Item,
 }
 
 func (c *client) CreateObjectAttachment(ctx context.Context, itemId string, data []byte, req AttachmentRequestData) (*CreateObjectAttachmentResponse, error) {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/api/ciphers/%s/attachment/v2", c.serverURL, itemId), req)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/ciphers/%s/attachment/v2", c.apiURL, itemId), req)
 	if err != nil {
 		return nil, fmt.Errorf("unable to marshall attachment creation request: %w", err)
 	}
@@ -196,7 +203,7 @@ func (c *client) CreateObjectAttachmentData(ctx context.Context, itemId, attachm
 		return fmt.Errorf("error closing writer: %w", err)
 	}
 
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/api/ciphers/%s/attachment/%s", c.serverURL, itemId, attachmentId), requestBody.Bytes())
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/ciphers/%s/attachment/%s", c.apiURL, itemId, attachmentId), requestBody.Bytes())
 	if err != nil {
 		return fmt.Errorf("error preparing attachment create request: %w", err)
 	}
@@ -208,7 +215,7 @@ func (c *client) CreateObjectAttachmentData(ctx context.Context, itemId, attachm
 }
 
 func (c *client) CreateOrganization(ctx context.Context, req CreateOrganizationRequest) (*CreateOrganizationResponse, error) {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/api/organizations", c.serverURL), req)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/organizations", c.apiURL), req)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing organization creation request: %w", err)
 	}
@@ -217,7 +224,7 @@ func (c *client) CreateOrganization(ctx context.Context, req CreateOrganizationR
 }
 
 func (c *client) CreateOrganizationCollection(ctx context.Context, orgId string, req Collection) (*Collection, error) {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/api/organizations/%s/collections", c.serverURL, orgId), req)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/organizations/%s/collections", c.apiURL, orgId), req)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing organization collection creation request: %w", err)
 	}
@@ -229,7 +236,7 @@ func (c *client) CreateProject(ctx context.Context, project models.Project) (*mo
 	projectCreationRequest := CreateProjectRequest{
 		Name: project.Name,
 	}
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/api/organizations/%s/projects", c.serverURL, project.OrganizationID), projectCreationRequest)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/organizations/%s/projects", c.apiURL, project.OrganizationID), projectCreationRequest)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing secret creation request: %w", err)
 	}
@@ -244,7 +251,7 @@ func (c *client) CreateSecret(ctx context.Context, secret models.Secret) (*Secre
 		Note:       secret.Note,
 		ProjectIDs: []string{secret.ProjectID},
 	}
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/api/organizations/%s/secrets", c.serverURL, secret.OrganizationID), cipherCreationRequest)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/organizations/%s/secrets", c.apiURL, secret.OrganizationID), cipherCreationRequest)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing secret creation request: %w", err)
 	}
@@ -253,7 +260,7 @@ func (c *client) CreateSecret(ctx context.Context, secret models.Secret) (*Secre
 }
 
 func (c *client) DeleteFolder(ctx context.Context, objID string) error {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "DELETE", fmt.Sprintf("%s/api/folders/%s", c.serverURL, objID), nil)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "DELETE", fmt.Sprintf("%s/folders/%s", c.apiURL, objID), nil)
 	if err != nil {
 		return fmt.Errorf("error preparing folder deletion request: %w", err)
 	}
@@ -263,7 +270,7 @@ func (c *client) DeleteFolder(ctx context.Context, objID string) error {
 }
 
 func (c *client) DeleteOrganizationGroup(ctx context.Context, obj models.OrgGroup) error {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "DELETE", fmt.Sprintf("%s/api/organizations/%s/groups/%s", c.serverURL, obj.OrganizationID, obj.ID), nil)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "DELETE", fmt.Sprintf("%s/organizations/%s/groups/%s", c.apiURL, obj.OrganizationID, obj.ID), nil)
 	if err != nil {
 		return fmt.Errorf("error preparing group deletion request: %w", err)
 	}
@@ -273,7 +280,7 @@ func (c *client) DeleteOrganizationGroup(ctx context.Context, obj models.OrgGrou
 }
 
 func (c *client) DeleteObject(ctx context.Context, objID string) error {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "PUT", fmt.Sprintf("%s/api/ciphers/%s/delete", c.serverURL, objID), nil)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "PUT", fmt.Sprintf("%s/ciphers/%s/delete", c.apiURL, objID), nil)
 	if err != nil {
 		return fmt.Errorf("error preparing object deletion request: %w", err)
 	}
@@ -283,7 +290,7 @@ func (c *client) DeleteObject(ctx context.Context, objID string) error {
 }
 
 func (c *client) DeleteObjectAttachment(ctx context.Context, itemId, attachmentId string) error {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "DELETE", fmt.Sprintf("%s/api/ciphers/%s/attachment/%s", c.serverURL, itemId, attachmentId), nil)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "DELETE", fmt.Sprintf("%s/ciphers/%s/attachment/%s", c.apiURL, itemId, attachmentId), nil)
 	if err != nil {
 		return fmt.Errorf("error preparing object attachment deletion request: %w", err)
 	}
@@ -293,7 +300,7 @@ func (c *client) DeleteObjectAttachment(ctx context.Context, itemId, attachmentI
 }
 
 func (c *client) DeleteOrganizationCollection(ctx context.Context, orgID, collectionID string) error {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "DELETE", fmt.Sprintf("%s/api/organizations/%s/collections/%s", c.serverURL, orgID, collectionID), nil)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "DELETE", fmt.Sprintf("%s/organizations/%s/collections/%s", c.apiURL, orgID, collectionID), nil)
 	if err != nil {
 		return fmt.Errorf("error preparing organization collection deletion request: %w", err)
 	}
@@ -304,7 +311,7 @@ func (c *client) DeleteOrganizationCollection(ctx context.Context, orgID, collec
 
 func (c *client) DeleteProject(ctx context.Context, projectId string) error {
 	IDs := []string{projectId}
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/api/projects/delete", c.serverURL), IDs)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/projects/delete", c.apiURL), IDs)
 	if err != nil {
 		return fmt.Errorf("error preparing project deletion request: %w", err)
 	}
@@ -315,7 +322,7 @@ func (c *client) DeleteProject(ctx context.Context, projectId string) error {
 
 func (c *client) DeleteSecret(ctx context.Context, secretId string) error {
 	IDs := []string{secretId}
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/api/secrets/delete", c.serverURL), IDs)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/secrets/delete", c.apiURL), IDs)
 	if err != nil {
 		return fmt.Errorf("error preparing secret deletion request: %w", err)
 	}
@@ -325,7 +332,7 @@ func (c *client) DeleteSecret(ctx context.Context, secretId string) error {
 }
 
 func (c *client) GetCipherAttachment(ctx context.Context, itemId, attachmentId string) (*models.Attachment, error) {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/api/ciphers/%s/attachment/%s", c.serverURL, itemId, attachmentId), nil)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/ciphers/%s/attachment/%s", c.apiURL, itemId, attachmentId), nil)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing item attachment retrieval request: %w", err)
 	}
@@ -333,7 +340,7 @@ func (c *client) GetCipherAttachment(ctx context.Context, itemId, attachmentId s
 }
 
 func (c *client) EditFolder(ctx context.Context, obj models.Folder) (*models.Folder, error) {
-	req, err := c.prepareAuthenticatedRequest(ctx, "PUT", fmt.Sprintf("%s/api/folders/%s", c.serverURL, obj.ID), obj)
+	req, err := c.prepareAuthenticatedRequest(ctx, "PUT", fmt.Sprintf("%s/folders/%s", c.apiURL, obj.ID), obj)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing folder edition request: %w", err)
 	}
@@ -342,7 +349,21 @@ func (c *client) EditFolder(ctx context.Context, obj models.Folder) (*models.Fol
 }
 
 func (c *client) EditItem(ctx context.Context, obj models.Item) (*models.Item, error) {
-	req, err := c.prepareAuthenticatedRequest(ctx, "PUT", fmt.Sprintf("%s/api/ciphers/%s", c.serverURL, obj.ID), obj)
+	itemEditionRequest := EditItemRequest{Item: obj, LastKnownRevisionDate: obj.RevisionDate}
+	for _, attachment := range obj.Attachments {
+		if len(attachment.Key) == 0 {
+			continue
+		}
+		if itemEditionRequest.Attachments2 == nil {
+			itemEditionRequest.Attachments2 = map[string]AttachmentUpdate{}
+		}
+		itemEditionRequest.Attachments2[attachment.ID] = AttachmentUpdate{
+			FileName: attachment.FileName,
+			Key:      attachment.Key,
+		}
+	}
+
+	req, err := c.prepareAuthenticatedRequest(ctx, "PUT", fmt.Sprintf("%s/ciphers/%s", c.apiURL, obj.ID), itemEditionRequest)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing item edition request: %w", err)
 	}
@@ -355,7 +376,7 @@ func (c *client) EditItemCollections(ctx context.Context, objId string, collecti
 		CollectionIDs []string `json:"collectionIds"`
 	}
 
-	req, err := c.prepareAuthenticatedRequest(ctx, "PUT", fmt.Sprintf("%s/api/ciphers/%s/collections_v2", c.serverURL, objId), CollectionChange{
+	req, err := c.prepareAuthenticatedRequest(ctx, "PUT", fmt.Sprintf("%s/ciphers/%s/collections_v2", c.apiURL, objId), CollectionChange{
 		CollectionIDs: collectionIds,
 	})
 	if err != nil {
@@ -366,7 +387,7 @@ func (c *client) EditItemCollections(ctx context.Context, objId string, collecti
 }
 
 func (c *client) EditOrganizationCollection(ctx context.Context, orgId, objId string, obj Collection) (*Collection, error) {
-	req, err := c.prepareAuthenticatedRequest(ctx, "PUT", fmt.Sprintf("%s/api/organizations/%s/collections/%s", c.serverURL, orgId, objId), obj)
+	req, err := c.prepareAuthenticatedRequest(ctx, "PUT", fmt.Sprintf("%s/organizations/%s/collections/%s", c.apiURL, orgId, objId), obj)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing collection edition request: %w", err)
 	}
@@ -378,7 +399,7 @@ func (c *client) EditProject(ctx context.Context, project models.Project) (*mode
 	projectEditionRequest := CreateProjectRequest{
 		Name: project.Name,
 	}
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "PUT", fmt.Sprintf("%s/api/projects/%s", c.serverURL, project.ID), projectEditionRequest)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "PUT", fmt.Sprintf("%s/projects/%s", c.apiURL, project.ID), projectEditionRequest)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing project edition request: %w", err)
 	}
@@ -393,7 +414,7 @@ func (c *client) EditSecret(ctx context.Context, secret models.Secret) (*Secret,
 		Note:       secret.Note,
 		ProjectIDs: []string{secret.ProjectID},
 	}
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "PUT", fmt.Sprintf("%s/api/secrets/%s", c.serverURL, secret.ID), cipherCreationRequest)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "PUT", fmt.Sprintf("%s/secrets/%s", c.apiURL, secret.ID), cipherCreationRequest)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing secret edition request: %w", err)
 	}
@@ -413,7 +434,7 @@ func (c *client) GetAPIKey(ctx context.Context, username, password string, kdfCo
 
 	hashedPassword := crypto.HashPassword(password, *preloginKey, false)
 	obj := ApiKeyRequest{MasterPasswordHash: hashedPassword}
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/api/accounts/api-key", c.serverURL), obj)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/accounts/api-key", c.apiURL), obj)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing api key retrieval request: %w", err)
 	}
@@ -436,7 +457,7 @@ func (c *client) GetContentFromURL(ctx context.Context, url string) ([]byte, err
 }
 
 func (c *client) GetOrganizationCollections(ctx context.Context, orgID string) ([]Collection, error) {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/api/organizations/%s/collections/details", c.serverURL, orgID), nil)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/organizations/%s/collections/details", c.apiURL, orgID), nil)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing collection retrieval request: %w", err)
 	}
@@ -449,7 +470,7 @@ func (c *client) GetOrganizationCollections(ctx context.Context, orgID string) (
 }
 
 func (c *client) GetOrganizationGroup(ctx context.Context, obj models.OrgGroup) (*models.OrgGroup, error) {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/api/organizations/%s/groups/%s/details", c.serverURL, obj.OrganizationID, obj.ID), obj)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/organizations/%s/groups/%s/details", c.apiURL, obj.OrganizationID, obj.ID), obj)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing group retrieval request: %w", err)
 	}
@@ -458,7 +479,7 @@ func (c *client) GetOrganizationGroup(ctx context.Context, obj models.OrgGroup)
 }
 
 func (c *client) GetOrganizationGroups(ctx context.Context, orgId string) ([]OrganizationGroupDetails, error) {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/api/organizations/%s/groups", c.serverURL, orgId), nil)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/organizations/%s/groups", c.apiURL, orgId), nil)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing group retrieval request: %w", err)
 	}
@@ -471,7 +492,7 @@ func (c *client) GetOrganizationGroups(ctx context.Context, orgId string) ([]Org
 }
 
 func (c *client) GetOrganizationUsers(ctx context.Context, orgId string) ([]OrganizationUserDetails, error) {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/api/organizations/%s/users/mini-details", c.serverURL, orgId), nil)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/organizations/%s/users/mini-details", c.apiURL, orgId), nil)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing organization user list retrieval request: %w", err)
 	}
@@ -484,7 +505,7 @@ func (c *client) GetOrganizationUsers(ctx context.Context, orgId string) ([]Orga
 }
 
 func (c *client) GetProfile(ctx context.Context) (*Profile, error) {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/api/accounts/profile", c.serverURL), nil)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/accounts/profile", c.apiURL), nil)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing config retrieval request: %w", err)
 	}
@@ -493,7 +514,7 @@ func (c *client) GetProfile(ctx context.Context) (*Profile, error) {
 }
 
 func (c *client) GetProject(ctx context.Context, projectId string) (*models.Project, error) {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/api/projects/%s", c.serverURL, projectId), nil)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/projects/%s", c.apiURL, projectId), nil)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing project retrieval request: %w", err)
 	}
@@ -502,7 +523,7 @@ func (c *client) GetProject(ctx context.Context, projectId string) (*models.Proj
 }
 
 func (c *client) GetProjects(ctx context.Context, orgId string) ([]models.Project, error) {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/api/organizations/%s/projects", c.serverURL, orgId), nil)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/organizations/%s/projects", c.apiURL, orgId), nil)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing projects retrieval request: %w", err)
 	}
@@ -518,7 +539,7 @@ func (c *client) GetProjects(ctx context.Context, orgId string) ([]models.Projec
 }
 
 func (c *client) GetSecret(ctx context.Context, secretId string) (*Secret, error) {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/api/secrets/%s", c.serverURL, secretId), nil)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/secrets/%s", c.apiURL, secretId), nil)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing secret retrieval request: %w", err)
 	}
@@ -527,7 +548,7 @@ func (c *client) GetSecret(ctx context.Context, secretId string) (*Secret, error
 }
 
 func (c *client) GetSecrets(ctx context.Context, orgId string) ([]SecretSummary, error) {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/api/organizations/%s/secrets", c.serverURL, orgId), nil)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/organizations/%s/secrets", c.apiURL, orgId), nil)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing secrets retrieval request: %w", err)
 	}
@@ -540,7 +561,7 @@ func (c *client) GetSecrets(ctx context.Context, orgId string) ([]SecretSummary,
 }
 
 func (c *client) GetUserPublicKey(ctx context.Context, userId string) ([]byte, error) {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/api/users/%s/public-key", c.serverURL, userId), nil)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/users/%s/public-key", c.apiURL, userId), nil)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing user public key retrieval request: %w", err)
 	}
@@ -553,7 +574,7 @@ func (c *client) GetUserPublicKey(ctx context.Context, userId string) ([]byte, e
 }
 
 func (c *client) InviteUser(ctx context.Context, orgId string, inviteRequest InviteUserRequest) error {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/api/organizations/%s/users/invite", c.serverURL, orgId), inviteRequest)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "POST", fmt.Sprintf("%s/organizations/%s/users/invite", c.apiURL, orgId), inviteRequest)
 	if err != nil {
 		return fmt.Errorf("error preparing user invitation request: %w", err)
 	}
@@ -569,7 +590,7 @@ func (c *client) LoginWithAccessToken(ctx context.Context, clientId, clientSecre
 	form.Add("client_secret", clientSecret)
 	form.Add("grant_type", "client_credentials")
 
-	httpReq, err := c.prepareGenericRequest(ctx, "POST", fmt.Sprintf("%s/identity/connect/token", c.serverURL), form)
+	httpReq, err := c.prepareGenericRequest(ctx, "POST", fmt.Sprintf("%s/connect/token", c.identityURL), form)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing login with access token request: %w", err)
 	}
@@ -605,7 +626,7 @@ func (c *client) LoginWithPassword(ctx context.Context, username, password strin
 	form.Add("deviceIdentifier", c.device.official.deviceIdentifier)
 	form.Add("deviceName", c.device.official.deviceName)
 
-	httpReq, err := c.prepareGenericRequest(ctx, "POST", fmt.Sprintf("%s/identity/connect/token", c.serverURL), form)
+	httpReq, err := c.prepareGenericRequest(ctx, "POST", fmt.Sprintf("%s/connect/token", c.identityURL), form)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing login with password request: %w", err)
 	}
@@ -651,7 +672,7 @@ func (c *client) LoginWithAPIKey(ctx context.Context, clientId, clientSecret str
 	form.Add("deviceIdentifier", c.device.deviceIdentifier)
 	form.Add("deviceName", c.device.deviceName)
 
-	httpReq, err := c.prepareGenericRequest(ctx, "POST", fmt.Sprintf("%s/identity/connect/token", c.serverURL), form)
+	httpReq, err := c.prepareGenericRequest(ctx, "POST", fmt.Sprintf("%s/connect/token", c.identityURL), form)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing login with api key request: %w", err)
 	}
@@ -665,7 +686,7 @@ func (c *client) LoginWithAPIKey(ctx context.Context, clientId, clientSecret str
 }
 
 func (c *client) PreLogin(ctx context.Context, username string) (*PreloginResponse, error) {
-	httpReq, err := c.prepareGenericRequest(ctx, "POST", fmt.Sprintf("%s/identity/accounts/prelogin", c.serverURL), PreloginRequest{Email: username})
+	httpReq, err := c.prepareGenericRequest(ctx, "POST", fmt.Sprintf("%s/accounts/prelogin", c.identityURL), PreloginRequest{Email: username})
 	if err != nil {
 		return nil, fmt.Errorf("error preparing prelogin request: %w", err)
 	}
@@ -674,7 +695,7 @@ func (c *client) PreLogin(ctx context.Context, username string) (*PreloginRespon
 }
 
 func (c *client) RegisterUser(ctx context.Context, signupRequest SignupRequest) error {
-	httpReq, err := c.prepareGenericRequest(ctx, "POST", fmt.Sprintf("%s/api/accounts/register", c.serverURL), signupRequest)
+	httpReq, err := c.prepareGenericRequest(ctx, "POST", fmt.Sprintf("%s/accounts/register", c.apiURL), signupRequest)
 	if err != nil {
 		return fmt.Errorf("error preparing registration request: %w", err)
 	}
@@ -684,7 +705,7 @@ func (c *client) RegisterUser(ctx context.Context, signupRequest SignupRequest)
 }
 
 func (c *client) Sync(ctx context.Context) (*SyncResponse, error) {
-	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/api/sync?excludeDomains=true", c.serverURL), nil)
+	httpReq, err := c.prepareAuthenticatedRequest(ctx, "GET", fmt.Sprintf("%s/sync?excludeDomains=true", c.apiURL), nil)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing config retrieval request: %w", err)
 	}
@@ -765,15 +786,37 @@ func (c *client) prepareGenericRequest(ctx context.Context, reqMethod, reqUrl st
 	}
 	httpReq.Header.Set("bitwarden-client-version", c.device.official.deviceVersion)
 
+	// Custom headers often carry credentials for a proxy in front of the
+	// Bitwarden server, and must not leak to third parties like the storage
+	// provider of attachments.
+	if c.isServerURL(httpReq.URL) {
+		for name, value := range c.headers {
+			httpReq.Header.Set(name, value)
+		}
+	}
+
 	return httpReq, nil
 }
 
+func (c *client) isServerURL(reqUrl *url.URL) bool {
+	for _, rawURL := range []string{c.serverURL, c.apiURL, c.identityURL} {
+		serverURL, err := url.Parse(rawURL)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(reqUrl.Scheme, serverURL.Scheme) && strings.EqualFold(reqUrl.Host, serverURL.Host) {
+			return true
+		}
+	}
+	return false
+}
+
 func doRequest[T any](ctx context.Context, httpClient *http.Client, httpReq *http.Request) (*T, error) {
 	reqBody := readAndRestoreRequestBody(ctx, httpReq)
 
 	httpResp, err := httpClient.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("error doing request to '%s': %w", httpReq.URL, err)
+		return nil, models.Errorf(models.ErrorKindTransportFailure, "error doing request to '%s': %w", httpReq.URL, err)
 	}
 	defer httpResp.Body.Close()
 
@@ -810,7 +853,7 @@ func doRequest[T any](ctx context.Context, httpClient *http.Client, httpReq *htt
 	}
 	err = json.Unmarshal(respBody, &res)
 	if err != nil {
-		fmt.Printf("Body to unmarshall: %s\n", string(respBody))
+		tflog.Trace(ctx, "Unable to unmarshal response", map[string]interface{}{"body": redact.Body(httpResp.Header.Get("Content-Type"), respBody)})
 		return nil, fmt.Errorf("error unmarshalling response from '%s': %w", httpReq.URL, err)
 	}
 
@@ -822,19 +865,19 @@ func logRequest(ctx context.Context, httpReq *http.Request, reqBody []byte, http
 	responseInfo := map[string]interface{}{}
 
 	if httpReq != nil {
-		requestInfo["url"] = httpReq.URL.RequestURI()
+		requestInfo["url"] = redact.URL(&url.URL{Path: httpReq.URL.Path, RawQuery: httpReq.URL.RawQuery})
 		requestInfo["method"] = httpReq.Method
-		requestInfo["headers"] = httpReq.Header
+		requestInfo["headers"] = redact.Headers(httpReq.Header)
 		if len(reqBody) > 0 {
-			requestInfo["body"] = string(reqBody)
+			requestInfo["body"] = redact.Body(httpReq.Header.Get("Content-Type"), reqBody)
 		}
 	}
 
 	if httpResp != nil {
 		responseInfo["status_code"] = httpResp.StatusCode
-		responseInfo["headers"] = httpResp.Header
+		responseInfo["headers"] = redact.Headers(httpResp.Header)
 		if len(respBody) > 0 {
-			responseInfo["body"] = string(respBody)
+			responseInfo["body"] = redact.Body(httpResp.Header.Get("Content-Type"), respBody)
 		}
 	}
 
@@ -875,9 +918,12 @@ func readReader(rc io.ReadCloser) ([]byte, io.ReadCloser, error) {
 	return body, io.NopCloser(bytes.NewReader(buf.Bytes())), nil
 }
 
-func handleRedirect(req *http.Request, via []*http.Request) error {
+func (c *client) handleRedirect(req *http.Request, via []*http.Request) error {
 	if len(via) > 0 && req.URL.Host != via[0].URL.Host {
 		req.Header.Del("Authorization")
+		for name := range c.headers {
+			req.Header.Del(name)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,272 @@
+package webapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/redact"
+)
+
+const harCreatorName = "terraform-provider-bitwarden"
+
+var (
+	harRecordersMu sync.Mutex
+	harRecorders   = map[string]*harRecorder{}
+)
+
+// harFooter closes the entries of a HAR file, and is rewritten after each
+// new entry.
+const harFooter = "\n    ]\n  }\n}\n"
+
+// harRecorder writes the HTTP exchanges of all the clients of the process
+// configured with the same path to a HAR file, with secrets redacted. Each
+// exchange is appended in place of the closing brackets, which are written
+// back after it, so that the file is complete even when the process is
+// killed without rewriting the previous exchanges.
+type harRecorder struct {
+	mu      sync.Mutex
+	path    string
+	version string
+	entries int
+
+	// footerOffset is where the closing brackets of the file start.
+	footerOffset int64
+}
+
+func harRecorderFor(path, version string) *harRecorder {
+	harRecordersMu.Lock()
+	defer harRecordersMu.Unlock()
+
+	if r, ok := harRecorders[path]; ok {
+		return r
+	}
+	r := &harRecorder{path: path, version: version}
+	harRecorders[path] = r
+	return r
+}
+
+type harLog struct {
+	Log harLogContent `json:"log"`
+}
+
+type harLogContent struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// recordAttempt adds an attempt of the retry round tripper to the HAR file,
+// buffering the response body so that it can still be read afterwards.
+func (r *harRecorder) recordAttempt(httpReq *http.Request, httpResp *http.Response, err error, started time.Time) {
+	var respBody []byte
+	if readErr := preserveResponseBody(httpResp); readErr != nil {
+		err = errors.Join(err, readErr)
+	} else if httpResp != nil && httpResp.Body != nil {
+		respBody, _ = io.ReadAll(httpResp.Body)
+		httpResp.Body = io.NopCloser(bytes.NewReader(respBody))
+	}
+	r.record(httpReq, requestBody(httpReq), httpResp, respBody, started, err)
+}
+
+// record adds an exchange to the HAR file. Failing to write the file doesn't
+// fail the request, as the file is only meant for troubleshooting.
+func (r *harRecorder) record(httpReq *http.Request, reqBody []byte, httpResp *http.Response, respBody []byte, started time.Time, err error) {
+	duration := float64(time.Since(started).Microseconds()) / 1000
+	entry := harEntry{
+		StartedDateTime: started.Format(time.RFC3339Nano),
+		Time:            duration,
+		Request: harRequest{
+			Method:      httpReq.Method,
+			URL:         redact.URL(httpReq.URL),
+			HTTPVersion: httpReq.Proto,
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(redact.Headers(httpReq.Header)),
+			QueryString: []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(reqBody),
+		},
+		Response: harResponse{
+			Cookies:     []harNameValue{},
+			Headers:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: harTimings{Send: -1, Wait: duration, Receive: -1},
+	}
+	for name, values := range redact.Form(httpReq.URL.Query()) {
+		for _, value := range values {
+			entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{Name: name, Value: value})
+		}
+	}
+	if len(reqBody) > 0 {
+		contentType := httpReq.Header.Get("Content-Type")
+		entry.Request.PostData = &harPostData{MimeType: contentType, Text: redact.Body(contentType, reqBody)}
+	}
+	if httpResp != nil {
+		contentType := httpResp.Header.Get("Content-Type")
+		entry.Response.Status = httpResp.StatusCode
+		entry.Response.StatusText = http.StatusText(httpResp.StatusCode)
+		entry.Response.HTTPVersion = httpResp.Proto
+		entry.Response.Headers = harHeaders(redact.Headers(httpResp.Header))
+		entry.Response.Content = harContent{Size: len(respBody), MimeType: contentType, Text: redact.Body(contentType, respBody)}
+		if location := httpResp.Header.Get("Location"); len(location) > 0 {
+			entry.Response.RedirectURL = redact.Location(location)
+		}
+		entry.Response.BodySize = len(respBody)
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = r.appendLocked(entry)
+}
+
+func (r *harRecorder) appendLocked(entry harEntry) error {
+	out, err := json.MarshalIndent(entry, "      ", "  ")
+	if err != nil {
+		return err
+	}
+
+	if r.entries == 0 {
+		if err := r.createLocked(); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(r.path, os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	separator := ",\n      "
+	if r.entries == 0 {
+		separator = "\n      "
+	}
+	content := append([]byte(separator), out...)
+	if _, err := f.WriteAt(append(content, harFooter...), r.footerOffset); err != nil {
+		return err
+	}
+	r.entries++
+	r.footerOffset += int64(len(content))
+	return nil
+}
+
+// createLocked writes a HAR file without entries, replacing the one a
+// previous run left.
+func (r *harRecorder) createLocked() error {
+	creator, err := json.Marshal(harCreator{Name: harCreatorName, Version: r.version})
+	if err != nil {
+		return err
+	}
+	header := fmt.Sprintf("{\n  \"log\": {\n    \"version\": \"1.2\",\n    \"creator\": %s,\n    \"entries\": [", creator)
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0700); err != nil {
+		return err
+	}
+	tmpPath := fmt.Sprintf("%s.tmp", r.path)
+	if err := os.WriteFile(tmpPath, []byte(header+harFooter), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, r.path); err != nil {
+		return err
+	}
+	r.footerOffset = int64(len(header))
+	return nil
+}
+
+func harHeaders(headers http.Header) []harNameValue {
+	res := []harNameValue{}
+	for name, values := range headers {
+		for _, value := range values {
+			res = append(res, harNameValue{Name: name, Value: value})
+		}
+	}
+	return res
+}
+
+// requestBody returns a copy of the body of a request, without consuming it.
+func requestBody(httpReq *http.Request) []byte {
+	if httpReq.Body == nil || httpReq.GetBody == nil {
+		return nil
+	}
+	body, err := httpReq.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+
+	content, _ := io.ReadAll(body)
+	return content
+}
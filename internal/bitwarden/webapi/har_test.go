@@ -0,0 +1,79 @@
+//go:build offline
+
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHARFileRecordsRedactedExchanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"secret-access-token","expires_in":3600,"token_type":"Bearer","Key":"2.protected-key"}`))
+	}))
+	defer server.Close()
+
+	harPath := filepath.Join(t.TempDir(), "debug.har")
+	c := NewClient(server.URL, "device-id", "1.2.3", DisableRetries(), WithHARFile(harPath))
+
+	_, err := c.LoginWithAPIKey(t.Context(), "user.client-id", "client-secret")
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(harPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "client-secret")
+	assert.NotContains(t, string(content), "secret-access-token")
+	assert.NotContains(t, string(content), "protected-key")
+
+	var har harLog
+	require.NoError(t, json.Unmarshal(content, &har))
+	assert.Equal(t, "1.2", har.Log.Version)
+	assert.Equal(t, harCreator{Name: "terraform-provider-bitwarden", Version: "1.2.3"}, har.Log.Creator)
+	require.Len(t, har.Log.Entries, 1)
+
+	entry := har.Log.Entries[0]
+	assert.Equal(t, http.MethodPost, entry.Request.Method)
+	assert.Equal(t, server.URL+"/identity/connect/token", entry.Request.URL)
+	require.NotNil(t, entry.Request.PostData)
+	assert.Contains(t, entry.Request.PostData.Text, "client_id=user.client-id")
+	assert.Contains(t, entry.Request.PostData.Text, "client_secret=%5BREDACTED%5D")
+	assert.Equal(t, http.StatusOK, entry.Response.Status)
+	assert.JSONEq(t, `{"access_token":"[REDACTED]","expires_in":3600,"token_type":"Bearer","Key":"[REDACTED]"}`, entry.Response.Content.Text)
+}
+
+func TestWithHARFileAppendsExchanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/sso-connector?code=secret-code&state=1")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	harPath := filepath.Join(t.TempDir(), "debug.har")
+	recorder := &harRecorder{path: harPath, version: "1.2.3"}
+	for i := 0; i < 3; i++ {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		recorder.record(req, nil, resp, nil, time.Now(), nil)
+		resp.Body.Close()
+
+		content, err := os.ReadFile(harPath)
+		require.NoError(t, err)
+		assert.NotContains(t, string(content), "secret-code")
+
+		var har harLog
+		require.NoError(t, json.Unmarshal(content, &har), "the file must be complete after each exchange")
+		require.Len(t, har.Log.Entries, i+1)
+		assert.Equal(t, "/sso-connector?code=%5BREDACTED%5D&state=1", har.Log.Entries[i].Response.RedirectURL)
+	}
+}
@@ -1,6 +1,12 @@
 package webapi
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+)
 
 type HTTPError struct {
 	StatusCode int
@@ -15,6 +21,41 @@ func (e *HTTPError) GetStatusCode() int {
 	return e.StatusCode
 }
 
+// Is classifies the error by status code, e.g. for
+// errors.Is(err, models.ErrorKindNotFound).
+func (e *HTTPError) Is(target error) bool {
+	kind, ok := target.(models.ErrorKind)
+	return ok && e.kind() == kind
+}
+
+func (e *HTTPError) kind() models.ErrorKind {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return models.ErrorKindAuthFailure
+	case http.StatusBadRequest:
+		// The identity server rejects wrong credentials with a 400.
+		if strings.Contains(e.Message, "invalid_grant") || strings.Contains(e.Message, "invalid_client") {
+			return models.ErrorKindAuthFailure
+		}
+		// Editions sent with an outdated 'lastKnownRevisionDate' are rejected
+		// with a 400 too.
+		if strings.Contains(e.Message, "out of date") {
+			return models.ErrorKindConflict
+		}
+	case http.StatusForbidden:
+		return models.ErrorKindPermissionDenied
+	case http.StatusNotFound:
+		return models.ErrorKindNotFound
+	case http.StatusConflict:
+		return models.ErrorKindConflict
+	case http.StatusTooManyRequests:
+		return models.ErrorKindRateLimited
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return models.ErrorKindTransportFailure
+	}
+	return ""
+}
+
 func IsHTTPError(err error) (*HTTPError, bool) {
 	var httpErr *HTTPError
 	if errors.As(err, &httpErr) {
@@ -0,0 +1,37 @@
+//go:build offline
+
+package webapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPErrorKinds(t *testing.T) {
+	for statusCode, kind := range map[int]models.ErrorKind{
+		http.StatusUnauthorized:       models.ErrorKindAuthFailure,
+		http.StatusForbidden:          models.ErrorKindPermissionDenied,
+		http.StatusNotFound:           models.ErrorKindNotFound,
+		http.StatusConflict:           models.ErrorKindConflict,
+		http.StatusTooManyRequests:    models.ErrorKindRateLimited,
+		http.StatusServiceUnavailable: models.ErrorKindTransportFailure,
+	} {
+		err := fmt.Errorf("wrapped: %w", &HTTPError{StatusCode: statusCode, Message: "error"})
+		assert.ErrorIs(t, err, kind, statusCode)
+	}
+
+	err := &HTTPError{StatusCode: http.StatusBadRequest, Message: `bad response status code: 400!=200, body:{"error":"invalid_grant"}`}
+	assert.ErrorIs(t, err, models.ErrorKindAuthFailure)
+
+	err = &HTTPError{StatusCode: http.StatusBadRequest, Message: `bad response status code: 400!=200, body:{"message":"The cipher you are updating is out of date. Please save your work, sync your vault, and try again."}`}
+	assert.ErrorIs(t, err, models.ErrorKindConflict)
+
+	_, ok := models.ErrorKindOf(&HTTPError{StatusCode: http.StatusInternalServerError, Message: "error"})
+	assert.False(t, ok)
+	assert.False(t, errors.Is(&HTTPError{StatusCode: http.StatusNotFound}, models.ErrObjectNotFound))
+}
@@ -2,6 +2,7 @@ package webapi
 
 import (
 	"crypto/rsa"
+	"encoding/json"
 	"time"
 
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
@@ -30,6 +31,21 @@ type AttachmentRequestData struct {
 	FileSize int    `json:"fileSize"`
 }
 
+// EditItemRequest is the body of an item edition. The server ignores the keys
+// and file names of attachments sent in 'attachments', and only updates them
+// from 'attachments2'. It rejects the edition as out of date when
+// 'lastKnownRevisionDate' is older than the item's revision.
+type EditItemRequest struct {
+	models.Item
+	Attachments2          map[string]AttachmentUpdate `json:"attachments2,omitempty"`
+	LastKnownRevisionDate *time.Time                  `json:"lastKnownRevisionDate,omitempty"`
+}
+
+type AttachmentUpdate struct {
+	FileName string `json:"fileName"`
+	Key      string `json:"key"`
+}
+
 type CreateObjectAttachmentResponse struct {
 	AttachmentId   string                `json:"attachmentId"`
 	CipherResponse models.Item           `json:"cipherResponse"`
@@ -315,9 +331,27 @@ type ErrorResponse struct {
 
 type ConfigResponse struct {
 	FeatureStates ConfigFeatureStates `json:"featureStates"`
+	Server        *ConfigServer       `json:"server"`
 	Version       string              `json:"version"`
 }
 
+// ConfigServer is only set by third-party servers like Vaultwarden.
+type ConfigServer struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// ConfigFeatureStates has a field for the feature flags the clients rely on,
+// while All keeps every flag the server returned.
 type ConfigFeatureStates struct {
-	CipherKeyEncryption bool `json:"cipher-key-encryption"`
+	CipherKeyEncryption bool                   `json:"cipher-key-encryption"`
+	All                 map[string]interface{} `json:"-"`
+}
+
+func (f *ConfigFeatureStates) UnmarshalJSON(data []byte) error {
+	type featureStates ConfigFeatureStates
+	if err := json.Unmarshal(data, (*featureStates)(f)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &f.All)
 }
@@ -1,12 +1,21 @@
 package webapi
 
-import "net/http"
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+)
 
 type Options func(c Client)
 
 func DisableRetries() Options {
 	return func(c Client) {
-		roundTripper, ok := c.(*client).httpClient.Transport.(*RetryRoundTripper)
+		roundTripper, ok := retryRoundTripper(c)
 		if !ok {
 			return
 		}
@@ -14,8 +23,153 @@ func DisableRetries() Options {
 	}
 }
 
+// WithMaxConcurrentRequests limits how many requests are sent to the server
+// at the same time.
+func WithMaxConcurrentRequests(maxConcurrentRequests int) Options {
+	return func(c Client) {
+		roundTripper, ok := retryRoundTripper(c)
+		if !ok {
+			return
+		}
+		roundTripper.concurrentRequestsSem = semaphore.NewWeighted(int64(maxConcurrentRequests))
+	}
+}
+
+// WithMaxRetries sets the maximum number of retries for low-level errors and
+// retryable status codes. Rate limited requests (429) are retried regardless.
+func WithMaxRetries(maxRetries int) Options {
+	return func(c Client) {
+		roundTripper, ok := retryRoundTripper(c)
+		if !ok {
+			return
+		}
+		// The round tripper gives up after maxRetries-1 attempts, which
+		// keeps the default of maxRetryAttempts unchanged.
+		roundTripper.maxRetries = maxRetries + 2
+	}
+}
+
+// WithAttemptTimeout sets the overall timeout of a single request attempt.
+func WithAttemptTimeout(attemptTimeout time.Duration) Options {
+	return func(c Client) {
+		roundTripper, ok := retryRoundTripper(c)
+		if !ok {
+			return
+		}
+		roundTripper.attemptTimeout = attemptTimeout
+	}
+}
+
+// WithDialTimeout sets the timeout for DNS resolution and TCP connection.
+func WithDialTimeout(dialTimeout time.Duration) Options {
+	return func(c Client) {
+		transport, ok := baseTransport(c)
+		if !ok {
+			return
+		}
+		transport.DialContext = (&net.Dialer{Timeout: dialTimeout}).DialContext
+	}
+}
+
+// WithRetryBackoffFactor sets the base of the exponential backoff between
+// attempts, in seconds.
+func WithRetryBackoffFactor(backoffFactor float64) Options {
+	return func(c Client) {
+		roundTripper, ok := retryRoundTripper(c)
+		if !ok {
+			return
+		}
+		roundTripper.backoffFactor = backoffFactor
+	}
+}
+
+// WithRetryableStatusCodes replaces the list of status codes that are retried.
+func WithRetryableStatusCodes(statusCodes []int) Options {
+	return func(c Client) {
+		roundTripper, ok := retryRoundTripper(c)
+		if !ok {
+			return
+		}
+		roundTripper.retryableStatusCodes = statusCodes
+	}
+}
+
+// WithHARFile records every HTTP exchange in a HAR file, with secrets
+// redacted. Clients configured with the same path share the file.
+func WithHARFile(path string) Options {
+	return func(c Client) {
+		roundTripper, ok := retryRoundTripper(c)
+		if !ok {
+			return
+		}
+		roundTripper.har = harRecorderFor(path, c.(*client).device.deviceVersion)
+	}
+}
+
 func WithCustomClient(httpClient http.Client) Options {
 	return func(c Client) {
 		c.(*client).httpClient = &httpClient
 	}
 }
+
+// WithAPIURL overrides the base URL of the API endpoints, which defaults to
+// "<serverURL>/api".
+func WithAPIURL(apiURL string) Options {
+	return func(c Client) {
+		c.(*client).apiURL = strings.TrimSuffix(apiURL, "/")
+	}
+}
+
+// WithIdentityURL overrides the base URL of the identity endpoints, which
+// defaults to "<serverURL>/identity".
+func WithIdentityURL(identityURL string) Options {
+	return func(c Client) {
+		c.(*client).identityURL = strings.TrimSuffix(identityURL, "/")
+	}
+}
+
+// WithHeaders adds the given headers to every request sent to the Bitwarden
+// server, e.g. to authenticate against a proxy in front of it.
+func WithHeaders(headers map[string]string) Options {
+	return func(c Client) {
+		c.(*client).headers = headers
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used when connecting to servers,
+// e.g. to trust extra CAs, present a client certificate or pin the server key.
+func WithTLSConfig(tlsConfig *tls.Config) Options {
+	return func(c Client) {
+		transport, ok := baseTransport(c)
+		if !ok || tlsConfig == nil {
+			return
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+}
+
+// WithProxyURL sends all requests through the given proxy instead of the one
+// derived from the HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables.
+func WithProxyURL(proxyURL *url.URL) Options {
+	return func(c Client) {
+		transport, ok := baseTransport(c)
+		if !ok || proxyURL == nil {
+			return
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+}
+
+func retryRoundTripper(c Client) (*RetryRoundTripper, bool) {
+	roundTripper, ok := c.(*client).httpClient.Transport.(*RetryRoundTripper)
+	return roundTripper, ok
+}
+
+func baseTransport(c Client) (*http.Transport, bool) {
+	roundTripper, ok := retryRoundTripper(c)
+	if !ok {
+		return nil, false
+	}
+	transport, ok := roundTripper.Transport.(*http.Transport)
+	return transport, ok
+}
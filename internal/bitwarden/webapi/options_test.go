@@ -0,0 +1,75 @@
+//go:build offline
+
+package webapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHeaders_OnlySentToConfiguredServer(t *testing.T) {
+	var thirdPartyHeaders http.Header
+	thirdParty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		thirdPartyHeaders = r.Header.Clone()
+	}))
+	defer thirdParty.Close()
+
+	var serverHeaders http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverHeaders = r.Header.Clone()
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, thirdParty.URL, http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "device-id", "dev", DisableRetries(), WithHeaders(map[string]string{
+		"CF-Access-Client-Id":     "client-id",
+		"CF-Access-Client-Secret": "client-secret",
+	}))
+
+	_, err := c.GetContentFromURL(t.Context(), server.URL+"/attachment")
+	require.NoError(t, err)
+	assert.Equal(t, "client-id", serverHeaders.Get("CF-Access-Client-Id"))
+	assert.Equal(t, "client-secret", serverHeaders.Get("CF-Access-Client-Secret"))
+
+	_, err = c.GetContentFromURL(t.Context(), thirdParty.URL)
+	require.NoError(t, err)
+	assert.Empty(t, thirdPartyHeaders.Get("CF-Access-Client-Id"))
+	assert.Empty(t, thirdPartyHeaders.Get("CF-Access-Client-Secret"))
+
+	thirdPartyHeaders = nil
+	_, err = c.GetContentFromURL(t.Context(), server.URL+"/redirect")
+	require.NoError(t, err)
+	require.NotNil(t, thirdPartyHeaders)
+	assert.Empty(t, thirdPartyHeaders.Get("CF-Access-Client-Secret"))
+}
+
+func TestWithAPIAndIdentityURL(t *testing.T) {
+	var requestedPaths []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPaths = append(requestedPaths, r.Host+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	})
+	apiServer := httptest.NewServer(handler)
+	defer apiServer.Close()
+	identityServer := httptest.NewServer(handler)
+	defer identityServer.Close()
+
+	c := NewClient("http://127.0.0.1:1", "device-id", "dev", DisableRetries(), WithAPIURL(apiServer.URL+"/"), WithIdentityURL(identityServer.URL))
+
+	_, err := c.Config(t.Context())
+	require.NoError(t, err)
+	_, err = c.PreLogin(t.Context(), "test@laverse.net")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		apiServer.Listener.Addr().String() + "/config",
+		identityServer.Listener.Addr().String() + "/accounts/prelogin",
+	}, requestedPaths)
+}
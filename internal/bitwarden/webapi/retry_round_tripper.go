@@ -23,8 +23,11 @@ type RetryRoundTripper struct {
 	Transport      http.RoundTripper
 
 	concurrentRequestsSem *semaphore.Weighted
+	har                   *harRecorder
 	maxRetries            int
 	attemptTimeout        time.Duration
+	backoffFactor         float64
+	retryableStatusCodes  []int
 }
 
 // List of status codes that are considered retryable depending on the method.
@@ -53,6 +56,8 @@ func NewRetryRoundTripper(maxConcurrentRequests int, maxRetries int, attemptTime
 		concurrentRequestsSem: semaphore.NewWeighted(int64(maxConcurrentRequests)),
 		maxRetries:            maxRetries,
 		attemptTimeout:        attemptTimeout,
+		backoffFactor:         retryBackoffFactor,
+		retryableStatusCodes:  retryableStatusCodes,
 	}
 }
 
@@ -101,10 +106,16 @@ func (rrt *RetryRoundTripper) doRequest(originalCtx context.Context, httpReq *ht
 	reqCtx, cancel := context.WithTimeout(originalCtx, rrt.attemptTimeout)
 	defer cancel()
 
+	started := time.Now()
+	reqCtx, span := startAttemptSpan(reqCtx, httpReq, attemptNumber)
 	resp, err := rrt.Transport.RoundTrip(httpReq.WithContext(reqCtx))
+	endAttemptSpan(span, resp, err)
+	if rrt.har != nil {
+		rrt.har.recordAttempt(httpReq, resp, err, started)
+	}
 
 	// A request is successful if there's no error, we got a response, and the status code is not retryable
-	isSuccessful := err == nil && resp != nil && !isRetriableStatusCode(httpReq.Method, resp.StatusCode)
+	isSuccessful := err == nil && resp != nil && !isRetriableStatusCode(rrt.retryableStatusCodes, httpReq.Method, resp.StatusCode)
 
 	// Success: return response, no retry.
 	if isSuccessful {
@@ -116,7 +127,7 @@ func (rrt *RetryRoundTripper) doRequest(originalCtx context.Context, httpReq *ht
 	is429 := resp != nil && resp.StatusCode == http.StatusTooManyRequests
 	// 429 is retried indefinitely (rate limits eventually reset). Other retryable cases use maxRetries.
 	isLastPossibleAttempt := (attemptNumber >= rrt.maxRetries-1 || rrt.DisableRetries) && !is429
-	reasons := retriableReasons(err, httpReq, resp)
+	reasons := retriableReasons(rrt.retryableStatusCodes, err, httpReq, resp)
 	isRetriable := len(reasons) > 0
 	giveUp := isLastPossibleAttempt || !isRetriable
 
@@ -137,7 +148,7 @@ func (rrt *RetryRoundTripper) doRequest(originalCtx context.Context, httpReq *ht
 		} else if readErr != nil {
 			err = readErr
 		}
-		if attemptNumber > 1 {
+		if err != nil && attemptNumber > 1 {
 			err = fmt.Errorf("%w (after %d attempts)", err, attemptNumber)
 		}
 		return resp, false, err
@@ -155,7 +166,7 @@ func (rrt *RetryRoundTripper) doRequest(originalCtx context.Context, httpReq *ht
 		debugInfo["status_code"] = resp.StatusCode
 		debugInfo["status_message"] = resp.Status
 	}
-	waitDuration := backoff(attemptNumber)
+	waitDuration := backoff(rrt.backoffFactor, attemptNumber)
 	if is429 {
 		if d := tryToReadWaitDurationFromHeaders(resp); d > 0 {
 			waitDuration = d
@@ -259,9 +270,9 @@ func isRetriableForResponsePhase(httpReq *http.Request) bool {
 	return false
 }
 
-func backoff(attempt int) time.Duration {
+func backoff(backoffFactor float64, attempt int) time.Duration {
 	maxInterval := 30 * time.Second
-	delay := time.Duration(math.Pow(float64(retryBackoffFactor), float64(attempt))) * time.Second
+	delay := time.Duration(math.Pow(backoffFactor, float64(attempt))) * time.Second
 	if delay > maxInterval {
 		delay = maxInterval
 	}
@@ -295,10 +306,10 @@ func preserveResponseBody(resp *http.Response) error {
 }
 
 // isRetriableStatusCode determines if a status code is retryable based on the HTTP method.
-// - 4xx status codes: retryable for both GET and POST
-// - 5xx status codes: retryable for GET only if the code is in retryableStatusCodes
-func isRetriableStatusCode(method string, statusCode int) bool {
-	if !slices.Contains(retryableStatusCodes, statusCode) {
+// - 4xx status codes: retryable for both GET and POST if the code is in retryableCodes
+// - 5xx status codes: retryable for GET only if the code is in retryableCodes
+func isRetriableStatusCode(retryableCodes []int, method string, statusCode int) bool {
+	if !slices.Contains(retryableCodes, statusCode) {
 		return false
 	}
 
@@ -313,7 +324,7 @@ func isRetriableStatusCode(method string, statusCode int) bool {
 
 // retriableReasons returns the reasons why a request is retriable (e.g. dial_error, read_timeout).
 // Empty slice means the request is not retriable.
-func retriableReasons(err error, httpReq *http.Request, resp *http.Response) []string {
+func retriableReasons(retryableCodes []int, err error, httpReq *http.Request, resp *http.Response) []string {
 	var reasons []string
 	if err != nil && isDialError(err) {
 		reasons = append(reasons, "dial_error")
@@ -327,7 +338,7 @@ func retriableReasons(err error, httpReq *http.Request, resp *http.Response) []s
 	if isRetriableForResponsePhase(httpReq) && isResponseHeaderTimeout(err) {
 		reasons = append(reasons, "response_header_timeout")
 	}
-	if resp != nil && isRetriableStatusCode(httpReq.Method, resp.StatusCode) {
+	if resp != nil && isRetriableStatusCode(retryableCodes, httpReq.Method, resp.StatusCode) {
 		reasons = append(reasons, "retriable_http_status_code")
 	}
 	if isRetriableForResponsePhase(httpReq) && isContextTimeout(err) {
@@ -36,7 +36,7 @@ func TestRetryRoundTripper_Backoff(t *testing.T) {
 		10: 30 * time.Second,
 	}
 	for attempt, expected := range testData {
-		assert.Equal(t, expected, backoff(attempt))
+		assert.Equal(t, expected, backoff(retryBackoffFactor, attempt))
 	}
 }
 
@@ -217,6 +217,47 @@ func TestRetryRoundTripper_429RetriedIndefinitely(t *testing.T) {
 	assert.Equal(t, 4, transport.index, "should have retried 429 more than maxRetries times until success")
 }
 
+func TestRetryRoundTripper_MaxRetries(t *testing.T) {
+	lowerBackoffFactor()
+	defer lowerBackoffFactor()
+
+	testCases := map[string]struct {
+		opts             []Options
+		expectedAttempts int
+	}{
+		"default":   {expectedAttempts: 2},
+		"no-retry":  {opts: []Options{WithMaxRetries(0)}, expectedAttempts: 1},
+		"one-retry": {opts: []Options{WithMaxRetries(1)}, expectedAttempts: 2},
+		"3-retries": {opts: []Options{WithMaxRetries(3)}, expectedAttempts: 4},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			transport := &mockTransport{
+				responses: []*http.Response{
+					{StatusCode: http.StatusServiceUnavailable},
+					{StatusCode: http.StatusServiceUnavailable},
+					{StatusCode: http.StatusServiceUnavailable},
+					{StatusCode: http.StatusServiceUnavailable},
+					{StatusCode: http.StatusOK},
+				},
+				errors: []error{nil, nil, nil, nil, nil},
+			}
+
+			rrt, ok := retryRoundTripper(NewClient("http://example.com", "", "dev", tc.opts...))
+			require.True(t, ok)
+			rrt.Transport = transport
+
+			req, err := http.NewRequest("GET", "http://example.com", nil)
+			require.NoError(t, err)
+
+			resp, err := rrt.RoundTrip(req)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+			assert.Equal(t, tc.expectedAttempts, transport.index)
+		})
+	}
+}
+
 func TestRetryRoundTripper_4xxWithGETNotInRetryableList(t *testing.T) {
 	lowerBackoffFactor()
 	defer lowerBackoffFactor()
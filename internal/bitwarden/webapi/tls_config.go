@@ -0,0 +1,121 @@
+package webapi
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strings"
+)
+
+// TLSOptions describes how the embedded client establishes TLS connections.
+type TLSOptions struct {
+	// ExtraCACertsPath is a PEM bundle appended to the system root CAs.
+	ExtraCACertsPath string
+
+	// ClientCertPath and ClientKeyPath are a PEM certificate/key pair
+	// presented to servers requesting mutual TLS.
+	ClientCertPath string
+	ClientKeyPath  string
+
+	// PinnedHosts are the hostnames the SPKI pins apply to. Connections to
+	// other hosts (e.g. attachment storage) are not pinned.
+	PinnedHosts []string
+
+	// SPKIPins are base64-encoded SHA-256 digests of a SubjectPublicKeyInfo,
+	// optionally prefixed with "sha256/". At least one certificate of the
+	// verified chain must match one of the pins.
+	SPKIPins []string
+}
+
+// NewTLSConfig builds a tls.Config from the given options. It returns nil
+// when no option is set so that the default transport settings are kept.
+func NewTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	if len(opts.ExtraCACertsPath) == 0 && len(opts.ClientCertPath) == 0 && len(opts.ClientKeyPath) == 0 && len(opts.SPKIPins) == 0 {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(opts.ExtraCACertsPath) > 0 {
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil {
+			rootCAs = x509.NewCertPool()
+		}
+
+		pemCerts, err := os.ReadFile(opts.ExtraCACertsPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading extra CA certificates: %w", err)
+		}
+		if !rootCAs.AppendCertsFromPEM(pemCerts) {
+			return nil, fmt.Errorf("no valid PEM certificate found in '%s'", opts.ExtraCACertsPath)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if len(opts.ClientCertPath) > 0 || len(opts.ClientKeyPath) > 0 {
+		clientCert, err := tls.LoadX509KeyPair(opts.ClientCertPath, opts.ClientKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+
+	if len(opts.SPKIPins) > 0 {
+		pins := make([]string, 0, len(opts.SPKIPins))
+		for _, pin := range opts.SPKIPins {
+			pin = strings.TrimPrefix(strings.TrimPrefix(pin, "sha256/"), "/")
+			if _, err := base64.StdEncoding.DecodeString(pin); err != nil {
+				return nil, fmt.Errorf("invalid SPKI pin '%s': %w", pin, err)
+			}
+			pins = append(pins, pin)
+		}
+		tlsConfig.VerifyConnection = verifySPKIPins(opts.PinnedHosts, pins)
+	}
+
+	return tlsConfig, nil
+}
+
+func verifySPKIPins(pinnedHosts []string, pins []string) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if !isPinnedHost(cs.ServerName, pinnedHosts) {
+			return nil
+		}
+
+		for _, chain := range cs.VerifiedChains {
+			for _, cert := range chain {
+				if slices.Contains(pins, spkiFingerprint(cert)) {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("no certificate presented by '%s' matches the configured SPKI pins", cmp.Or(cs.ServerName, strings.Join(pinnedHosts, ", ")))
+	}
+}
+
+func isPinnedHost(serverName string, pinnedHosts []string) bool {
+	if len(pinnedHosts) == 0 {
+		return true
+	}
+	for _, pinnedHost := range pinnedHosts {
+		// No SNI is sent when connecting to an IP literal, in which case the
+		// server name is unknown at this point.
+		if len(serverName) == 0 && net.ParseIP(pinnedHost) != nil {
+			return true
+		}
+		if strings.EqualFold(serverName, pinnedHost) {
+			return true
+		}
+	}
+	return false
+}
+
+func spkiFingerprint(cert *x509.Certificate) string {
+	digest := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(digest[:])
+}
@@ -0,0 +1,84 @@
+//go:build offline
+
+package webapi
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTLSConfig_NoOptions(t *testing.T) {
+	tlsConfig, err := NewTLSConfig(TLSOptions{})
+	assert.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+}
+
+func TestNewTLSConfig_InvalidPin(t *testing.T) {
+	_, err := NewTLSConfig(TLSOptions{SPKIPins: []string{"not base64!"}})
+	assert.ErrorContains(t, err, "invalid SPKI pin")
+}
+
+func TestNewTLSConfig_ExtraCACertsAndPins(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caPath, caPEM, 0600))
+
+	srvURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	testData := map[string]struct {
+		pins          []string
+		expectedError string
+	}{
+		"trusted-ca": {},
+		"matching-pin": {
+			pins: []string{"sha256/" + spkiFingerprint(srv.Certificate())},
+		},
+		"mismatching-pin": {
+			pins:          []string{"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+			expectedError: "matches the configured SPKI pins",
+		},
+	}
+
+	for name, tt := range testData {
+		t.Run(name, func(t *testing.T) {
+			tlsConfig, err := NewTLSConfig(TLSOptions{
+				ExtraCACertsPath: caPath,
+				PinnedHosts:      []string{srvURL.Hostname()},
+				SPKIPins:         tt.pins,
+			})
+			require.NoError(t, err)
+
+			c := NewClient(srv.URL, "device-id", "dev", DisableRetries(), WithTLSConfig(tlsConfig))
+			body, err := c.GetContentFromURL(t.Context(), srv.URL)
+			if len(tt.expectedError) > 0 {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "ok", string(body))
+		})
+	}
+}
+
+func TestNewClient_UntrustedServerWithoutExtraCACerts(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "device-id", "dev", DisableRetries())
+	_, err := c.GetContentFromURL(t.Context(), srv.URL)
+	assert.ErrorContains(t, err, "certificate")
+}
@@ -0,0 +1,60 @@
+package webapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const attributeRetryAttempt = attribute.Key("bitwarden.retry.attempt")
+
+// uuidPattern matches the identifiers of the objects in the paths of the API,
+// which are left out of the URL templates.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// startAttemptSpan starts a span for one attempt of the retry round tripper
+// to send a request.
+func startAttemptSpan(ctx context.Context, httpReq *http.Request, attemptNumber int) (context.Context, trace.Span) {
+	template := urlTemplate(httpReq.URL.Path)
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(httpReq.Method),
+		semconv.URLTemplate(template),
+		semconv.ServerAddress(httpReq.URL.Hostname()),
+		attributeRetryAttempt.Int(attemptNumber),
+	}
+	if attemptNumber > 1 {
+		attrs = append(attrs, semconv.HTTPRequestResendCount(attemptNumber-1))
+	}
+	return tracing.Start(ctx, fmt.Sprintf("%s %s", httpReq.Method, template), attrs...)
+}
+
+// endAttemptSpan ends the span of an attempt, which fails when no response
+// was received or when the server returned an error.
+func endAttemptSpan(span trace.Span, resp *http.Response, err error) {
+	if resp != nil {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+		if err == nil && resp.StatusCode >= 400 {
+			err = fmt.Errorf("the server returned %d", resp.StatusCode)
+		}
+	}
+	tracing.End(span, err)
+}
+
+// urlTemplate replaces the object identifiers of a path with placeholders,
+// so that spans of requests to the same endpoint can be grouped.
+func urlTemplate(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if uuidPattern.MatchString(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,57 @@
+//go:build offline
+
+package webapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/tracing/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+func TestURLTemplate(t *testing.T) {
+	assert.Equal(t, "/api/ciphers/{id}/attachment/{id}", urlTemplate("/api/ciphers/2b6a2d06-a3b5-4c0b-9c0f-4a8b8a1f5e7e/attachment/0F4E8C25-6DB1-4E3B-8C5A-2C0B1F2E3D4A"))
+	assert.Equal(t, "/identity/connect/token", urlTemplate("/identity/connect/token"))
+	assert.Equal(t, "/api/ciphers/not-an-id", urlTemplate("/api/ciphers/not-an-id"))
+}
+
+func TestRetryRoundTripper_Spans(t *testing.T) {
+	lowerBackoffFactor()
+	defer lowerBackoffFactor()
+	recorder := test.UseRecorder(t)
+
+	rrt := NewRetryRoundTripper(1, 3, time.Second, time.Second, time.Second, time.Second)
+	rrt.Transport = &mockTransport{
+		responses: []*http.Response{
+			{StatusCode: http.StatusServiceUnavailable},
+			{StatusCode: http.StatusOK},
+		},
+		errors: []error{nil, nil},
+	}
+
+	req, err := http.NewRequest("GET", "http://example.com/api/folders/2b6a2d06-a3b5-4c0b-9c0f-4a8b8a1f5e7e", nil)
+	require.NoError(t, err)
+
+	_, err = rrt.RoundTrip(req)
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "GET /api/folders/{id}", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Contains(t, spans[0].Attributes(), semconv.URLTemplate("/api/folders/{id}"))
+	assert.Contains(t, spans[0].Attributes(), semconv.HTTPResponseStatusCode(http.StatusServiceUnavailable))
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("bitwarden.retry.attempt", 1))
+
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	assert.Contains(t, spans[1].Attributes(), semconv.HTTPResponseStatusCode(http.StatusOK))
+	assert.Contains(t, spans[1].Attributes(), semconv.HTTPRequestResendCount(1))
+	assert.Contains(t, spans[1].Attributes(), attribute.Int("bitwarden.retry.attempt", 2))
+}
@@ -3,12 +3,25 @@ package command
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/redact"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// waitDelay is how long to wait for the outputs of a cancelled command to be
+// closed, as processes it started might still hold them.
+const waitDelay = 5 * time.Second
+
 type NewFn func(binary string, args ...string) Command
 
 // New is only meant to be changed during tests.
@@ -44,8 +57,11 @@ func (c *command) WithStdin(dir string) Command {
 	return c
 }
 
-func (c *command) Run(ctx context.Context) ([]byte, error) {
-	ctx = tflog.SetField(ctx, "command", c.args)
+func (c *command) Run(ctx context.Context) (out []byte, err error) {
+	ctx, span := c.startSpan(ctx)
+	defer func() { tracing.End(span, err) }()
+
+	ctx = tflog.SetField(ctx, "command", redact.Args(c.args))
 	tflog.Debug(ctx, "Running command")
 
 	var stdOut, stdErr bytes.Buffer
@@ -54,15 +70,42 @@ func (c *command) Run(ctx context.Context) ([]byte, error) {
 	cmd.Stdin = c.stdin
 	cmd.Stdout = &stdOut
 	cmd.Stderr = &stdErr
+	cmd.WaitDelay = waitDelay
+	killProcessGroupOnCancel(cmd)
 
-	err := cmd.Run()
+	err = cmd.Run()
+	if cmd.ProcessState != nil {
+		span.SetAttributes(semconv.ProcessExitCode(cmd.ProcessState.ExitCode()))
+	}
 	if err != nil {
 		tflog.Error(ctx, "Command finished with error", map[string]interface{}{"error": err})
-		tflog.Trace(ctx, "Command outputs", map[string]interface{}{"stdout": stdOut.String(), "stderr": stdErr.String()})
+		tflog.Trace(ctx, "Command outputs", map[string]interface{}{"stdout": c.redactedOutput(stdOut.Bytes()), "stderr": stdErr.String()})
 		return nil, NewError(err, c.args, stdOut.String(), stdErr.String())
 	}
 	tflog.Debug(ctx, "Command finished with success")
-	tflog.Trace(ctx, "Command outputs", map[string]interface{}{"stdout": stdOut.String(), "stderr": stdErr.String()})
+	tflog.Trace(ctx, "Command outputs", map[string]interface{}{"stdout": c.redactedOutput(stdOut.Bytes()), "stderr": stdErr.String()})
 
 	return stdOut.Bytes(), nil
 }
+
+// startSpan starts a span named after the binary and its subcommand, like
+// "bw get", with the redacted arguments as an attribute.
+func (c *command) startSpan(ctx context.Context) (context.Context, trace.Span) {
+	name := strings.TrimSuffix(filepath.Base(c.binary), filepath.Ext(c.binary))
+	if len(c.args) > 0 && !strings.HasPrefix(c.args[0], "-") {
+		name = fmt.Sprintf("%s %s", name, c.args[0])
+	}
+	return tracing.Start(ctx, name,
+		semconv.ProcessExecutableName(filepath.Base(c.binary)),
+		semconv.ProcessCommandArgs(redact.Args(c.args)...),
+	)
+}
+
+// redactedOutput returns what a command printed without secrets. Commands
+// printing raw values, like session keys, have their output omitted entirely.
+func (c *command) redactedOutput(out []byte) string {
+	if slices.Contains(c.args, "--raw") || (len(c.args) > 0 && c.args[0] == "encode") {
+		return redact.Body("", out)
+	}
+	return redact.Text(string(out))
+}
@@ -3,6 +3,8 @@ package command
 import (
 	"fmt"
 	"strings"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/redact"
 )
 
 type CommandError struct {
@@ -22,7 +24,7 @@ func NewError(err error, args []string, stdout, stderr string) *CommandError {
 }
 
 func (c CommandError) Error() string {
-	return fmt.Sprintf("'%s' while running '%s': %v, %v", c.err, strings.Join(c.args, " "), c.stdout, c.stderr)
+	return fmt.Sprintf("'%s' while running '%s': %v, %v", c.err, strings.Join(redact.Args(c.args), " "), redact.Text(c.stdout), c.stderr)
 }
 
 func (c CommandError) Stderr() string {
@@ -0,0 +1,11 @@
+//go:build windows
+
+package command
+
+import (
+	"os/exec"
+)
+
+// killProcessGroupOnCancel keeps the default behavior on Windows, where
+// cancelled commands are killed without the processes they started.
+func killProcessGroupOnCancel(cmd *exec.Cmd) {}
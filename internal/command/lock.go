@@ -0,0 +1,47 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockPollInterval is how often a lock held by another process is retried.
+const lockPollInterval = 100 * time.Millisecond
+
+// lockFile locks a file, creating it if needed, and waits for other processes
+// holding it in a conflicting mode to release it. Locks are advisory, and
+// released when the process exits.
+func lockFile(ctx context.Context, path string, exclusive bool) (func() error, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		locked, err := tryLockFile(f, exclusive)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if locked {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	return func() error {
+		return errors.Join(unlockFile(f), f.Close())
+	}, nil
+}
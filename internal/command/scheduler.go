@@ -0,0 +1,231 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	DefaultMaxParallelReads  = 4
+	DefaultMaxParallelWrites = 1
+	DefaultCommandTimeout    = 10 * time.Minute
+)
+
+// Scheduler limits how many commands run at the same time and for how long.
+// Commands reading the data directory of a CLI run in parallel, while
+// commands writing to it never run alongside reads. When given a lock file,
+// the scheduler also holds it while commands run, shared between reads and
+// exclusive for writes, so that other processes using the same directory
+// don't run commands at the same time.
+type Scheduler struct {
+	maxReads  int
+	maxWrites int
+	timeout   time.Duration
+	lockPath  string
+
+	mu            sync.Mutex
+	changed       chan struct{}
+	reads         int
+	writes        int
+	waitingWrites int
+	locking       bool
+	unlock        func() error
+}
+
+type SchedulerOptions func(s *Scheduler)
+
+// WithMaxParallelReads sets how many reads can run at the same time.
+func WithMaxParallelReads(reads int) SchedulerOptions {
+	return func(s *Scheduler) {
+		s.maxReads = reads
+	}
+}
+
+// WithMaxParallelWrites sets how many writes can run at the same time.
+func WithMaxParallelWrites(writes int) SchedulerOptions {
+	return func(s *Scheduler) {
+		s.maxWrites = writes
+	}
+}
+
+// WithCommandTimeout sets how long a command can run before being killed.
+// Zero disables the timeout.
+func WithCommandTimeout(timeout time.Duration) SchedulerOptions {
+	return func(s *Scheduler) {
+		s.timeout = timeout
+	}
+}
+
+// WithLockFile sets the file locked while commands run.
+func WithLockFile(path string) SchedulerOptions {
+	return func(s *Scheduler) {
+		s.lockPath = path
+	}
+}
+
+func NewScheduler(opts ...SchedulerOptions) *Scheduler {
+	s := &Scheduler{
+		maxReads:  DefaultMaxParallelReads,
+		maxWrites: DefaultMaxParallelWrites,
+		timeout:   DefaultCommandTimeout,
+		changed:   make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// NewWithScheduler returns commands running through a scheduler, built with
+// newCommand. isWrite tells whether a command writes to the data directory.
+func NewWithScheduler(newCommand NewFn, scheduler *Scheduler, isWrite func(args []string) bool) NewFn {
+	return func(binary string, args ...string) Command {
+		return &scheduledCommand{
+			args:      args,
+			cmd:       newCommand(binary, args...),
+			scheduler: scheduler,
+			write:     isWrite(args),
+		}
+	}
+}
+
+type scheduledCommand struct {
+	args      []string
+	cmd       Command
+	scheduler *Scheduler
+	write     bool
+}
+
+func (c *scheduledCommand) AppendEnv(envs []string) Command {
+	c.cmd.AppendEnv(envs)
+	return c
+}
+
+func (c *scheduledCommand) WithStdin(dir string) Command {
+	c.cmd.WithStdin(dir)
+	return c
+}
+
+func (c *scheduledCommand) Run(ctx context.Context) ([]byte, error) {
+	return c.scheduler.Run(ctx, c.args, c.write, c.cmd.Run)
+}
+
+// Run waits for a slot, then runs a command within the timeout.
+func (s *Scheduler) Run(ctx context.Context, args []string, write bool, run func(context.Context) ([]byte, error)) ([]byte, error) {
+	if err := s.acquire(ctx, write); err != nil {
+		return nil, fmt.Errorf("error waiting to run '%s': %w", strings.Join(args, " "), err)
+	}
+	defer s.release(ctx, write)
+
+	if s.timeout <= 0 {
+		return run(ctx)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	out, err := run(runCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("'%s' didn't finish within %s: %w", strings.Join(args, " "), s.timeout, err)
+	}
+	return out, err
+}
+
+func (s *Scheduler) canStart(write bool) bool {
+	if s.locking {
+		return false
+	}
+	if write {
+		return s.reads == 0 && s.writes < s.maxWrites
+	}
+	// Waiting writes go first, so that they aren't starved by reads.
+	return s.writes == 0 && s.waitingWrites == 0 && s.reads < s.maxReads
+}
+
+func (s *Scheduler) acquire(ctx context.Context, write bool) error {
+	s.mu.Lock()
+	if write {
+		s.waitingWrites++
+	}
+	for !s.canStart(write) {
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			s.mu.Lock()
+			if write {
+				s.waitingWrites--
+				s.notifyLocked()
+			}
+			s.mu.Unlock()
+			return ctx.Err()
+		case <-changed:
+		}
+		s.mu.Lock()
+	}
+
+	first := s.reads == 0 && s.writes == 0
+	if write {
+		s.waitingWrites--
+		s.writes++
+	} else {
+		s.reads++
+	}
+	if !first || len(s.lockPath) == 0 {
+		s.mu.Unlock()
+		return nil
+	}
+
+	// Other commands wait until the first one of a batch got the lock file.
+	s.locking = true
+	s.mu.Unlock()
+
+	tflog.Trace(ctx, "Locking file", map[string]interface{}{"path": s.lockPath, "exclusive": write})
+	unlock, err := lockFile(ctx, s.lockPath, write)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locking = false
+	if err != nil {
+		if write {
+			s.writes--
+		} else {
+			s.reads--
+		}
+		s.notifyLocked()
+		return fmt.Errorf("error locking '%s': %w", s.lockPath, err)
+	}
+	s.unlock = unlock
+	s.notifyLocked()
+	return nil
+}
+
+func (s *Scheduler) release(ctx context.Context, write bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if write {
+		s.writes--
+	} else {
+		s.reads--
+	}
+	if s.reads == 0 && s.writes == 0 && s.unlock != nil {
+		if err := s.unlock(); err != nil {
+			tflog.Warn(ctx, "Unable to unlock file", map[string]interface{}{"path": s.lockPath, "error": err})
+		}
+		s.unlock = nil
+	}
+	s.notifyLocked()
+}
+
+func (s *Scheduler) notifyLocked() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
@@ -0,0 +1,139 @@
+//go:build offline
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerLimitsParallelism(t *testing.T) {
+	s := NewScheduler(WithMaxParallelReads(2), WithMaxParallelWrites(1))
+
+	var reads, writes, maxReads, maxWrites, readsDuringWrites atomic.Int32
+	track := func(running, max *atomic.Int32) func(context.Context) ([]byte, error) {
+		return func(context.Context) ([]byte, error) {
+			n := running.Add(1)
+			for {
+				m := max.Load()
+				if n <= m || max.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			if running == &writes && reads.Load() > 0 {
+				readsDuringWrites.Add(1)
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+			return nil, nil
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		write := i%4 == 0
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if write {
+				_, err = s.Run(t.Context(), []string{"edit"}, true, track(&writes, &maxWrites))
+			} else {
+				_, err = s.Run(t.Context(), []string{"get"}, false, track(&reads, &maxReads))
+			}
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), maxReads.Load())
+	assert.Equal(t, int32(1), maxWrites.Load())
+	assert.Zero(t, readsDuringWrites.Load())
+}
+
+func TestSchedulerGivesUpWaitingOnCancel(t *testing.T) {
+	s := NewScheduler()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_, _ = s.Run(t.Context(), []string{"edit"}, true, func(context.Context) ([]byte, error) {
+			close(started)
+			<-release
+			return nil, nil
+		})
+	}()
+	<-started
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	_, err := s.Run(ctx, []string{"get"}, false, func(context.Context) ([]byte, error) {
+		t.Fatal("read ran alongside a write")
+		return nil, nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "error waiting to run 'get'")
+}
+
+func TestSchedulerKillsCommandsAfterTimeout(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") == "1" {
+		time.Sleep(time.Minute)
+		return
+	}
+
+	s := NewScheduler(WithCommandTimeout(100 * time.Millisecond))
+	cmd := NewWithScheduler(New, s, func([]string) bool { return false })(os.Args[0], "-test.run=TestSchedulerKillsCommandsAfterTimeout")
+	cmd.AppendEnv([]string{"GO_WANT_HELPER_PROCESS=1"})
+
+	start := time.Now()
+	_, err := cmd.Run(t.Context())
+
+	assert.ErrorContains(t, err, "didn't finish within 100ms")
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestSchedulerLocksFileAcrossSchedulers(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "vault", "test.lock")
+	first := NewScheduler(WithLockFile(lockPath))
+	second := NewScheduler(WithLockFile(lockPath))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := first.Run(t.Context(), []string{"edit"}, true, func(context.Context) ([]byte, error) {
+			close(started)
+			<-release
+			return nil, nil
+		})
+		done <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(t.Context(), 300*time.Millisecond)
+	defer cancel()
+	_, err := second.Run(ctx, []string{"get"}, false, func(context.Context) ([]byte, error) {
+		return nil, fmt.Errorf("read ran while the file was locked for writing")
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "error locking")
+
+	close(release)
+	require.NoError(t, <-done)
+
+	out, err := second.Run(t.Context(), []string{"get"}, false, func(context.Context) ([]byte, error) {
+		return []byte("ok"), nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("ok"), out)
+}
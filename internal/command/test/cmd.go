@@ -45,11 +45,29 @@ func (c *testCommand) Run(_ context.Context) ([]byte, error) {
 		return []byte(v), nil
 	}
 	if v, ok := c.dummyOutput[strings.Join(append(c.args, "@error"), " ")]; ok {
-		return nil, fmt.Errorf("failing command '%s' for test purposes: %v", argsStr, v)
+		return nil, &failingCommandError{
+			message: fmt.Sprintf("failing command '%s' for test purposes: %v", argsStr, v),
+			err:     command.NewError(fmt.Errorf("test failure"), c.args, "", v),
+		}
 	}
 	return nil, fmt.Errorf("[unknown test command: '%s', '%s'", c.cmd, c.args)
 }
 
+// failingCommandError unwraps to the error a real command would have
+// returned, so that callers can read what the command printed on stderr.
+type failingCommandError struct {
+	message string
+	err     *command.CommandError
+}
+
+func (e *failingCommandError) Error() string {
+	return e.message
+}
+
+func (e *failingCommandError) Unwrap() error {
+	return e.err
+}
+
 func MockCommands(t *testing.T, dummyOutput map[string]string) (func(t *testing.T), func() []string) {
 	commandsExecuted := []string{}
 	newCommandToRestore := command.New
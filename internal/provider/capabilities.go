@@ -0,0 +1,223 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	goversion "github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/bwcli"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/webapi"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
+)
+
+const (
+	serverNameBitwarden   = "Bitwarden"
+	serverNameVaultwarden = "Vaultwarden"
+
+	// minCLIServeVersion is the first version of the Bitwarden CLI with
+	// `bw serve` listening on unix sockets.
+	minCLIServeVersion = "2024.2.0"
+)
+
+// Capabilities describes the Bitwarden Server and CLIs the provider talks to.
+// Versions are detected the first time a feature gate or the server info data
+// source needs them, and are empty when they couldn't be detected, in which
+// case all features are assumed to be supported.
+type Capabilities struct {
+	BWSVersion           string
+	CLIVersion           string
+	ClientImplementation string
+	FeatureFlags         map[string]string
+	ServerName           string
+	ServerURL            string
+	ServerVersion        string
+
+	detectOnce sync.Once
+	detect     func(ctx context.Context, c *Capabilities)
+}
+
+// feature is something resources rely on that not every backend supports.
+type feature struct {
+	name             string
+	minCLIVersion    string
+	minServerVersion string
+
+	// embeddedOnly is set for features the CLI implementations don't have.
+	embeddedOnly bool
+
+	// minVaultwardenVersion is compared against the Bitwarden version
+	// Vaultwarden reports to be compatible with.
+	minVaultwardenVersion string
+
+	// usedBy tells whether a resource relies on the feature. The feature is
+	// assumed to be used when it's nil.
+	usedBy func(d *schema.ResourceData) bool
+}
+
+// Older versions drop the key pair of SSH key items they don't know about.
+var featureSSHKeyItems = feature{
+	name:             "SSH key items",
+	minCLIVersion:    "2025.1.0",
+	minServerVersion: "2025.1.0",
+}
+
+// The CLIs don't send collection members, and older Vaultwarden releases
+// ignore the manage permission.
+var featureOrgCollectionMembers = feature{
+	name:                  "Collection members",
+	embeddedOnly:          true,
+	minVaultwardenVersion: "2024.6.0",
+	usedBy: func(d *schema.ResourceData) bool {
+		return d.Get(schema_definition.AttributeMember).(*schema.Set).Len() > 0 || d.Get(schema_definition.AttributeMemberGroup).(*schema.Set).Len() > 0
+	},
+}
+
+type versionedClient interface {
+	Version(ctx context.Context) (string, error)
+}
+
+// resolve detects the versions the first time they're needed.
+func (c *Capabilities) resolve(ctx context.Context) {
+	if c == nil || c.detect == nil {
+		return
+	}
+	c.detectOnce.Do(func() {
+		c.detect(ctx, c)
+	})
+}
+
+// require returns an error explaining why the backend doesn't support a
+// feature, or nil when it does or when it couldn't be told.
+func (c *Capabilities) require(ctx context.Context, f feature) error {
+	if c == nil {
+		return nil
+	}
+
+	usesCLI := c.ClientImplementation == schema_definition.ClientImplementationCLI || c.ClientImplementation == schema_definition.ClientImplementationCLIServe
+	if usesCLI && f.embeddedOnly {
+		return fmt.Errorf("%w: %s are only supported by the embedded client", errFeatureUnsupported, f.name)
+	}
+
+	c.resolve(ctx)
+	if usesCLI && len(f.minCLIVersion) > 0 && !versionAtLeast(c.CLIVersion, f.minCLIVersion) {
+		return fmt.Errorf("%w: %s requires the Bitwarden CLI %s or later, found %s", errFeatureUnsupported, f.name, f.minCLIVersion, c.CLIVersion)
+	}
+	if c.ServerName == serverNameBitwarden && len(f.minServerVersion) > 0 && !versionAtLeast(c.ServerVersion, f.minServerVersion) {
+		return fmt.Errorf("%w: %s requires Bitwarden Server %s or later, found %s", errFeatureUnsupported, f.name, f.minServerVersion, c.ServerVersion)
+	}
+	if c.ServerName == serverNameVaultwarden && len(f.minVaultwardenVersion) > 0 && !versionAtLeast(c.ServerVersion, f.minVaultwardenVersion) {
+		return fmt.Errorf("%w: %s requires a Vaultwarden release compatible with Bitwarden %s or later, found %s", errFeatureUnsupported, f.name, f.minVaultwardenVersion, c.ServerVersion)
+	}
+	return nil
+}
+
+// versionAtLeast returns true when version is at least minVersion, or when
+// version can't be parsed.
+func versionAtLeast(version, minVersion string) bool {
+	v, err := goversion.NewVersion(version)
+	if err != nil {
+		return true
+	}
+	return v.GreaterThanOrEqual(goversion.Must(goversion.NewVersion(minVersion)))
+}
+
+// routeAroundIncompatibilities adjusts the configuration for known
+// incompatibilities of the installed CLIs. It returns the version of the
+// Bitwarden CLI when it had to detect it.
+func routeAroundIncompatibilities(ctx context.Context, version string, cfg providerConfig) (providerConfig, string) {
+	if strings.Contains(version, versionTestSkippedLogin) || getClientImplementation(cfg) != schema_definition.ClientImplementationCLIServe {
+		return cfg, ""
+	}
+
+	// Node listens on named pipes instead of unix sockets on Windows.
+	if runtime.GOOS == "windows" {
+		tflog.Warn(ctx, "'bw serve' can't listen on a unix socket on Windows, running one command per operation instead.")
+		cfg.ClientImplementation = schema_definition.ClientImplementationCLI
+		return cfg, ""
+	}
+
+	cliVersion, err := bwcli.NewPasswordManagerClient().Version(ctx)
+	if err != nil {
+		tflog.Warn(ctx, "Unable to detect the version of the Bitwarden CLI", map[string]interface{}{"error": err})
+		return cfg, ""
+	}
+	if !versionAtLeast(cliVersion, minCLIServeVersion) {
+		tflog.Warn(ctx, fmt.Sprintf("The Bitwarden CLI %s can't run 'bw serve' on a unix socket (%s or later), running one command per operation instead.", cliVersion, minCLIServeVersion))
+		cfg.ClientImplementation = schema_definition.ClientImplementationCLI
+	}
+	return cfg, cliVersion
+}
+
+// detectCapabilities prepares asking the CLIs and the Bitwarden Server for
+// their versions, which only happens once they're needed. cliVersion is
+// reused when the version of the Bitwarden CLI is already known. Failures
+// aren't fatal, as the provider works without knowing the versions.
+func detectCapabilities(ctx context.Context, version string, cfg providerConfig, clients *ProviderClients, cliVersion string) *Capabilities {
+	clientImplementation := getClientImplementation(cfg)
+	capabilities := &Capabilities{
+		ClientImplementation: clientImplementation,
+		FeatureFlags:         map[string]string{},
+	}
+
+	// Those implementations never talk to a server.
+	if clientImplementation == schema_definition.ClientImplementationExportFile || clientImplementation == schema_definition.ClientImplementationMemory {
+		return capabilities
+	}
+	capabilities.ServerURL = cfg.Server
+	if strings.Contains(version, versionTestSkippedLogin) {
+		return capabilities
+	}
+
+	capabilities.detect = func(ctx context.Context, capabilities *Capabilities) {
+		if err := detectServerCapabilities(ctx, version, cfg, capabilities); err != nil {
+			tflog.Warn(ctx, "Unable to detect the capabilities of the Bitwarden Server", map[string]interface{}{"error": err})
+		}
+
+		if clientImplementation != schema_definition.ClientImplementationCLI && clientImplementation != schema_definition.ClientImplementationCLIServe {
+			return
+		}
+		capabilities.CLIVersion = cliVersion
+		if bwClient, ok := clients.PasswordManager.(versionedClient); ok && len(cliVersion) == 0 {
+			detectedVersion, err := bwClient.Version(ctx)
+			if err != nil {
+				tflog.Warn(ctx, "Unable to detect the version of the Bitwarden CLI", map[string]interface{}{"error": err})
+			}
+			capabilities.CLIVersion = detectedVersion
+		}
+		if bwsClient, ok := clients.SecretsManager.(versionedClient); ok {
+			bwsVersion, err := bwsClient.Version(ctx)
+			if err != nil {
+				tflog.Warn(ctx, "Unable to detect the version of the Secrets Manager CLI", map[string]interface{}{"error": err})
+			}
+			capabilities.BWSVersion = bwsVersion
+		}
+	}
+	return capabilities
+}
+
+func detectServerCapabilities(ctx context.Context, version string, cfg providerConfig, capabilities *Capabilities) error {
+	webapiOpts, err := buildWebapiOptions(cfg, version)
+	if err != nil {
+		return err
+	}
+
+	config, err := webapi.NewClient(cfg.Server, "", version, webapiOpts...).Config(ctx)
+	if err != nil {
+		return err
+	}
+
+	capabilities.ServerVersion = config.Version
+	capabilities.ServerName = serverNameBitwarden
+	if config.Server != nil && len(config.Server.Name) > 0 {
+		capabilities.ServerName = config.Server.Name
+	}
+	for name, value := range config.FeatureStates.All {
+		capabilities.FeatureFlags[name] = fmt.Sprint(value)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+//go:build offline
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/fakeserver"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectCapabilities(t *testing.T) {
+	var configRequests atomic.Int32
+	server := fakeserver.New(fakeserver.WithCipherKeyEncryption(false))
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/config") {
+			configRequests.Add(1)
+		}
+		server.ServeHTTP(w, r)
+	}))
+	defer httpServer.Close()
+
+	cfg := providerConfig{Server: httpServer.URL, ClientImplementation: schema_definition.ClientImplementationEmbedded}
+	capabilities := detectCapabilities(t.Context(), versionTestDisabledRetries, cfg, &ProviderClients{}, "")
+
+	// The server is only asked once its version is needed.
+	assert.Equal(t, int32(0), configRequests.Load())
+	capabilities.resolve(t.Context())
+	capabilities.resolve(t.Context())
+	assert.Equal(t, int32(1), configRequests.Load())
+
+	assert.Equal(t, schema_definition.ClientImplementationEmbedded, capabilities.ClientImplementation)
+	assert.Equal(t, httpServer.URL, capabilities.ServerURL)
+	assert.Equal(t, serverNameBitwarden, capabilities.ServerName)
+	assert.Equal(t, fakeserver.Version, capabilities.ServerVersion)
+	assert.Equal(t, "false", capabilities.FeatureFlags["cipher-key-encryption"])
+	assert.Empty(t, capabilities.CLIVersion)
+
+	// Unreachable servers aren't fatal.
+	cfg.Server = "http://127.0.0.1:1"
+	capabilities = detectCapabilities(t.Context(), versionTestDisabledRetries, cfg, &ProviderClients{}, "")
+	assert.NoError(t, capabilities.require(t.Context(), featureSSHKeyItems))
+	assert.Empty(t, capabilities.ServerVersion)
+}
+
+type countingVersionClient struct {
+	bitwarden.PasswordManager
+	calls int
+}
+
+func (c *countingVersionClient) Version(_ context.Context) (string, error) {
+	c.calls++
+	return "2024.12.0", nil
+}
+
+func TestDetectCapabilitiesReusesCLIVersion(t *testing.T) {
+	cfg := providerConfig{Server: "http://127.0.0.1:1", ClientImplementation: schema_definition.ClientImplementationCLIServe}
+
+	bwClient := &countingVersionClient{}
+	capabilities := detectCapabilities(t.Context(), versionTestDisabledRetries, cfg, &ProviderClients{PasswordManager: bwClient}, "2025.2.0")
+	require.NoError(t, capabilities.require(t.Context(), featureSSHKeyItems))
+	assert.Equal(t, "2025.2.0", capabilities.CLIVersion)
+	assert.Zero(t, bwClient.calls)
+
+	// The CLI is asked when its version isn't known yet.
+	capabilities = detectCapabilities(t.Context(), versionTestDisabledRetries, cfg, &ProviderClients{PasswordManager: bwClient}, "")
+	assert.ErrorIs(t, capabilities.require(t.Context(), featureSSHKeyItems), errFeatureUnsupported)
+	assert.Equal(t, 1, bwClient.calls)
+}
+
+func TestCapabilitiesRequire(t *testing.T) {
+	ctx := t.Context()
+
+	var unknown *Capabilities
+	require.NoError(t, unknown.require(ctx, featureSSHKeyItems))
+
+	oldCLI := &Capabilities{ClientImplementation: schema_definition.ClientImplementationCLI, CLIVersion: "2024.12.0"}
+	assert.ErrorIs(t, oldCLI.require(ctx, featureSSHKeyItems), errFeatureUnsupported)
+	assert.ErrorContains(t, oldCLI.require(ctx, featureSSHKeyItems), "requires the Bitwarden CLI 2025.1.0 or later, found 2024.12.0")
+
+	// The CLI version doesn't matter to the embedded client.
+	embedded := &Capabilities{ClientImplementation: schema_definition.ClientImplementationEmbedded, CLIVersion: "2024.12.0"}
+	assert.NoError(t, embedded.require(ctx, featureSSHKeyItems))
+
+	oldServer := &Capabilities{ClientImplementation: schema_definition.ClientImplementationEmbedded, ServerName: serverNameBitwarden, ServerVersion: "2024.6.2"}
+	assert.ErrorIs(t, oldServer.require(ctx, featureSSHKeyItems), errFeatureUnsupported)
+
+	// Third-party servers have their own versioning.
+	vaultwarden := &Capabilities{ClientImplementation: schema_definition.ClientImplementationEmbedded, ServerName: serverNameVaultwarden, ServerVersion: "2024.2.0"}
+	assert.NoError(t, vaultwarden.require(ctx, featureSSHKeyItems))
+}
+
+func TestCapabilitiesRequireOrgCollectionMembers(t *testing.T) {
+	ctx := t.Context()
+
+	for _, clientImplementation := range []string{schema_definition.ClientImplementationCLI, schema_definition.ClientImplementationCLIServe} {
+		cli := &Capabilities{ClientImplementation: clientImplementation, CLIVersion: "2025.2.0"}
+		assert.ErrorIs(t, cli.require(ctx, featureOrgCollectionMembers), errFeatureUnsupported)
+		assert.ErrorContains(t, cli.require(ctx, featureOrgCollectionMembers), "Collection members are only supported by the embedded client")
+	}
+
+	embedded := &Capabilities{ClientImplementation: schema_definition.ClientImplementationEmbedded, ServerName: serverNameBitwarden, ServerVersion: "2024.2.0"}
+	assert.NoError(t, embedded.require(ctx, featureOrgCollectionMembers))
+
+	oldVaultwarden := &Capabilities{ClientImplementation: schema_definition.ClientImplementationEmbedded, ServerName: serverNameVaultwarden, ServerVersion: "2024.2.0"}
+	assert.ErrorContains(t, oldVaultwarden.require(ctx, featureOrgCollectionMembers), "requires a Vaultwarden release compatible with Bitwarden 2024.6.0 or later, found 2024.2.0")
+
+	vaultwarden := &Capabilities{ClientImplementation: schema_definition.ClientImplementationEmbedded, ServerName: serverNameVaultwarden, ServerVersion: "2025.1.0"}
+	assert.NoError(t, vaultwarden.require(ctx, featureOrgCollectionMembers))
+}
@@ -5,11 +5,16 @@ import (
 )
 
 // ProviderClients holds the Bitwarden clients created during Configure.
-// Exactly one of PasswordManager or SecretsManager is typically set, depending
-// on the credentials supplied to the provider.
+// PasswordManager and/or SecretsManager are set, depending on the credentials
+// supplied to the provider. Capabilities describes what they talk to.
+// DeletionProtection is the default of the resources' deletion_protection,
+// ConflictPolicy tells whether updates overwrite changes made in the Vault.
 type ProviderClients struct {
-	PasswordManager bitwarden.PasswordManager
-	SecretsManager  bitwarden.SecretsManager
+	Capabilities       *Capabilities
+	PasswordManager    bitwarden.PasswordManager
+	SecretsManager     bitwarden.SecretsManager
+	DeletionProtection bool
+	ConflictPolicy     string
 }
 
 func (c *ProviderClients) RequirePasswordManager() (bitwarden.PasswordManager, error) {
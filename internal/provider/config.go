@@ -3,18 +3,28 @@ package provider
 import (
 	"context"
 	"fmt"
+	"maps"
+	"math/big"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/audit"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/bwcli"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/bwscli"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/embedded"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/readonly"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/webapi"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
 )
@@ -41,17 +51,105 @@ const (
 // VaultPath is the exception: omitted vs explicit empty vs a path are distinct.
 type providerConfig struct {
 	Server                                        string
+	Region                                        string
+	APIURL                                        string
+	IdentityURL                                   string
+	EventsURL                                     string
 	Email                                         string
 	MasterPassword                                string
 	SessionKey                                    string
+	UserKey                                       string
 	ClientID                                      string
 	ClientSecret                                  string
 	AccessToken                                   string
 	VaultPath                                     vaultPath
 	ExtraCACertsPath                              string
+	ClientCertPath                                string
+	ClientKeyPath                                 string
+	ProxyURL                                      string
+	DebugHARPath                                  string
+	AuditLogPath                                  string
+	ReadOnly                                      bool
+	DeletionProtection                            bool
+	ConflictPolicy                                string
+	ServerSPKIPins                                []string
+	HTTPHeaders                                   map[string]string
+	HTTP                                          httpConfig
+	CLI                                           cliConfig
 	ClientImplementation                          string
+	ExportFile                                    string
+	ExportPassword                                string
+	MemoryFixture                                 string
 	ExperimentalEmbeddedClient                    bool
 	ExperimentalDisableSyncAfterWriteVerification bool
+	ExperimentalMigrateToItemKeys                 bool
+	ExperimentalCLIListCache                      bool
+}
+
+// httpConfig is the http block. Nil pointers and empty strings mean "not set",
+// in which case the client defaults apply.
+type httpConfig struct {
+	MaxConcurrentRequests *int64
+	MaxRetries            *int64
+	AttemptTimeout        string
+	DialTimeout           string
+	RetryBackoffFactor    *float64
+	RetryOn429            *bool
+	RetryOn500            *bool
+	RetryOn503            *bool
+}
+
+func (h httpConfig) cacheKey() string {
+	return strings.Join([]string{
+		pointerCacheKey(h.MaxConcurrentRequests),
+		pointerCacheKey(h.MaxRetries),
+		h.AttemptTimeout,
+		h.DialTimeout,
+		pointerCacheKey(h.RetryBackoffFactor),
+		pointerCacheKey(h.RetryOn429),
+		pointerCacheKey(h.RetryOn500),
+		pointerCacheKey(h.RetryOn503),
+	}, ",")
+}
+
+// retryableStatusCodes returns the status codes to retry, or nil when no
+// toggle is set and the client defaults apply.
+func (h httpConfig) retryableStatusCodes() []int {
+	if h.RetryOn429 == nil && h.RetryOn500 == nil && h.RetryOn503 == nil {
+		return nil
+	}
+
+	statusCodes := []int{}
+	for statusCode, enabled := range map[int]*bool{429: h.RetryOn429, 500: h.RetryOn500, 503: h.RetryOn503} {
+		if enabled == nil || *enabled {
+			statusCodes = append(statusCodes, statusCode)
+		}
+	}
+	slices.Sort(statusCodes)
+	return statusCodes
+}
+
+// cliConfig is the cli block. Nil pointers and empty strings mean "not set",
+// in which case the client defaults apply.
+type cliConfig struct {
+	MaxParallelReads  *int64
+	MaxParallelWrites *int64
+	CommandTimeout    string
+}
+
+func (c cliConfig) cacheKey() string {
+	return strings.Join([]string{
+		pointerCacheKey(c.MaxParallelReads),
+		pointerCacheKey(c.MaxParallelWrites),
+		c.CommandTimeout,
+	}, ",")
+}
+
+func pointerCacheKey[T any](v *T) string {
+	if v == nil {
+		return "\x00<unset>"
+	}
+	return fmt.Sprintf("%v", *v)
 }
 
 // vaultPath is vault_path after Configure decoding.
@@ -88,20 +186,50 @@ func (c providerConfig) cacheKey(version string) string {
 	return strings.Join([]string{
 		version,
 		c.Server,
+		c.Region,
+		c.APIURL,
+		c.IdentityURL,
+		c.EventsURL,
 		c.Email,
 		c.MasterPassword,
 		c.SessionKey,
+		c.UserKey,
 		c.ClientID,
 		c.ClientSecret,
 		c.AccessToken,
 		c.VaultPath.cacheKey(),
 		c.ExtraCACertsPath,
+		c.ClientCertPath,
+		c.ClientKeyPath,
+		c.ProxyURL,
+		c.DebugHARPath,
+		c.AuditLogPath,
+		fmt.Sprintf("%t", c.ReadOnly),
+		fmt.Sprintf("%t", c.DeletionProtection),
+		c.ConflictPolicy,
+		strings.Join(c.ServerSPKIPins, ","),
+		stringMapCacheKey(c.HTTPHeaders),
+		c.HTTP.cacheKey(),
+		c.CLI.cacheKey(),
 		c.ClientImplementation,
+		c.ExportFile,
+		c.ExportPassword,
+		c.MemoryFixture,
 		fmt.Sprintf("%t", c.ExperimentalEmbeddedClient),
 		fmt.Sprintf("%t", c.ExperimentalDisableSyncAfterWriteVerification),
+		fmt.Sprintf("%t", c.ExperimentalMigrateToItemKeys),
+		fmt.Sprintf("%t", c.ExperimentalCLIListCache),
 	}, "\x00")
 }
 
+func stringMapCacheKey(m map[string]string) string {
+	keys := slices.Sorted(maps.Keys(m))
+	for i, k := range keys {
+		keys[i] = fmt.Sprintf("%s=%s", k, m[k])
+	}
+	return strings.Join(keys, ",")
+}
+
 var (
 	muxClientsMu    sync.Mutex
 	muxClientsOffer = map[string]*ProviderClients{}
@@ -153,7 +281,7 @@ func providerConfigureSDK(version string) func(context.Context, *schema.Resource
 		// we do not log in twice for the same ConfigureProvider RPC.
 		clients, err := configureClientsTakeOrCreate(ctx, version, cfg)
 		if err != nil {
-			return nil, diag.FromErr(err)
+			return nil, diagFromErr(err)
 		}
 		return clients, nil
 	}
@@ -162,15 +290,35 @@ func providerConfigureSDK(version string) func(context.Context, *schema.Resource
 func providerConfigFromResourceData(d *schema.ResourceData) providerConfig {
 	cfg := providerConfig{
 		Server:               stringFromResourceData(d, schema_definition.AttributeServer),
+		Region:               stringFromResourceData(d, schema_definition.AttributeRegion),
+		APIURL:               stringFromResourceData(d, schema_definition.AttributeAPIURL),
+		IdentityURL:          stringFromResourceData(d, schema_definition.AttributeIdentityURL),
+		EventsURL:            stringFromResourceData(d, schema_definition.AttributeEventsURL),
 		Email:                stringFromResourceData(d, schema_definition.AttributeProviderEmail),
 		MasterPassword:       stringFromResourceData(d, schema_definition.AttributeMasterPassword),
 		SessionKey:           stringFromResourceData(d, schema_definition.AttributeSessionKey),
+		UserKey:              stringFromResourceData(d, schema_definition.AttributeUserKey),
 		ClientID:             stringFromResourceData(d, schema_definition.AttributeClientID),
 		ClientSecret:         stringFromResourceData(d, schema_definition.AttributeClientSecret),
 		AccessToken:          stringFromResourceData(d, schema_definition.AttributeBwsAccessToken),
 		VaultPath:            vaultPathFromResourceData(d, schema_definition.AttributeVaultPath),
 		ExtraCACertsPath:     stringFromResourceData(d, schema_definition.AttributeExtraCACertsPath),
+		ClientCertPath:       stringFromResourceData(d, schema_definition.AttributeClientCertPath),
+		ClientKeyPath:        stringFromResourceData(d, schema_definition.AttributeClientKeyPath),
+		ProxyURL:             stringFromResourceData(d, schema_definition.AttributeProxyURL),
+		DebugHARPath:         stringFromResourceData(d, schema_definition.AttributeDebugHARPath),
+		AuditLogPath:         stringFromResourceData(d, schema_definition.AttributeAuditLogPath),
+		ReadOnly:             boolFromResourceData(d, schema_definition.AttributeReadOnly),
+		DeletionProtection:   boolFromResourceData(d, schema_definition.AttributeProviderDeletionProtection),
+		ConflictPolicy:       stringFromResourceData(d, schema_definition.AttributeConflictPolicy),
+		ServerSPKIPins:       stringListFromResourceData(d, schema_definition.AttributeServerSPKIPins),
+		HTTPHeaders:          stringMapFromResourceData(d, schema_definition.AttributeHTTPHeaders),
+		HTTP:                 httpConfigFromResourceData(d),
+		CLI:                  cliConfigFromResourceData(d),
 		ClientImplementation: stringFromResourceData(d, schema_definition.AttributeClientImplementation),
+		ExportFile:           stringFromResourceData(d, schema_definition.AttributeExportFile),
+		ExportPassword:       stringFromResourceData(d, schema_definition.AttributeExportPassword),
+		MemoryFixture:        stringFromResourceData(d, schema_definition.AttributeMemoryFixture),
 	}
 
 	if experimental, ok := d.GetOk(schema_definition.AttributeExperimental); ok {
@@ -183,6 +331,12 @@ func providerConfigFromResourceData(d *schema.ResourceData) providerConfig {
 			if v, ok := m[schema_definition.AttributeExperimentalDisableSyncAfterWriteVerification].(bool); ok {
 				cfg.ExperimentalDisableSyncAfterWriteVerification = v
 			}
+			if v, ok := m[schema_definition.AttributeExperimentalMigrateToItemKeys].(bool); ok {
+				cfg.ExperimentalMigrateToItemKeys = v
+			}
+			if v, ok := m[schema_definition.AttributeExperimentalCLIListCache].(bool); ok {
+				cfg.ExperimentalCLIListCache = v
+			}
 		}
 	}
 
@@ -196,6 +350,203 @@ func stringFromResourceData(d *schema.ResourceData, key string) string {
 	return ""
 }
 
+func boolFromResourceData(d *schema.ResourceData, key string) bool {
+	b, _ := d.Get(key).(bool)
+	return b
+}
+
+func stringListFromResourceData(d *schema.ResourceData, key string) []string {
+	values, ok := d.Get(key).([]interface{})
+	if !ok || len(values) == 0 {
+		return nil
+	}
+	res := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+func stringMapFromResourceData(d *schema.ResourceData, key string) map[string]string {
+	values, ok := d.Get(key).(map[string]interface{})
+	if !ok || len(values) == 0 {
+		return nil
+	}
+	res := make(map[string]string, len(values))
+	for k, v := range values {
+		if s, ok := v.(string); ok {
+			res[k] = s
+		}
+	}
+	return res
+}
+
+// httpConfigFromResourceData reads the http block from raw config, as
+// ResourceData can't tell an omitted number or boolean from its zero value.
+// Without raw config, zero values are considered unset.
+func httpConfigFromResourceData(d *schema.ResourceData) httpConfig {
+	raw := d.GetRawConfig()
+	if raw.IsNull() || !raw.IsKnown() || !raw.Type().IsObjectType() {
+		return httpConfigFromSet(d)
+	}
+	if _, ok := raw.Type().AttributeTypes()[schema_definition.AttributeHTTP]; !ok {
+		return httpConfig{}
+	}
+	blocks := raw.GetAttr(schema_definition.AttributeHTTP)
+	if blocks.IsNull() || !blocks.IsKnown() || blocks.LengthInt() == 0 {
+		return httpConfig{}
+	}
+	return httpConfigFromObject(blocks.AsValueSlice()[0])
+}
+
+func httpConfigFromObject(block cty.Value) httpConfig {
+	cfg := httpConfig{
+		AttemptTimeout: ctyStringAttr(block, schema_definition.AttributeHTTPAttemptTimeout),
+		DialTimeout:    ctyStringAttr(block, schema_definition.AttributeHTTPDialTimeout),
+		RetryOn429:     ctyBoolAttr(block, schema_definition.AttributeHTTPRetryOn429),
+		RetryOn500:     ctyBoolAttr(block, schema_definition.AttributeHTTPRetryOn500),
+		RetryOn503:     ctyBoolAttr(block, schema_definition.AttributeHTTPRetryOn503),
+	}
+	if v := ctyNumberAttr(block, schema_definition.AttributeHTTPMaxConcurrentRequests); v != nil {
+		i, _ := v.Int64()
+		cfg.MaxConcurrentRequests = &i
+	}
+	if v := ctyNumberAttr(block, schema_definition.AttributeHTTPMaxRetries); v != nil {
+		i, _ := v.Int64()
+		cfg.MaxRetries = &i
+	}
+	if v := ctyNumberAttr(block, schema_definition.AttributeHTTPRetryBackoffFactor); v != nil {
+		f, _ := v.Float64()
+		cfg.RetryBackoffFactor = &f
+	}
+	return cfg
+}
+
+func httpConfigFromSet(d *schema.ResourceData) httpConfig {
+	set, ok := d.Get(schema_definition.AttributeHTTP).(*schema.Set)
+	if !ok || set.Len() == 0 {
+		return httpConfig{}
+	}
+
+	m := set.List()[0].(map[string]interface{})
+	cfg := httpConfig{}
+	if v, ok := m[schema_definition.AttributeHTTPMaxConcurrentRequests].(int); ok && v != 0 {
+		i := int64(v)
+		cfg.MaxConcurrentRequests = &i
+	}
+	if v, ok := m[schema_definition.AttributeHTTPMaxRetries].(int); ok && v != 0 {
+		i := int64(v)
+		cfg.MaxRetries = &i
+	}
+	if v, ok := m[schema_definition.AttributeHTTPAttemptTimeout].(string); ok {
+		cfg.AttemptTimeout = v
+	}
+	if v, ok := m[schema_definition.AttributeHTTPDialTimeout].(string); ok {
+		cfg.DialTimeout = v
+	}
+	if v, ok := m[schema_definition.AttributeHTTPRetryBackoffFactor].(float64); ok && v != 0 {
+		cfg.RetryBackoffFactor = &v
+	}
+	// The toggles default to true in the SDKv2 schema, so that false is only
+	// read when explicitly set.
+	if v, ok := m[schema_definition.AttributeHTTPRetryOn429].(bool); ok && !v {
+		cfg.RetryOn429 = &v
+	}
+	if v, ok := m[schema_definition.AttributeHTTPRetryOn500].(bool); ok && !v {
+		cfg.RetryOn500 = &v
+	}
+	if v, ok := m[schema_definition.AttributeHTTPRetryOn503].(bool); ok && !v {
+		cfg.RetryOn503 = &v
+	}
+	return cfg
+}
+
+// cliConfigFromResourceData reads the cli block the same way as the http block.
+func cliConfigFromResourceData(d *schema.ResourceData) cliConfig {
+	raw := d.GetRawConfig()
+	if raw.IsNull() || !raw.IsKnown() || !raw.Type().IsObjectType() {
+		return cliConfigFromSet(d)
+	}
+	if _, ok := raw.Type().AttributeTypes()[schema_definition.AttributeCLI]; !ok {
+		return cliConfig{}
+	}
+	blocks := raw.GetAttr(schema_definition.AttributeCLI)
+	if blocks.IsNull() || !blocks.IsKnown() || blocks.LengthInt() == 0 {
+		return cliConfig{}
+	}
+
+	block := blocks.AsValueSlice()[0]
+	cfg := cliConfig{
+		CommandTimeout: ctyStringAttr(block, schema_definition.AttributeCLICommandTimeout),
+	}
+	if v := ctyNumberAttr(block, schema_definition.AttributeCLIMaxParallelReads); v != nil {
+		i, _ := v.Int64()
+		cfg.MaxParallelReads = &i
+	}
+	if v := ctyNumberAttr(block, schema_definition.AttributeCLIMaxParallelWrites); v != nil {
+		i, _ := v.Int64()
+		cfg.MaxParallelWrites = &i
+	}
+	return cfg
+}
+
+func cliConfigFromSet(d *schema.ResourceData) cliConfig {
+	set, ok := d.Get(schema_definition.AttributeCLI).(*schema.Set)
+	if !ok || set.Len() == 0 {
+		return cliConfig{}
+	}
+
+	m := set.List()[0].(map[string]interface{})
+	cfg := cliConfig{}
+	if v, ok := m[schema_definition.AttributeCLIMaxParallelReads].(int); ok && v != 0 {
+		i := int64(v)
+		cfg.MaxParallelReads = &i
+	}
+	if v, ok := m[schema_definition.AttributeCLIMaxParallelWrites].(int); ok && v != 0 {
+		i := int64(v)
+		cfg.MaxParallelWrites = &i
+	}
+	if v, ok := m[schema_definition.AttributeCLICommandTimeout].(string); ok {
+		cfg.CommandTimeout = v
+	}
+	return cfg
+}
+
+func ctyKnownAttr(obj cty.Value, key string) (cty.Value, bool) {
+	if _, ok := obj.Type().AttributeTypes()[key]; !ok {
+		return cty.NilVal, false
+	}
+	attr := obj.GetAttr(key)
+	if attr.IsNull() || !attr.IsKnown() {
+		return cty.NilVal, false
+	}
+	return attr, true
+}
+
+func ctyStringAttr(obj cty.Value, key string) string {
+	if attr, ok := ctyKnownAttr(obj, key); ok {
+		return attr.AsString()
+	}
+	return ""
+}
+
+func ctyBoolAttr(obj cty.Value, key string) *bool {
+	if attr, ok := ctyKnownAttr(obj, key); ok {
+		b := attr.True()
+		return &b
+	}
+	return nil
+}
+
+func ctyNumberAttr(obj cty.Value, key string) *big.Float {
+	if attr, ok := ctyKnownAttr(obj, key); ok {
+		return attr.AsBigFloat()
+	}
+	return nil
+}
+
 // vaultPathFromResourceData distinguishes an omitted attribute from an
 // explicit empty string. Prefer raw config (null vs "") so muxed SDKv2
 // Configure matches the Framework half; GetOkExists is a fallback for
@@ -227,10 +578,11 @@ func vaultPathFromResourceData(d *schema.ResourceData, key string) vaultPath {
 // data directory. Omitted vault_path still falls through to
 // BITWARDENCLI_APPDATA_DIR / .bitwarden/.
 func applyProviderConfigEnvDefaults(cfg providerConfig) providerConfig {
-	cfg.Server = firstNonEmpty(cfg.Server, envFirst("BW_URL", "BWS_SERVER_URL"), bitwarden.DefaultBitwardenServerURL)
+	cfg.Server = firstNonEmpty(cfg.Server, bitwarden.RegionServerURLs[cfg.Region], envFirst("BW_URL", "BWS_SERVER_URL"), bitwarden.DefaultBitwardenServerURL)
 	cfg.Email = firstNonEmpty(cfg.Email, envFirst("BW_EMAIL"))
 	cfg.MasterPassword = firstNonEmpty(cfg.MasterPassword, envFirst("BW_PASSWORD"))
 	cfg.SessionKey = firstNonEmpty(cfg.SessionKey, envFirst("BW_SESSION"))
+	cfg.UserKey = firstNonEmpty(cfg.UserKey, envFirst("BW_USER_KEY"))
 	cfg.ClientID = firstNonEmpty(cfg.ClientID, envFirst("BW_CLIENTID"))
 	cfg.ClientSecret = firstNonEmpty(cfg.ClientSecret, envFirst("BW_CLIENTSECRET"))
 	cfg.AccessToken = firstNonEmpty(cfg.AccessToken, envFirst("BWS_ACCESS_TOKEN"))
@@ -238,6 +590,18 @@ func applyProviderConfigEnvDefaults(cfg providerConfig) providerConfig {
 		cfg.VaultPath = explicitVaultPath(firstNonEmpty(envFirst("BITWARDENCLI_APPDATA_DIR"), ".bitwarden/"))
 	}
 	cfg.ExtraCACertsPath = firstNonEmpty(cfg.ExtraCACertsPath, envFirst("NODE_EXTRA_CA_CERTS"))
+	cfg.DebugHARPath = firstNonEmpty(cfg.DebugHARPath, envFirst("BW_DEBUG_HAR_PATH"))
+	cfg.AuditLogPath = firstNonEmpty(cfg.AuditLogPath, envFirst("BW_AUDIT_LOG_PATH"))
+	// BW_READ_ONLY can only make the provider read-only, so that pipelines
+	// running configurations they don't trust can enforce it.
+	if v := envFirst("BW_READ_ONLY"); len(v) > 0 {
+		enabled, err := strconv.ParseBool(v)
+		cfg.ReadOnly = cfg.ReadOnly || err != nil || enabled
+	}
+	cfg.ConflictPolicy = firstNonEmpty(cfg.ConflictPolicy, schema_definition.ConflictPolicyFail)
+	cfg.ExportFile = firstNonEmpty(cfg.ExportFile, envFirst("BW_EXPORT_FILE"))
+	cfg.ExportPassword = firstNonEmpty(cfg.ExportPassword, envFirst("BW_EXPORT_PASSWORD"))
+	cfg.MemoryFixture = firstNonEmpty(cfg.MemoryFixture, envFirst("BW_MEMORY_FIXTURE"))
 	return cfg
 }
 
@@ -265,29 +629,131 @@ func envFirst(keys ...string) string {
 func validateProviderConfig(cfg providerConfig) error {
 	hasMasterPassword := cfg.has(cfg.MasterPassword)
 	hasSessionKey := cfg.has(cfg.SessionKey)
+	hasUserKey := cfg.has(cfg.UserKey)
 	hasAccessToken := cfg.has(cfg.AccessToken)
 	hasClientID := cfg.has(cfg.ClientID)
 	hasClientSecret := cfg.has(cfg.ClientSecret)
 	hasEmail := cfg.has(cfg.Email)
+	clientImplementation := getClientImplementation(cfg)
+	useEmbeddedClient := clientImplementation == schema_definition.ClientImplementationEmbedded
+
+	if cfg.ExperimentalMigrateToItemKeys && !useEmbeddedClient {
+		return fmt.Errorf("`experimental.migrate_to_item_keys` is only supported by the embedded client")
+	}
+
+	usesCLIPasswordManager := clientImplementation == schema_definition.ClientImplementationCLI || clientImplementation == schema_definition.ClientImplementationCLIServe
+	if cfg.ExperimentalCLIListCache && !usesCLIPasswordManager {
+		return fmt.Errorf("`experimental.cli_list_cache` is only supported by the Bitwarden CLI")
+	}
+
+	// The CLIs would talk to the server without the headers, which proxies
+	// requiring them reject with confusing errors.
+	if len(cfg.HTTPHeaders) > 0 && usesCLIPasswordManager {
+		return fmt.Errorf("`http_headers` is only supported by the embedded client, as the Bitwarden CLIs don't send custom headers")
+	}
+
+	// An export is served as is, Password Manager credentials are ignored.
+	if clientImplementation == schema_definition.ClientImplementationExportFile {
+		if !cfg.has(cfg.ExportFile) {
+			return fmt.Errorf("`export_file` is required when `client_implementation` is \"export_file\"")
+		}
+		return validateHTTPConfig(cfg.HTTP)
+	}
+
+	// The in-memory vault needs no credentials at all.
+	if clientImplementation == schema_definition.ClientImplementationMemory {
+		return validateHTTPConfig(cfg.HTTP)
+	}
+
+	if err := validateCLIConfig(cfg.CLI); err != nil {
+		return err
+	}
+
+	if !hasMasterPassword && !hasSessionKey && !hasUserKey && !hasAccessToken {
+		return fmt.Errorf("one of `access_token`, `master_password`, `session_key` or `user_key` must be specified")
+	}
+
+	if hasMasterPassword && hasSessionKey {
+		return fmt.Errorf("`master_password` conflicts with `session_key`")
+	}
 
-	if !hasMasterPassword && !hasSessionKey && !hasAccessToken {
-		return fmt.Errorf("one of `access_token`, `master_password` or `session_key` must be specified")
+	if hasUserKey && (hasMasterPassword || hasSessionKey) {
+		return fmt.Errorf("`user_key` conflicts with `master_password` and `session_key`")
 	}
 
-	if hasMasterPassword && (hasSessionKey || hasAccessToken) {
-		return fmt.Errorf("`master_password` conflicts with `session_key` and `access_token`")
+	if hasUserKey && !useEmbeddedClient {
+		return fmt.Errorf("`user_key` is only supported by the embedded client")
 	}
 
 	if hasClientID != hasClientSecret {
 		return fmt.Errorf("`client_id` and `client_secret` must be specified together")
 	}
 
-	if (hasClientID || hasClientSecret) && !hasMasterPassword {
+	// The embedded client logs in with the API key and only skips the master
+	// password derivation when unlocking with a user key or session key.
+	unlocksWithKey := hasUserKey || (useEmbeddedClient && hasSessionKey)
+	if (hasClientID || hasClientSecret) && !hasMasterPassword && !unlocksWithKey {
 		return fmt.Errorf("`client_id` and `client_secret` require `master_password` to also be specified")
 	}
 
-	if !hasEmail && !hasAccessToken && !hasClientID && !hasSessionKey {
-		return fmt.Errorf("one of `access_token`, `client_id`, `email` or `session_key` must be specified")
+	if hasUserKey && !hasClientID {
+		return fmt.Errorf("`user_key` requires `client_id` and `client_secret` to also be specified")
+	}
+
+	// The access token only authenticates against the Secrets Manager, the
+	// Password Manager still needs to know which account to log into.
+	if hasMasterPassword && !hasEmail && !hasClientID {
+		return fmt.Errorf("`master_password` requires one of `client_id` or `email` to also be specified")
+	}
+
+	if cfg.has(cfg.ClientCertPath) != cfg.has(cfg.ClientKeyPath) {
+		return fmt.Errorf("`client_cert` and `client_key` must be specified together")
+	}
+
+	return validateHTTPConfig(cfg.HTTP)
+}
+
+func validateCLIConfig(cfg cliConfig) error {
+	if cfg.MaxParallelReads != nil && *cfg.MaxParallelReads < 1 {
+		return fmt.Errorf("`cli.max_parallel_reads` must be at least 1")
+	}
+
+	if cfg.MaxParallelWrites != nil && *cfg.MaxParallelWrites < 1 {
+		return fmt.Errorf("`cli.max_parallel_writes` must be at least 1")
+	}
+
+	if len(cfg.CommandTimeout) > 0 {
+		if d, err := time.ParseDuration(cfg.CommandTimeout); err != nil || d <= 0 {
+			return fmt.Errorf("`cli.command_timeout` must be a positive duration like \"5m\", got %q", cfg.CommandTimeout)
+		}
+	}
+
+	return nil
+}
+
+func validateHTTPConfig(cfg httpConfig) error {
+	if cfg.MaxConcurrentRequests != nil && *cfg.MaxConcurrentRequests < 1 {
+		return fmt.Errorf("`http.max_concurrent_requests` must be at least 1")
+	}
+
+	if cfg.MaxRetries != nil && *cfg.MaxRetries < 0 {
+		return fmt.Errorf("`http.max_retries` must not be negative")
+	}
+
+	if cfg.RetryBackoffFactor != nil && *cfg.RetryBackoffFactor < 1 {
+		return fmt.Errorf("`http.retry_backoff_factor` must be at least 1")
+	}
+
+	for attribute, value := range map[string]string{
+		schema_definition.AttributeHTTPAttemptTimeout: cfg.AttemptTimeout,
+		schema_definition.AttributeHTTPDialTimeout:    cfg.DialTimeout,
+	} {
+		if len(value) == 0 {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			return fmt.Errorf("`http.%s` must be a positive duration like \"30s\", got %q", attribute, value)
+		}
 	}
 
 	return nil
@@ -297,52 +763,114 @@ func validateProviderConfig(cfg providerConfig) error {
 // configuration. It mirrors the behaviour of the historical SDKv2
 // providerConfigure implementation.
 func configureClients(ctx context.Context, version string, cfg providerConfig) (*ProviderClients, error) {
-	shouldLogin := !strings.Contains(version, versionTestSkippedLogin)
+	cfg, cliVersion := routeAroundIncompatibilities(ctx, version, cfg)
+	clientImplementation := getClientImplementation(cfg)
+	useEmbeddedClient := clientImplementation == schema_definition.ClientImplementationEmbedded
 
-	hasAccessToken := cfg.has(cfg.AccessToken)
-	useEmbeddedClient := getClientImplementation(cfg) == schema_definition.ClientImplementationEmbedded
-
-	if useEmbeddedClient && cfg.has(cfg.SessionKey) {
-		return nil, fmt.Errorf("session key is not supported with the embedded client")
+	// The in-memory vault serves both products, whatever the credentials.
+	if clientImplementation == schema_definition.ClientImplementationMemory {
+		vault, err := embedded.NewMemoryVault(ctx, cfg.MemoryFixture)
+		if err != nil {
+			return nil, err
+		}
+		clients := &ProviderClients{PasswordManager: vault, SecretsManager: vault, DeletionProtection: cfg.DeletionProtection, ConflictPolicy: cfg.ConflictPolicy}
+		clients.Capabilities = detectCapabilities(ctx, version, cfg, clients, cliVersion)
+		return decorateClients(cfg, clients)
 	}
 
-	if useEmbeddedClient && !hasAccessToken {
-		bwClient, err := newEmbeddedPasswordManagerClient(ctx, cfg, version)
+	// Password Manager and Secrets Manager credentials are independent, and
+	// both clients are built when both sets are provided.
+	clients := &ProviderClients{DeletionProtection: cfg.DeletionProtection, ConflictPolicy: cfg.ConflictPolicy}
+	if clientImplementation == schema_definition.ClientImplementationExportFile {
+		bwClient, err := embedded.LoadExportFile(ctx, cfg.ExportFile, cfg.ExportPassword)
 		if err != nil {
 			return nil, err
 		}
-
-		if shouldLogin {
-			if err = ensureLoggedInEmbeddedPasswordManager(ctx, cfg, bwClient); err != nil {
-				return nil, err
-			}
+		clients.PasswordManager = bwClient
+	} else if cfg.has(cfg.MasterPassword) || cfg.has(cfg.SessionKey) || cfg.has(cfg.UserKey) {
+		bwClient, err := configurePasswordManager(ctx, version, cfg, useEmbeddedClient)
+		if err != nil {
+			return nil, err
 		}
-		return &ProviderClients{PasswordManager: bwClient}, nil
-	} else if useEmbeddedClient && hasAccessToken {
-		bwsClient, err := newEmbeddedSecretsManagerClient(ctx, cfg, version)
+		clients.PasswordManager = bwClient
+	}
+
+	if cfg.has(cfg.AccessToken) {
+		bwsClient, err := configureSecretsManager(ctx, version, cfg, useEmbeddedClient)
 		if err != nil {
 			return nil, err
 		}
+		clients.SecretsManager = bwsClient
+	}
 
-		if shouldLogin {
-			if err = ensureLoggedInEmbeddedSecretsManager(ctx, cfg, bwsClient); err != nil {
-				return nil, err
-			}
+	clients.Capabilities = detectCapabilities(ctx, version, cfg, clients, cliVersion)
+	return decorateClients(cfg, clients)
+}
+
+// decorateClients wraps the clients with the decorators the configuration
+// enables. It runs last, as logging in and detecting capabilities need the
+// clients themselves. Writes refused by `read_only` are still audited.
+func decorateClients(cfg providerConfig, clients *ProviderClients) (*ProviderClients, error) {
+	if cfg.ReadOnly {
+		if clients.PasswordManager != nil {
+			clients.PasswordManager = readonly.NewPasswordManager(clients.PasswordManager)
+		}
+		if clients.SecretsManager != nil {
+			clients.SecretsManager = readonly.NewSecretsManager(clients.SecretsManager)
 		}
-		return &ProviderClients{SecretsManager: bwsClient}, nil
-	} else if !useEmbeddedClient && hasAccessToken {
-		bwsClient, err := newCLISecretsManagerClient(ctx, cfg, version)
+	}
+
+	if !cfg.has(cfg.AuditLogPath) {
+		return clients, nil
+	}
+
+	auditLogPath, err := filepath.Abs(cfg.AuditLogPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid `audit_log_path`: %w", err)
+	}
+	clientImplementation := getClientImplementation(cfg)
+	if clients.PasswordManager != nil {
+		log, err := audit.NewLog(auditLogPath, audit.WithClientImplementation(clientImplementation), audit.WithActor(firstNonEmpty(cfg.Email, cfg.ClientID)))
 		if err != nil {
 			return nil, err
 		}
+		clients.PasswordManager = audit.NewPasswordManager(clients.PasswordManager, log)
+	}
+	if clients.SecretsManager != nil {
+		log, err := audit.NewLog(auditLogPath, audit.WithClientImplementation(clientImplementation), audit.WithActor(accessTokenID(cfg.AccessToken)))
+		if err != nil {
+			return nil, err
+		}
+		clients.SecretsManager = audit.NewSecretsManager(clients.SecretsManager, log)
+	}
+	return clients, nil
+}
+
+// accessTokenID returns the ID of a Secrets Manager access token, formatted as
+// "0.<id>.<secret>:<key>", or an empty string when it is malformed.
+func accessTokenID(accessToken string) string {
+	parts := strings.Split(strings.SplitN(accessToken, ":", 2)[0], ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	return parts[1]
+}
 
-		// We login anyway, since it's just about storing the access token
-		// when using the CLI.
-		if err = ensureLoggedInEmbeddedSecretsManager(ctx, cfg, bwsClient); err != nil {
+func configurePasswordManager(ctx context.Context, version string, cfg providerConfig, useEmbeddedClient bool) (bitwarden.PasswordManager, error) {
+	shouldLogin := !strings.Contains(version, versionTestSkippedLogin)
+
+	if useEmbeddedClient {
+		bwClient, err := newEmbeddedPasswordManagerClient(ctx, cfg, version)
+		if err != nil {
 			return nil, err
 		}
 
-		return &ProviderClients{SecretsManager: bwsClient}, nil
+		if shouldLogin {
+			if err = ensureLoggedInEmbeddedPasswordManager(ctx, cfg, bwClient); err != nil {
+				return nil, err
+			}
+		}
+		return bwClient, nil
 	}
 
 	bwClient, err := newCLIPasswordManagerClient(cfg, version)
@@ -360,7 +888,38 @@ func configureClients(ctx context.Context, version string, cfg providerConfig) (
 		}
 	}
 
-	return &ProviderClients{PasswordManager: bwClient}, nil
+	return bwClient, nil
+}
+
+func configureSecretsManager(ctx context.Context, version string, cfg providerConfig, useEmbeddedClient bool) (bitwarden.SecretsManager, error) {
+	shouldLogin := !strings.Contains(version, versionTestSkippedLogin)
+
+	if useEmbeddedClient {
+		bwsClient, err := newEmbeddedSecretsManagerClient(ctx, cfg, version)
+		if err != nil {
+			return nil, err
+		}
+
+		if shouldLogin {
+			if err = ensureLoggedInEmbeddedSecretsManager(ctx, cfg, bwsClient); err != nil {
+				return nil, err
+			}
+		}
+		return bwsClient, nil
+	}
+
+	bwsClient, err := newCLISecretsManagerClient(ctx, cfg, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// We login anyway, since it's just about storing the access token
+	// when using the CLI.
+	if err = ensureLoggedInEmbeddedSecretsManager(ctx, cfg, bwsClient); err != nil {
+		return nil, err
+	}
+
+	return bwsClient, nil
 }
 
 func getClientImplementation(cfg providerConfig) string {
@@ -386,6 +945,12 @@ func ensureLoggedInCLIPasswordManager(ctx context.Context, cfg providerConfig, b
 		return err
 	}
 
+	// A read-only provider only reuses the local Vault of the CLI, as logging
+	// in would change the server of the CLI and store a new session.
+	if cfg.ReadOnly && status.Status == bwcli.StatusUnauthenticated {
+		return fmt.Errorf("%w: the Bitwarden CLI isn't logged in and the provider is read-only so it won't log in, run `bw login` beforehand or use the embedded client", models.ErrReadOnlyVault)
+	}
+
 	if err = logoutIfIdentityChanged(ctx, cfg, bwClient, status); err != nil {
 		return err
 	}
@@ -414,7 +979,7 @@ func ensureLoggedInCLIPasswordManager(ctx context.Context, cfg providerConfig, b
 	//             => login and return
 	//
 	// Note: We don't trigger a manual 'sync' as login operations already do.
-	switch loginMethod(cfg) {
+	switch passwordManagerLoginMethod(cfg) {
 	case LoginMethodPersonalAPIKey:
 		return bwClient.LoginWithAPIKey(ctx, cfg.MasterPassword, cfg.ClientID, cfg.ClientSecret)
 	case LoginMethodPassword:
@@ -436,7 +1001,14 @@ func ensureLoggedInCLIPasswordManager(ctx context.Context, cfg providerConfig, b
 func loginMethod(cfg providerConfig) LoginMethod {
 	if cfg.has(cfg.AccessToken) {
 		return LoginMethodAccessToken
-	} else if cfg.has(cfg.ClientID) && cfg.has(cfg.ClientSecret) {
+	}
+	return passwordManagerLoginMethod(cfg)
+}
+
+// passwordManagerLoginMethod ignores the access token, which only
+// authenticates against the Secrets Manager.
+func passwordManagerLoginMethod(cfg providerConfig) LoginMethod {
+	if cfg.has(cfg.ClientID) && cfg.has(cfg.ClientSecret) {
 		return LoginMethodPersonalAPIKey
 	} else if cfg.has(cfg.MasterPassword) {
 		return LoginMethodPassword
@@ -451,6 +1023,9 @@ func logoutIfIdentityChanged(ctx context.Context, cfg providerConfig, bwClient b
 	vaultBelongsToEmailAndServer := (!emailProvided || status.VaultOfUser(cfg.Email)) && status.VaultFromServer(serverURL)
 
 	if (status.Status == bwcli.StatusLocked || status.Status == bwcli.StatusUnlocked) && !vaultBelongsToEmailAndServer {
+		if cfg.ReadOnly {
+			return fmt.Errorf("%w: the local Vault in `vault_path` belongs to %s on %s, and the provider is read-only so it won't log it out", models.ErrReadOnlyVault, status.UserEmail, status.ServerURL)
+		}
 		status.Status = bwcli.StatusUnauthenticated
 
 		tflog.Warn(ctx, "Logging out as the local Vault belongs to a different identity", map[string]interface{}{"vault_email": status.UserEmail, "vault_server": status.ServerURL, "provider_server": serverURL})
@@ -481,54 +1056,192 @@ func newCLIPasswordManagerClient(cfg providerConfig, version string) (bwcli.Pass
 		opts = append(opts, bwcli.WithExtraCACertsPath(cfg.ExtraCACertsPath))
 	}
 
+	if cfg.has(cfg.APIURL) || cfg.has(cfg.IdentityURL) || cfg.has(cfg.EventsURL) {
+		opts = append(opts, bwcli.WithServerURLs(cfg.APIURL, cfg.IdentityURL, cfg.EventsURL))
+	}
+
+	if !strings.Contains(version, versionTestSkippedLogin) {
+		// Sessions can expire or be locked by other processes during long
+		// runs, in which case we go through the login scenarios again.
+		opts = append(opts, bwcli.WithSessionRecovery(func(ctx context.Context, bwClient bwcli.PasswordManagerClient) error {
+			return ensureLoggedInCLIPasswordManager(ctx, cfg, bwClient)
+		}))
+	}
+
+	if getClientImplementation(cfg) == schema_definition.ClientImplementationCLIServe {
+		opts = append(opts, bwcli.WithServe())
+	}
+
+	if cfg.ExperimentalCLIListCache {
+		opts = append(opts, bwcli.WithListCache())
+	}
+
 	if version == versionTestDisabledRetries {
 		// During development, we disable retry backoffs to make some operations faster.
 		opts = append(opts, bwcli.DisableRetryBackoff())
 	}
 
+	if cfg.HTTP.MaxRetries != nil {
+		opts = append(opts, bwcli.WithMaxRetries(int(*cfg.HTTP.MaxRetries)))
+	}
+	if cfg.HTTP.RetryBackoffFactor != nil {
+		opts = append(opts, bwcli.WithRetryBackoffFactor(*cfg.HTTP.RetryBackoffFactor))
+	}
+	if cfg.HTTP.RetryOn429 != nil && !*cfg.HTTP.RetryOn429 {
+		opts = append(opts, bwcli.DisableRateLimitRetries())
+	}
+
+	if cfg.CLI.MaxParallelReads != nil {
+		opts = append(opts, bwcli.WithMaxParallelReads(int(*cfg.CLI.MaxParallelReads)))
+	}
+	if cfg.CLI.MaxParallelWrites != nil {
+		opts = append(opts, bwcli.WithMaxParallelWrites(int(*cfg.CLI.MaxParallelWrites)))
+	}
+	if len(cfg.CLI.CommandTimeout) > 0 {
+		// Validated in validateCLIConfig().
+		timeout, _ := time.ParseDuration(cfg.CLI.CommandTimeout)
+		opts = append(opts, bwcli.WithCommandTimeout(timeout))
+	}
+
 	return bwcli.NewPasswordManagerClient(opts...), nil
 }
 
 func newEmbeddedPasswordManagerClient(ctx context.Context, cfg providerConfig, version string) (bitwarden.PasswordManager, error) {
-	deviceId, err := getOrGenerateDeviceIdentifier(ctx)
+	deviceId, err := getOrGenerateDeviceIdentifier(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	webapiOpts, err := buildWebapiOptions(cfg, version)
 	if err != nil {
 		return nil, err
 	}
 
 	opts := []embedded.PasswordManagerOptions{
-		embedded.WithPasswordManagerHttpOptions(buildWebapiOptions(version)...),
+		embedded.WithPasswordManagerHttpOptions(webapiOpts...),
 	}
 
 	if cfg.ExperimentalDisableSyncAfterWriteVerification {
 		opts = append(opts, embedded.DisableFailOnSyncAfterWriteVerification())
 	}
 
+	if cfg.ExperimentalMigrateToItemKeys {
+		opts = append(opts, embedded.EnableItemKeyMigration())
+	}
+
 	return embedded.NewPasswordManagerClient(cfg.Server, deviceId, version, opts...), nil
 }
 
 func newEmbeddedSecretsManagerClient(ctx context.Context, cfg providerConfig, version string) (bitwarden.SecretsManager, error) {
-	deviceId, err := getOrGenerateDeviceIdentifier(ctx)
+	deviceId, err := getOrGenerateDeviceIdentifier(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	webapiOpts, err := buildWebapiOptions(cfg, version)
 	if err != nil {
 		return nil, err
 	}
 
-	return embedded.NewSecretsManagerClient(cfg.Server, deviceId, version, embedded.WithSecretsManagerHttpOptions(buildWebapiOptions(version)...)), nil
+	return embedded.NewSecretsManagerClient(cfg.Server, deviceId, version, embedded.WithSecretsManagerHttpOptions(webapiOpts...)), nil
 }
 
-func newCLISecretsManagerClient(_ context.Context, cfg providerConfig, _ string) (bitwarden.SecretsManager, error) {
+func newCLISecretsManagerClient(ctx context.Context, cfg providerConfig, _ string) (bitwarden.SecretsManager, error) {
+	if cfg.has(cfg.APIURL) || cfg.has(cfg.IdentityURL) {
+		tflog.Warn(ctx, "The api_url and identity_url attributes are ignored by the Secrets Manager CLI, which only supports a server URL. Use client_implementation = \"embedded\" instead.")
+	}
 	return bwscli.NewSecretsManagerClient(cfg.Server), nil
 }
 
-func buildWebapiOptions(version string) []webapi.Options {
+func buildWebapiOptions(cfg providerConfig, version string) ([]webapi.Options, error) {
 	webapiOpts := []webapi.Options{}
 	if version == versionTestDisabledRetries {
 		// During development, we don't want to wait on any sporadic errors.
 		webapiOpts = append(webapiOpts, webapi.DisableRetries())
 	}
+
+	webapiOpts = append(webapiOpts, buildWebapiHTTPOptions(cfg.HTTP)...)
+
+	if cfg.has(cfg.APIURL) {
+		webapiOpts = append(webapiOpts, webapi.WithAPIURL(cfg.APIURL))
+	}
+	if cfg.has(cfg.IdentityURL) {
+		webapiOpts = append(webapiOpts, webapi.WithIdentityURL(cfg.IdentityURL))
+	}
+
+	pinnedHosts := []string{}
+	for _, rawURL := range []string{cfg.Server, cfg.APIURL, cfg.IdentityURL} {
+		if !cfg.has(rawURL) {
+			continue
+		}
+		serverURL, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid server URL '%s': %w", rawURL, err)
+		}
+		pinnedHosts = append(pinnedHosts, serverURL.Hostname())
+	}
+
+	tlsConfig, err := webapi.NewTLSConfig(webapi.TLSOptions{
+		ExtraCACertsPath: cfg.ExtraCACertsPath,
+		ClientCertPath:   cfg.ClientCertPath,
+		ClientKeyPath:    cfg.ClientKeyPath,
+		PinnedHosts:      pinnedHosts,
+		SPKIPins:         cfg.ServerSPKIPins,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		webapiOpts = append(webapiOpts, webapi.WithTLSConfig(tlsConfig))
+	}
+
+	if len(cfg.HTTPHeaders) > 0 {
+		webapiOpts = append(webapiOpts, webapi.WithHeaders(cfg.HTTPHeaders))
+	}
+
+	if cfg.has(cfg.ProxyURL) {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL '%s': %w", cfg.ProxyURL, err)
+		}
+		webapiOpts = append(webapiOpts, webapi.WithProxyURL(proxyURL))
+	}
+
+	if cfg.has(cfg.DebugHARPath) {
+		harPath, err := filepath.Abs(cfg.DebugHARPath)
+		if err != nil {
+			return nil, err
+		}
+		webapiOpts = append(webapiOpts, webapi.WithHARFile(harPath))
+	}
+	return webapiOpts, nil
+}
+
+func buildWebapiHTTPOptions(cfg httpConfig) []webapi.Options {
+	webapiOpts := []webapi.Options{}
+	if cfg.MaxConcurrentRequests != nil {
+		webapiOpts = append(webapiOpts, webapi.WithMaxConcurrentRequests(int(*cfg.MaxConcurrentRequests)))
+	}
+	if cfg.MaxRetries != nil {
+		webapiOpts = append(webapiOpts, webapi.WithMaxRetries(int(*cfg.MaxRetries)))
+	}
+	// Durations are checked in validateProviderConfig.
+	if attemptTimeout, err := time.ParseDuration(cfg.AttemptTimeout); err == nil {
+		webapiOpts = append(webapiOpts, webapi.WithAttemptTimeout(attemptTimeout))
+	}
+	if dialTimeout, err := time.ParseDuration(cfg.DialTimeout); err == nil {
+		webapiOpts = append(webapiOpts, webapi.WithDialTimeout(dialTimeout))
+	}
+	if cfg.RetryBackoffFactor != nil {
+		webapiOpts = append(webapiOpts, webapi.WithRetryBackoffFactor(*cfg.RetryBackoffFactor))
+	}
+	if statusCodes := cfg.retryableStatusCodes(); statusCodes != nil {
+		webapiOpts = append(webapiOpts, webapi.WithRetryableStatusCodes(statusCodes))
+	}
 	return webapiOpts
 }
 
-func getOrGenerateDeviceIdentifier(ctx context.Context) (string, error) {
+func getOrGenerateDeviceIdentifier(ctx context.Context, cfg providerConfig) (string, error) {
 	deviceIdBytes, err := os.ReadFile(".bitwarden/device_identifier")
 	if err == nil {
 		deviceId := string(deviceIdBytes)
@@ -536,6 +1249,15 @@ func getOrGenerateDeviceIdentifier(ctx context.Context) (string, error) {
 		return strings.TrimSpace(deviceId), nil
 	}
 
+	// A read-only provider doesn't store anything, but still presents the
+	// same device on every run so that the account isn't notified of a new
+	// login each time.
+	if cfg.ReadOnly {
+		deviceId := embedded.DeviceIdentifierFor(strings.Join([]string{cfg.Server, cfg.Email, cfg.ClientID, accessTokenID(cfg.AccessToken)}, "\x00"))
+		tflog.Info(ctx, "Derived device identifier as the provider is read-only", map[string]interface{}{"device_id": deviceId})
+		return deviceId, nil
+	}
+
 	deviceId := embedded.NewDeviceIdentifier()
 	err = os.Mkdir(".bitwarden", 0700)
 	if err != nil && !os.IsExist(err) {
@@ -566,11 +1288,19 @@ func ensureLoggedInEmbeddedSecretsManager(ctx context.Context, cfg providerConfi
 }
 
 func ensureLoggedInEmbeddedPasswordManager(ctx context.Context, cfg providerConfig, bwClient bitwarden.PasswordManager) error {
+	if cfg.has(cfg.SessionKey) && !cfg.has(cfg.ClientID) {
+		return loadEmbeddedPasswordManagerFromCLIVault(ctx, cfg, bwClient)
+	}
+
+	if cfg.has(cfg.UserKey) || cfg.has(cfg.SessionKey) {
+		return loginEmbeddedPasswordManagerWithUserKey(ctx, cfg, bwClient)
+	}
+
 	if !cfg.has(cfg.MasterPassword) {
 		return fmt.Errorf("master password is required")
 	}
 
-	switch loginMethod(cfg) {
+	switch passwordManagerLoginMethod(cfg) {
 	case LoginMethodPersonalAPIKey:
 		return bwClient.LoginWithAPIKey(ctx, cfg.MasterPassword, cfg.ClientID, cfg.ClientSecret)
 	case LoginMethodPassword:
@@ -579,3 +1309,47 @@ func ensureLoggedInEmbeddedPasswordManager(ctx context.Context, cfg providerConf
 
 	return fmt.Errorf("INTERNAL BUG: not enough parameters provided to login (status: 'BUG')")
 }
+
+// loadEmbeddedPasswordManagerFromCLIVault reads the Vault the CLI synced and
+// unlocked in vault_path, without logging in. Objects are only read from that
+// local copy, and write operations fail.
+func loadEmbeddedPasswordManagerFromCLIVault(ctx context.Context, cfg providerConfig, bwClient bitwarden.PasswordManager) error {
+	embeddedClient, ok := bwClient.(embedded.PasswordManagerClient)
+	if !ok {
+		return fmt.Errorf("INTERNAL BUG: loading a CLI vault requires the embedded client")
+	}
+
+	dir, ok := cfg.VaultPath.appDataDir()
+	if !ok {
+		return fmt.Errorf("`vault_path` is required to unlock the embedded client with `session_key`")
+	}
+
+	tflog.Info(ctx, "Loading the Vault from the CLI data without logging in, write operations will fail", map[string]interface{}{"vault_path": dir})
+	return embeddedClient.LoadCLIVaultWithSessionKey(ctx, dir, cfg.SessionKey)
+}
+
+// loginEmbeddedPasswordManagerWithUserKey logs in with the personal API key and
+// unlocks the Vault with a user key, either provided directly or unprotected
+// from a vault the CLI unlocked in vault_path with the session key.
+func loginEmbeddedPasswordManagerWithUserKey(ctx context.Context, cfg providerConfig, bwClient bitwarden.PasswordManager) error {
+	embeddedClient, ok := bwClient.(embedded.PasswordManagerClient)
+	if !ok {
+		return fmt.Errorf("INTERNAL BUG: unlocking with a user key requires the embedded client")
+	}
+
+	userKey := cfg.UserKey
+	if !cfg.has(userKey) {
+		dir, ok := cfg.VaultPath.appDataDir()
+		if !ok {
+			return fmt.Errorf("`vault_path` is required to unlock the embedded client with `session_key`")
+		}
+
+		var err error
+		userKey, err = embedded.UserKeyFromCLISession(dir, cfg.SessionKey)
+		if err != nil {
+			return fmt.Errorf("error reading user key from the CLI vault: %w", err)
+		}
+	}
+
+	return embeddedClient.LoginWithAPIKeyAndUserKey(ctx, userKey, cfg.ClientID, cfg.ClientSecret)
+}
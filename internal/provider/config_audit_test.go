@@ -0,0 +1,89 @@
+//go:build offline
+
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/transformation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureClientsWithAuditLog(t *testing.T) {
+	auditLogPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	cfg := providerConfig{
+		Email:                "terraform@example.com",
+		AccessToken:          "0.access-token-id.client-secret:a2V5",
+		AuditLogPath:         auditLogPath,
+		ClientImplementation: schema_definition.ClientImplementationMemory,
+	}
+
+	clients, err := configureClients(t.Context(), versionTestSkippedLogin, cfg)
+	require.NoError(t, err)
+
+	_, err = clients.PasswordManager.CreateFolder(t.Context(), models.Folder{Name: "Infrastructure"})
+	require.NoError(t, err)
+	_, err = clients.SecretsManager.CreateProject(t.Context(), models.Project{Name: "Backend"})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(auditLogPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"client_implementation":"memory","actor":"terraform@example.com","operation":"create","object_type":"folder"`)
+	assert.Contains(t, string(content), `"client_implementation":"memory","actor":"access-token-id","operation":"create","object_type":"project"`)
+}
+
+func TestItemUpdateAuditsChangesFromPriorState(t *testing.T) {
+	auditLogPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	clients, err := configureClients(t.Context(), versionTestSkippedLogin, providerConfig{
+		AuditLogPath:         auditLogPath,
+		ClientImplementation: schema_definition.ClientImplementationMemory,
+	})
+	require.NoError(t, err)
+
+	item, err := clients.PasswordManager.CreateItem(t.Context(), models.Item{
+		Type:   models.ItemTypeLogin,
+		Name:   "terraform",
+		Login:  models.Login{Username: "admin", Password: "secret"},
+		Object: models.ObjectTypeItem,
+	})
+	require.NoError(t, err)
+
+	resource := resourceItemLogin()
+	d := resource.Data(nil)
+	require.NoError(t, transformation.ItemObjectToSchema(t.Context(), item, d))
+	d, err = schema.InternalMap(resource.Schema).Data(d.State(), &terraform.InstanceDiff{
+		Attributes: map[string]*terraform.ResourceAttrDiff{
+			schema_definition.AttributeNotes: {New: "updated by Terraform"},
+		},
+	})
+	require.NoError(t, err)
+
+	diags := opItemUpdate(models.ItemTypeLogin)(t.Context(), d, clients.PasswordManager, true)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	content, err := os.ReadFile(auditLogPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"operation":"edit","object_type":"item","object_id":"`+item.ID+`","changed_attributes":["notes"],"result":"success"`)
+}
+
+func TestConfigureClientsWithUnwritableAuditLog(t *testing.T) {
+	cfg := providerConfig{
+		AuditLogPath:         t.TempDir(),
+		ClientImplementation: schema_definition.ClientImplementationMemory,
+	}
+
+	_, err := configureClients(t.Context(), versionTestSkippedLogin, cfg)
+	assert.ErrorContains(t, err, "error opening the audit log")
+}
+
+func TestAccessTokenID(t *testing.T) {
+	assert.Equal(t, "access-token-id", accessTokenID("0.access-token-id.client-secret:a2V5"))
+	assert.Empty(t, accessTokenID("malformed"))
+}
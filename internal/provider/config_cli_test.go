@@ -0,0 +1,59 @@
+//go:build offline
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCLIConfigFromResourceDataWithoutRawConfig(t *testing.T) {
+	sdk := NewSDK(versionTestSkippedLogin)()
+
+	d := schema.TestResourceDataRaw(t, sdk.Schema, map[string]interface{}{
+		schema_definition.AttributeCLI: []interface{}{
+			map[string]interface{}{
+				schema_definition.AttributeCLIMaxParallelWrites: 2,
+				schema_definition.AttributeCLICommandTimeout:    "1m",
+			},
+		},
+	})
+
+	cfg := cliConfigFromResourceData(d)
+	assert.Nil(t, cfg.MaxParallelReads)
+	require.NotNil(t, cfg.MaxParallelWrites)
+	assert.Equal(t, int64(2), *cfg.MaxParallelWrites)
+	assert.Equal(t, "1m", cfg.CommandTimeout)
+}
+
+func TestValidateCLIConfig(t *testing.T) {
+	zero := int64(0)
+	one := int64(1)
+
+	testData := map[string]struct {
+		cfg           cliConfig
+		expectedError string
+	}{
+		"empty":                   {cfg: cliConfig{}},
+		"serialized":              {cfg: cliConfig{MaxParallelReads: &one, MaxParallelWrites: &one, CommandTimeout: "30s"}},
+		"no-read-parallelism":     {cfg: cliConfig{MaxParallelReads: &zero}, expectedError: "max_parallel_reads"},
+		"no-write-parallelism":    {cfg: cliConfig{MaxParallelWrites: &zero}, expectedError: "max_parallel_writes"},
+		"invalid-command-timeout": {cfg: cliConfig{CommandTimeout: "forever"}, expectedError: "command_timeout"},
+		"zero-command-timeout":    {cfg: cliConfig{CommandTimeout: "0s"}, expectedError: "command_timeout"},
+	}
+
+	for name, tt := range testData {
+		t.Run(name, func(t *testing.T) {
+			err := validateCLIConfig(tt.cfg)
+			if len(tt.expectedError) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
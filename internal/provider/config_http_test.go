@@ -0,0 +1,114 @@
+//go:build offline
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPConfigFromObject(t *testing.T) {
+	sdk := NewSDK(versionTestSkippedLogin)()
+	typ := schema.InternalMap(sdk.Schema).CoreConfigSchema().ImpliedType()
+	blockType := typ.AttributeType(schema_definition.AttributeHTTP).ElementType()
+
+	vals := make(map[string]cty.Value, len(blockType.AttributeTypes()))
+	for name, at := range blockType.AttributeTypes() {
+		vals[name] = cty.NullVal(at)
+	}
+	vals[schema_definition.AttributeHTTPMaxRetries] = cty.NumberIntVal(0)
+	vals[schema_definition.AttributeHTTPAttemptTimeout] = cty.StringVal("1m")
+	vals[schema_definition.AttributeHTTPRetryBackoffFactor] = cty.NumberFloatVal(2.5)
+	vals[schema_definition.AttributeHTTPRetryOn500] = cty.False
+
+	cfg := httpConfigFromObject(cty.ObjectVal(vals))
+	assert.Nil(t, cfg.MaxConcurrentRequests)
+	require.NotNil(t, cfg.MaxRetries)
+	assert.Equal(t, int64(0), *cfg.MaxRetries)
+	assert.Equal(t, "1m", cfg.AttemptTimeout)
+	assert.Empty(t, cfg.DialTimeout)
+	require.NotNil(t, cfg.RetryBackoffFactor)
+	assert.Equal(t, 2.5, *cfg.RetryBackoffFactor)
+	assert.Nil(t, cfg.RetryOn429)
+	require.NotNil(t, cfg.RetryOn500)
+	assert.False(t, *cfg.RetryOn500)
+	assert.Equal(t, []int{429, 503}, cfg.retryableStatusCodes())
+}
+
+func TestHTTPConfigFromResourceDataWithoutRawConfig(t *testing.T) {
+	sdk := NewSDK(versionTestSkippedLogin)()
+
+	d := schema.TestResourceDataRaw(t, sdk.Schema, map[string]interface{}{
+		schema_definition.AttributeHTTP: []interface{}{
+			map[string]interface{}{
+				schema_definition.AttributeHTTPMaxConcurrentRequests: 1,
+				schema_definition.AttributeHTTPDialTimeout:           "30s",
+			},
+		},
+	})
+
+	cfg := httpConfigFromResourceData(d)
+	require.NotNil(t, cfg.MaxConcurrentRequests)
+	assert.Equal(t, int64(1), *cfg.MaxConcurrentRequests)
+	assert.Nil(t, cfg.MaxRetries)
+	assert.Equal(t, "30s", cfg.DialTimeout)
+	assert.Nil(t, cfg.retryableStatusCodes())
+}
+
+func TestHTTPConfigFromResourceDataWithoutRawConfigReadsRetryToggles(t *testing.T) {
+	sdk := NewSDK(versionTestSkippedLogin)()
+
+	d := schema.TestResourceDataRaw(t, sdk.Schema, map[string]interface{}{
+		schema_definition.AttributeHTTP: []interface{}{
+			map[string]interface{}{
+				schema_definition.AttributeHTTPRetryOn429: false,
+				schema_definition.AttributeHTTPRetryOn503: false,
+			},
+		},
+	})
+
+	cfg := httpConfigFromResourceData(d)
+	require.NotNil(t, cfg.RetryOn429)
+	assert.False(t, *cfg.RetryOn429)
+	assert.Nil(t, cfg.RetryOn500)
+	assert.Equal(t, []int{500}, cfg.retryableStatusCodes())
+}
+
+func TestHTTPConfigRetryableStatusCodesDefaultsWhenUnset(t *testing.T) {
+	assert.Nil(t, httpConfig{}.retryableStatusCodes())
+}
+
+func TestValidateHTTPConfig(t *testing.T) {
+	zero := int64(0)
+	negative := int64(-1)
+	lowFactor := 0.5
+
+	testData := map[string]struct {
+		cfg           httpConfig
+		expectedError string
+	}{
+		"empty":                   {cfg: httpConfig{}},
+		"zero-retries":            {cfg: httpConfig{MaxRetries: &zero}},
+		"no-concurrency":          {cfg: httpConfig{MaxConcurrentRequests: &zero}, expectedError: "max_concurrent_requests"},
+		"negative-retries":        {cfg: httpConfig{MaxRetries: &negative}, expectedError: "max_retries"},
+		"low-backoff-factor":      {cfg: httpConfig{RetryBackoffFactor: &lowFactor}, expectedError: "retry_backoff_factor"},
+		"invalid-attempt-timeout": {cfg: httpConfig{AttemptTimeout: "forever"}, expectedError: "attempt_timeout"},
+		"negative-dial-timeout":   {cfg: httpConfig{DialTimeout: "-5s"}, expectedError: "dial_timeout"},
+	}
+
+	for name, tt := range testData {
+		t.Run(name, func(t *testing.T) {
+			err := validateHTTPConfig(tt.cfg)
+			if len(tt.expectedError) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+//go:build offline
+
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureClientsReadOnly(t *testing.T) {
+	auditLogPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	cfg := providerConfig{
+		AuditLogPath:         auditLogPath,
+		ReadOnly:             true,
+		ClientImplementation: schema_definition.ClientImplementationMemory,
+	}
+
+	clients, err := configureClients(t.Context(), versionTestSkippedLogin, cfg)
+	require.NoError(t, err)
+
+	_, err = clients.PasswordManager.CreateFolder(t.Context(), models.Folder{Name: "Infrastructure"})
+	assert.ErrorIs(t, err, models.ErrReadOnlyVault)
+	_, err = clients.SecretsManager.CreateProject(t.Context(), models.Project{Name: "Backend"})
+	assert.ErrorIs(t, err, models.ErrReadOnlyVault)
+
+	content, err := os.ReadFile(auditLogPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"object_type":"folder","changed_attributes":["name"],"result":"failure","error_kind":"permission denied"`)
+}
+
+func TestReadOnlyFromEnvironment(t *testing.T) {
+	for value, expected := range map[string]bool{
+		"":      false,
+		"false": false,
+		"0":     false,
+		"true":  true,
+		"1":     true,
+		"yes":   true,
+	} {
+		t.Run(value, func(t *testing.T) {
+			t.Setenv("BW_READ_ONLY", value)
+			assert.Equal(t, expected, applyProviderConfigEnvDefaults(providerConfig{}).ReadOnly)
+		})
+	}
+
+	t.Setenv("BW_READ_ONLY", "false")
+	assert.True(t, applyProviderConfigEnvDefaults(providerConfig{ReadOnly: true}).ReadOnly)
+}
+
+func TestReadOnlyDeviceIdentifierIsNotStored(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg := providerConfig{Server: "https://vault.bitwarden.com", Email: "terraform@example.com", ReadOnly: true}
+
+	first, err := getOrGenerateDeviceIdentifier(t.Context(), cfg)
+	require.NoError(t, err)
+	second, err := getOrGenerateDeviceIdentifier(t.Context(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.NoDirExists(t, ".bitwarden")
+
+	cfg.Email = "someone-else@example.com"
+	other, err := getOrGenerateDeviceIdentifier(t.Context(), cfg)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, other)
+}
@@ -0,0 +1,223 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/transformation"
+)
+
+// itemServerAttributes are maintained by the server, or by other resources in
+// the case of attachments, and never conflict with an update. Items whose
+// revision only moved because of them are updated as usual.
+var itemServerAttributes = []string{
+	schema_definition.AttributeAttachments,
+	schema_definition.AttributeCreationDate,
+	schema_definition.AttributeDeletedDate,
+	schema_definition.AttributeRevisionDate,
+}
+
+// overwritesConflicts tells whether updates replace the changes made in the
+// Vault since Terraform last read an object, instead of failing.
+func (c *ProviderClients) overwritesConflicts() bool {
+	return c != nil && c.ConflictPolicy == schema_definition.ConflictPolicyOverwrite
+}
+
+// checkItemConflict fails when an item was modified in the Vault since its
+// state was last refreshed. Items whose revision date moved are compared
+// attribute by attribute. It returns the revision date to send along the
+// edition, for servers to detect changes made in the meantime.
+func checkItemConflict(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager, attrType models.ItemType) (*time.Time, error) {
+	knownRevision, _ := d.GetChange(schema_definition.AttributeRevisionDate)
+	knownRevisionString, _ := knownRevision.(string)
+	knownRevisionDate, err := time.Parse(models.DateLayout, knownRevisionString)
+	if err != nil {
+		// Items never read with a revision date can't be checked.
+		return nil, nil
+	}
+
+	remoteObj, err := bwClient.GetItem(ctx, transformation.ItemSchemaToObject(attrType)(ctx, d))
+	if err != nil {
+		return nil, fmt.Errorf("error reading item prior to edition: %w", err)
+	}
+
+	// The official Bitwarden server returns dates a few milliseconds apart
+	// between calls, and only considers items out of date beyond a second.
+	if remoteObj.RevisionDate == nil || remoteObj.RevisionDate.Sub(knownRevisionDate).Abs() <= time.Second {
+		return &knownRevisionDate, nil
+	}
+
+	// Resources merging fields don't mind changes to what they didn't manage,
+	// which their edition preserves.
+	if mergesFields(d) {
+		filteredObj := transformation.IgnoreUnmanagedItemAttributes(*remoteObj, previouslyManagedAttributes(ctx, d, attrType))
+		remoteObj = &filteredObj
+	}
+
+	changed, err := remoteChanges(ctx, d, remoteObj, transformation.ItemObjectToSchema, itemServerAttributes...)
+	if err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return remoteObj.RevisionDate, nil
+	}
+	return nil, conflictError(changed, remoteObj.RevisionDate, models.ObjectTypeItem, d.Id())
+}
+
+// checkOrganizationCollectionConflict fails when a collection was modified in
+// the Vault since its state was last refreshed. Collections don't have a
+// revision date, so its attributes are compared one by one.
+func checkOrganizationCollectionConflict(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager) error {
+	remoteObj, err := bwClient.GetOrganizationCollection(ctx, transformation.OrganizationCollectionToObject(ctx, d))
+	if err != nil {
+		return fmt.Errorf("error reading collection prior to edition: %w", err)
+	}
+
+	changed, err := remoteChanges(ctx, d, remoteObj, transformation.OrganizationCollectionObjectToSchema)
+	if err != nil || len(changed) == 0 {
+		return err
+	}
+	return conflictError(changed, nil, models.ObjectTypeOrgCollection, d.Id())
+}
+
+// checkFolderConflict fails when a folder was modified in the Vault since its
+// state was last refreshed.
+func checkFolderConflict(ctx context.Context, state *transformation.MapData, bwClient bitwarden.PasswordManager) error {
+	remoteObj, err := bwClient.GetFolder(ctx, transformation.SchemaToFolderObject(ctx, state))
+	if err != nil {
+		return fmt.Errorf("error reading folder prior to edition: %w", err)
+	}
+
+	remote := transformation.NewMapData(nil)
+	if err := transformation.FolderObjectToSchema(ctx, remoteObj, remote); err != nil {
+		return err
+	}
+	changed := changedAttributes(state.Get, remote.Values())
+	if len(changed) == 0 {
+		return nil
+	}
+	return conflictError(changed, nil, models.ObjectTypeFolder, state.Id())
+}
+
+// remoteChanges returns the attributes of an SDKv2 resource whose value in
+// the Vault differs from its prior state.
+func remoteChanges[T any](ctx context.Context, d *schema.ResourceData, remoteObj *T, fromObjToSchema objectToSchemaTransformation[T], ignored ...string) ([]string, error) {
+	remote := transformation.NewMapData(nil)
+	if err := fromObjToSchema(ctx, remoteObj, remote); err != nil {
+		return nil, err
+	}
+
+	return changedAttributes(func(name string) interface{} {
+		known, _ := d.GetChange(name)
+		return known
+	}, remote.Values(), ignored...), nil
+}
+
+// changedAttributes returns the names of the attributes whose value in remote
+// differs from the known one. Zero values are considered equal, and so are
+// sets with the same elements in a different order.
+func changedAttributes(known func(name string) interface{}, remote map[string]interface{}, ignored ...string) []string {
+	changed := []string{}
+	for name, remoteValue := range remote {
+		if slices.Contains(ignored, name) {
+			continue
+		}
+		knownValue := known(name)
+		_, unordered := knownValue.(*schema.Set)
+		if !reflect.DeepEqual(normalizeAttribute(knownValue, unordered), normalizeAttribute(remoteValue, unordered)) {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// normalizeAttribute converts the value of an attribute into its JSON
+// representation without zero values, sorting its elements when unordered.
+func normalizeAttribute(v interface{}, unordered bool) interface{} {
+	if set, ok := v.(*schema.Set); ok {
+		v = set.List()
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var value interface{}
+	if err := json.Unmarshal(out, &value); err != nil {
+		return v
+	}
+
+	value = withoutZeroValues(value)
+	if list, ok := value.([]interface{}); ok && unordered {
+		sort.Slice(list, func(i, j int) bool {
+			a, _ := json.Marshal(list[i])
+			b, _ := json.Marshal(list[j])
+			return string(a) < string(b)
+		})
+	}
+	return value
+}
+
+func withoutZeroValues(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for key, value := range vv {
+			if value = withoutZeroValues(value); value == nil {
+				delete(vv, key)
+			} else {
+				vv[key] = value
+			}
+		}
+		if len(vv) == 0 {
+			return nil
+		}
+	case []interface{}:
+		if len(vv) == 0 {
+			return nil
+		}
+		for i, value := range vv {
+			vv[i] = withoutZeroValues(value)
+		}
+	case string:
+		if len(vv) == 0 {
+			return nil
+		}
+	case bool:
+		if !vv {
+			return nil
+		}
+	case float64:
+		if vv == 0 {
+			return nil
+		}
+	}
+	return v
+}
+
+// conflictError reports the attributes modified in the Vault, but never their
+// values which may be secrets.
+func conflictError(changed []string, revisionDate *time.Time, objectType models.ObjectType, id string) error {
+	var details []string
+	if revisionDate != nil {
+		details = append(details, fmt.Sprintf("last revised on %s", revisionDate.Format(models.DateLayout)))
+	}
+	if len(changed) > 0 {
+		details = append(details, fmt.Sprintf("changed attributes: %s", strings.Join(changed, ", ")))
+	}
+
+	err := fmt.Errorf("%w since Terraform last read it", models.ErrObjectModified)
+	if len(details) > 0 {
+		err = fmt.Errorf("%w (%s)", err, strings.Join(details, "; "))
+	}
+	return models.WithObject(err, objectType, id)
+}
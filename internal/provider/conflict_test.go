@@ -0,0 +1,141 @@
+//go:build offline
+
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/embedded"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/transformation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemUpdateDetectsRemoteChanges(t *testing.T) {
+	vault, err := embedded.NewMemoryVault(t.Context(), "")
+	require.NoError(t, err)
+
+	item, err := vault.CreateItem(t.Context(), models.Item{
+		Type:   models.ItemTypeLogin,
+		Name:   "terraform",
+		Login:  models.Login{Username: "admin", Password: "secret"},
+		Object: models.ObjectTypeItem,
+	})
+	require.NoError(t, err)
+
+	// The state was refreshed before someone renamed the item in the Vault.
+	known := *item
+	knownRevisionDate := item.RevisionDate.Add(-time.Hour)
+	known.RevisionDate = &knownRevisionDate
+	remote := *item
+	remote.Name = "renamed"
+	remote.Login.Password = "changed"
+	_, err = vault.EditItem(t.Context(), remote)
+	require.NoError(t, err)
+
+	update := func(overwriteConflicts bool) string {
+		resource := resourceItemLogin()
+		d := resource.Data(nil)
+		require.NoError(t, transformation.ItemObjectToSchema(t.Context(), &known, d))
+		d, err := schema.InternalMap(resource.Schema).Data(d.State(), &terraform.InstanceDiff{
+			Attributes: map[string]*terraform.ResourceAttrDiff{
+				schema_definition.AttributeNotes: {New: "updated by Terraform"},
+			},
+		})
+		require.NoError(t, err)
+
+		var messages string
+		for _, diag := range opItemUpdate(models.ItemTypeLogin)(t.Context(), d, vault, overwriteConflicts) {
+			messages += diag.Summary + "\n" + diag.Detail + "\n"
+		}
+		return messages
+	}
+
+	messages := update(false)
+	assert.Contains(t, messages, models.ErrObjectModified.Error())
+	assert.Contains(t, messages, "changed attributes: name, password")
+
+	assert.Empty(t, update(true))
+	obj, err := vault.GetItem(t.Context(), models.Item{ID: item.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "terraform", obj.Name)
+	assert.Equal(t, "updated by Terraform", obj.Notes)
+}
+
+func TestItemUpdateIgnoresRevisionOnlyChanges(t *testing.T) {
+	vault, err := embedded.NewMemoryVault(t.Context(), "")
+	require.NoError(t, err)
+
+	item, err := vault.CreateItem(t.Context(), models.Item{
+		Type:   models.ItemTypeLogin,
+		Name:   "terraform",
+		Object: models.ObjectTypeItem,
+	})
+	require.NoError(t, err)
+
+	// Attachments are managed by other resources, and bump the revision of
+	// the item.
+	known := *item
+	knownRevisionDate := item.RevisionDate.Add(-time.Hour)
+	known.RevisionDate = &knownRevisionDate
+	_, err = vault.CreateAttachmentFromContent(t.Context(), item.ID, "attachment.txt", []byte("content"))
+	require.NoError(t, err)
+
+	resource := resourceItemLogin()
+	d := resource.Data(nil)
+	require.NoError(t, transformation.ItemObjectToSchema(t.Context(), &known, d))
+	d, err = schema.InternalMap(resource.Schema).Data(d.State(), &terraform.InstanceDiff{
+		Attributes: map[string]*terraform.ResourceAttrDiff{
+			schema_definition.AttributeNotes: {New: "updated by Terraform"},
+		},
+	})
+	require.NoError(t, err)
+
+	diags := opItemUpdate(models.ItemTypeLogin)(t.Context(), d, vault, false)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	obj, err := vault.GetItem(t.Context(), models.Item{ID: item.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "updated by Terraform", obj.Notes)
+}
+
+func TestFolderUpdateDetectsRemoteChanges(t *testing.T) {
+	vault, err := embedded.NewMemoryVault(t.Context(), "")
+	require.NoError(t, err)
+
+	folder, err := vault.CreateFolder(t.Context(), models.Folder{Name: "terraform", Object: models.ObjectTypeFolder})
+	require.NoError(t, err)
+
+	state := transformation.NewMapData(map[string]interface{}{schema_definition.AttributeName: folder.Name})
+	state.SetId(folder.ID)
+	require.NoError(t, checkFolderConflict(t.Context(), state, vault))
+
+	_, err = vault.EditFolder(t.Context(), models.Folder{ID: folder.ID, Name: "renamed", Object: models.ObjectTypeFolder})
+	require.NoError(t, err)
+
+	err = checkFolderConflict(t.Context(), state, vault)
+	assert.ErrorIs(t, err, models.ErrObjectModified)
+	assert.ErrorIs(t, err, models.ErrorKindConflict)
+	assert.Contains(t, err.Error(), "changed attributes: name")
+}
+
+func TestChangedAttributesIgnoresSetOrderAndZeroValues(t *testing.T) {
+	member := map[string]interface{}{"id": "a", "read_only": true, "hide_passwords": false}
+	otherMember := map[string]interface{}{"id": "b", "read_only": false}
+	known := map[string]interface{}{
+		"member": schema.NewSet(func(v interface{}) int { return schema.HashString(v.(map[string]interface{})["id"]) }, []interface{}{member, otherMember}),
+		"notes":  "",
+		"name":   "collection",
+	}
+
+	changed := changedAttributes(func(name string) interface{} { return known[name] }, map[string]interface{}{
+		"member": []interface{}{map[string]interface{}{"id": "b"}, map[string]interface{}{"id": "a", "read_only": true}},
+		"name":   "renamed",
+	})
+	assert.Equal(t, []string{"name"}, changed)
+}
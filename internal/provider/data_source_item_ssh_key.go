@@ -14,7 +14,7 @@ func dataSourceItemSSHKey() *schema.Resource {
 
 	return &schema.Resource{
 		Description: "Use this data source to get information on an existing SSH key item.",
-		ReadContext: withPasswordManager(opItemRead(models.ItemTypeSSHKey)),
+		ReadContext: withFeature(featureSSHKeyItems, withPasswordManager(opItemRead(models.ItemTypeSSHKey))),
 		Schema:      dataSourceItemSSHKeySchema,
 	}
 }
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
+)
+
+var (
+	_ datasource.DataSource              = &serverInfoDataSource{}
+	_ datasource.DataSourceWithConfigure = &serverInfoDataSource{}
+)
+
+type serverInfoDataSource struct {
+	clients *ProviderClients
+}
+
+func NewServerInfoDataSource() datasource.DataSource {
+	return &serverInfoDataSource{}
+}
+
+type serverInfoDataSourceModel struct {
+	ID                   types.String `tfsdk:"id"`
+	BWSVersion           types.String `tfsdk:"bws_version"`
+	CLIVersion           types.String `tfsdk:"cli_version"`
+	ClientImplementation types.String `tfsdk:"client_implementation"`
+	FeatureFlags         types.Map    `tfsdk:"feature_flags"`
+	ServerName           types.String `tfsdk:"server_name"`
+	ServerURL            types.String `tfsdk:"server_url"`
+	ServerVersion        types.String `tfsdk:"server_version"`
+}
+
+func (d *serverInfoDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_server_info"
+}
+
+func (d *serverInfoDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema_definition.ServerInfoDataSourceSchema()
+}
+
+func (d *serverInfoDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	clients, ok := clientsFromProviderData(req.ProviderData, &resp.Diagnostics)
+	if !ok {
+		return
+	}
+	d.clients = clients
+}
+
+func (d *serverInfoDataSource) Read(ctx context.Context, _ datasource.ReadRequest, resp *datasource.ReadResponse) {
+	capabilities := &Capabilities{}
+	if d.clients != nil && d.clients.Capabilities != nil {
+		capabilities = d.clients.Capabilities
+	}
+	capabilities.resolve(ctx)
+
+	featureFlags, diags := types.MapValueFrom(ctx, types.StringType, capabilities.FeatureFlags)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state := serverInfoDataSourceModel{
+		ID:                   types.StringValue(capabilities.ServerURL),
+		BWSVersion:           types.StringValue(capabilities.BWSVersion),
+		CLIVersion:           types.StringValue(capabilities.CLIVersion),
+		ClientImplementation: types.StringValue(capabilities.ClientImplementation),
+		FeatureFlags:         featureFlags,
+		ServerName:           types.StringValue(capabilities.ServerName),
+		ServerURL:            types.StringValue(capabilities.ServerURL),
+		ServerVersion:        types.StringValue(capabilities.ServerVersion),
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
@@ -0,0 +1,242 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
+)
+
+// deletionProtectedProviderServer refuses to plan or apply the destruction of
+// resources with deletion_protection, whether they are destroyed or replaced.
+// It sits in front of both halves of the mux, as SDKv2 resources aren't asked
+// about plans destroying them.
+type deletionProtectedProviderServer struct {
+	tfprotov6.ProviderServer
+
+	schemasMu sync.Mutex
+	schemas   *tfprotov6.GetProviderSchemaResponse
+
+	// defaultProtection applies to resources created before
+	// deletion_protection existed, which don't have it in their state.
+	defaultProtection atomic.Bool
+}
+
+func (s *deletionProtectedProviderServer) ConfigureProvider(ctx context.Context, req *tfprotov6.ConfigureProviderRequest) (*tfprotov6.ConfigureProviderResponse, error) {
+	schemas, err := s.providerSchemas(ctx)
+	if err != nil {
+		return &tfprotov6.ConfigureProviderResponse{Diagnostics: []*tfprotov6.Diagnostic{deletionProtectionUnavailable("the provider", err)}}, nil
+	}
+	if schemas.Provider != nil {
+		attrs, err := dynamicValueAttributes(req.Config, schemas.Provider)
+		if err != nil {
+			return &tfprotov6.ConfigureProviderResponse{Diagnostics: []*tfprotov6.Diagnostic{deletionProtectionUnavailable("the provider", err)}}, nil
+		}
+		s.defaultProtection.Store(boolAttribute(attrs, schema_definition.AttributeProviderDeletionProtection, false))
+	}
+	return s.ProviderServer.ConfigureProvider(ctx, req)
+}
+
+func (s *deletionProtectedProviderServer) PlanResourceChange(ctx context.Context, req *tfprotov6.PlanResourceChangeRequest) (*tfprotov6.PlanResourceChangeResponse, error) {
+	resp, err := s.ProviderServer.PlanResourceChange(ctx, req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+
+	if isNullDynamicValue(req.ProposedNewState) || len(resp.RequiresReplace) > 0 {
+		if diag := s.checkDeletionProtection(ctx, req.TypeName, req.PriorState); diag != nil {
+			resp.Diagnostics = append(resp.Diagnostics, diag)
+		}
+	}
+	return resp, nil
+}
+
+func (s *deletionProtectedProviderServer) ApplyResourceChange(ctx context.Context, req *tfprotov6.ApplyResourceChangeRequest) (*tfprotov6.ApplyResourceChangeResponse, error) {
+	if isNullDynamicValue(req.PlannedState) {
+		if diag := s.checkDeletionProtection(ctx, req.TypeName, req.PriorState); diag != nil {
+			return &tfprotov6.ApplyResourceChangeResponse{
+				NewState:    req.PriorState,
+				Private:     req.PlannedPrivate,
+				Diagnostics: []*tfprotov6.Diagnostic{diag},
+			}, nil
+		}
+	}
+	return s.ProviderServer.ApplyResourceChange(ctx, req)
+}
+
+// checkDeletionProtection returns an error diagnostic when a resource about to
+// be destroyed is protected.
+func (s *deletionProtectedProviderServer) checkDeletionProtection(ctx context.Context, typeName string, priorState *tfprotov6.DynamicValue) *tfprotov6.Diagnostic {
+	if isNullDynamicValue(priorState) {
+		return nil
+	}
+
+	schemas, err := s.providerSchemas(ctx)
+	if err != nil {
+		return deletionProtectionUnavailable(typeName, err)
+	}
+	resourceSchema, ok := schemas.ResourceSchemas[typeName]
+	if !ok || !hasAttribute(resourceSchema, schema_definition.AttributeDeletionProtection) {
+		return nil
+	}
+
+	attrs, err := dynamicValueAttributes(priorState, resourceSchema)
+	if err != nil {
+		return deletionProtectionUnavailable(typeName, err)
+	}
+	if !boolAttribute(attrs, schema_definition.AttributeDeletionProtection, s.defaultProtection.Load()) {
+		return nil
+	}
+
+	var id string
+	if v, ok := attrs[schema_definition.AttributeID]; ok && v.IsKnown() && !v.IsNull() {
+		_ = v.As(&id)
+	}
+	return &tfprotov6.Diagnostic{
+		Severity: tfprotov6.DiagnosticSeverityError,
+		Summary:  "Resource is protected against deletion",
+		Detail:   fmt.Sprintf("%s %q has `deletion_protection` enabled, which prevents destroying or replacing it. Set `deletion_protection = false` and apply that change first.", typeName, id),
+	}
+}
+
+// deletionProtectionUnavailable is the error diagnostic returned when the
+// configuration or state can't be read, as deletion protection would
+// otherwise be silently ignored.
+func deletionProtectionUnavailable(what string, err error) *tfprotov6.Diagnostic {
+	return &tfprotov6.Diagnostic{
+		Severity: tfprotov6.DiagnosticSeverityError,
+		Summary:  "Unable to check deletion protection",
+		Detail:   fmt.Sprintf("Unable to read the configuration or state of %s: %v. Please report this issue to the provider developers.", what, err),
+	}
+}
+
+// providerSchemas returns the schemas of the provider. Failures aren't cached,
+// so that the next call tries again.
+func (s *deletionProtectedProviderServer) providerSchemas(ctx context.Context) (*tfprotov6.GetProviderSchemaResponse, error) {
+	s.schemasMu.Lock()
+	defer s.schemasMu.Unlock()
+	if s.schemas != nil {
+		return s.schemas, nil
+	}
+
+	resp, err := s.ProviderServer.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("no provider schema returned")
+	}
+	for _, diag := range resp.Diagnostics {
+		if diag.Severity == tfprotov6.DiagnosticSeverityError {
+			return nil, fmt.Errorf("%s: %s", diag.Summary, diag.Detail)
+		}
+	}
+	s.schemas = resp
+	return resp, nil
+}
+
+func hasAttribute(s *tfprotov6.Schema, name string) bool {
+	if s == nil || s.Block == nil {
+		return false
+	}
+	for _, attr := range s.Block.Attributes {
+		if attr.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func dynamicValueAttributes(v *tfprotov6.DynamicValue, s *tfprotov6.Schema) (map[string]tftypes.Value, error) {
+	value, err := v.Unmarshal(s.ValueType())
+	if err != nil {
+		return nil, err
+	}
+	attrs := map[string]tftypes.Value{}
+	if value.IsNull() || !value.IsKnown() {
+		return attrs, nil
+	}
+	if err := value.As(&attrs); err != nil {
+		return nil, err
+	}
+	return attrs, nil
+}
+
+// boolAttribute returns the value of a boolean attribute, or def when it's
+// null, unknown or missing.
+func boolAttribute(attrs map[string]tftypes.Value, name string, def bool) bool {
+	v, ok := attrs[name]
+	if !ok || !v.IsKnown() || v.IsNull() {
+		return def
+	}
+	var b bool
+	if err := v.As(&b); err != nil {
+		return def
+	}
+	return b
+}
+
+// resolveDeletionProtection returns the deletion_protection of a Framework
+// resource, which is the provider's default when it isn't set.
+func resolveDeletionProtection(v types.Bool, clients *ProviderClients) types.Bool {
+	if !v.IsNull() && !v.IsUnknown() {
+		return v
+	}
+	return types.BoolValue(clients != nil && clients.DeletionProtection)
+}
+
+// modifyDeletionProtectionPlan plans the provider's default deletion_protection
+// for Framework resources which don't set it, so that it's visible in plans.
+func modifyDeletionProtectionPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, clients *ProviderClients) {
+	if req.Plan.Raw.IsNull() || clients == nil {
+		return
+	}
+
+	var configured types.Bool
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(schema_definition.AttributeDeletionProtection), &configured)...)
+	if resp.Diagnostics.HasError() || !configured.IsNull() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(schema_definition.AttributeDeletionProtection), types.BoolValue(clients.DeletionProtection))...)
+}
+
+// onlyDeletionProtectionChanged tells whether an update of a Framework resource
+// only changes deletion_protection, which isn't stored in the Vault.
+func onlyDeletionProtectionChanged(plan, state tftypes.Value) bool {
+	var planAttrs, stateAttrs map[string]tftypes.Value
+	if plan.As(&planAttrs) != nil || state.As(&stateAttrs) != nil {
+		return false
+	}
+	for name, planValue := range planAttrs {
+		if name == schema_definition.AttributeDeletionProtection {
+			continue
+		}
+		if stateValue, ok := stateAttrs[name]; !ok || !planValue.Equal(stateValue) {
+			return false
+		}
+	}
+	return true
+}
+
+// customizeDeletionProtectionDiff plans the provider's default
+// deletion_protection for SDKv2 resources which don't set it.
+func customizeDeletionProtectionDiff(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	clients, ok := meta.(*ProviderClients)
+	if !ok {
+		return nil
+	}
+
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() || !config.GetAttr(schema_definition.AttributeDeletionProtection).IsNull() {
+		return nil
+	}
+	return d.SetNew(schema_definition.AttributeDeletionProtection, clients.DeletionProtection)
+}
@@ -0,0 +1,276 @@
+//go:build offline
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeletionProtectionRefusesToDestroy(t *testing.T) {
+	server := newTestProviderServer(t)
+
+	for _, typeName := range []string{"bitwarden_folder", "bitwarden_item_login", "bitwarden_org_collection"} {
+		t.Run(typeName, func(t *testing.T) {
+			protected := testResourceState(t, server, typeName, map[string]tftypes.Value{
+				schema_definition.AttributeID:                 tftypes.NewValue(tftypes.String, "0f4e8c25-6db1-4e3b-8c5a-2c0b1f2e3d4a"),
+				schema_definition.AttributeDeletionProtection: tftypes.NewValue(tftypes.Bool, true),
+			})
+
+			planResp, err := server.PlanResourceChange(t.Context(), &tfprotov6.PlanResourceChangeRequest{
+				TypeName:         typeName,
+				PriorState:       protected,
+				ProposedNewState: testResourceState(t, server, typeName, nil),
+				Config:           testResourceState(t, server, typeName, nil),
+			})
+			require.NoError(t, err)
+			assertDeletionProtectionError(t, planResp.Diagnostics)
+
+			applyResp, err := server.ApplyResourceChange(t.Context(), &tfprotov6.ApplyResourceChangeRequest{
+				TypeName:     typeName,
+				PriorState:   protected,
+				PlannedState: testResourceState(t, server, typeName, nil),
+				Config:       testResourceState(t, server, typeName, nil),
+			})
+			require.NoError(t, err)
+			assertDeletionProtectionError(t, applyResp.Diagnostics)
+			assert.Equal(t, protected, applyResp.NewState)
+		})
+	}
+}
+
+func TestDeletionProtectionAllowsUnprotectedDestroy(t *testing.T) {
+	server := newTestProviderServer(t)
+
+	resp, err := server.PlanResourceChange(t.Context(), &tfprotov6.PlanResourceChangeRequest{
+		TypeName: "bitwarden_item_login",
+		PriorState: testResourceState(t, server, "bitwarden_item_login", map[string]tftypes.Value{
+			schema_definition.AttributeID:                 tftypes.NewValue(tftypes.String, "0f4e8c25-6db1-4e3b-8c5a-2c0b1f2e3d4a"),
+			schema_definition.AttributeDeletionProtection: tftypes.NewValue(tftypes.Bool, false),
+		}),
+		ProposedNewState: testResourceState(t, server, "bitwarden_item_login", nil),
+		Config:           testResourceState(t, server, "bitwarden_item_login", nil),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Diagnostics)
+}
+
+func TestDeletionProtectionRefusesToReplace(t *testing.T) {
+	server := newTestProviderServer(t)
+
+	// Changing the organization of an item forces its replacement.
+	attrs := map[string]tftypes.Value{
+		schema_definition.AttributeID:                 tftypes.NewValue(tftypes.String, "0f4e8c25-6db1-4e3b-8c5a-2c0b1f2e3d4a"),
+		schema_definition.AttributeDeletionProtection: tftypes.NewValue(tftypes.Bool, true),
+		schema_definition.AttributeOrganizationID:     tftypes.NewValue(tftypes.String, "b5a5d2e2-4c3e-4a54-9d4c-8f7c1e0e1a11"),
+	}
+	prior := testResourceState(t, server, "bitwarden_item_login", attrs)
+	attrs[schema_definition.AttributeOrganizationID] = tftypes.NewValue(tftypes.String, "c6b6e3f3-5d4f-4b65-8e5d-9a8d2f1f2b22")
+	proposed := testResourceState(t, server, "bitwarden_item_login", attrs)
+
+	resp, err := server.PlanResourceChange(t.Context(), &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         "bitwarden_item_login",
+		PriorState:       prior,
+		ProposedNewState: proposed,
+		Config:           proposed,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.RequiresReplace)
+	assertDeletionProtectionError(t, resp.Diagnostics)
+}
+
+func TestDeletionProtectionOnlyChangeDoesntWriteToTheVault(t *testing.T) {
+	server := newTestProviderServer(t)
+
+	// Read-only clients fail on any write to the Vault.
+	configureTestProviderServer(t, server, map[string]tftypes.Value{
+		schema_definition.AttributeClientImplementation: tftypes.NewValue(tftypes.String, schema_definition.ClientImplementationMemory),
+		schema_definition.AttributeConflictPolicy:       tftypes.NewValue(tftypes.String, schema_definition.ConflictPolicyOverwrite),
+		schema_definition.AttributeReadOnly:             tftypes.NewValue(tftypes.Bool, true),
+	})
+
+	attrs := map[string]tftypes.Value{
+		schema_definition.AttributeID:                 tftypes.NewValue(tftypes.String, "0f4e8c25-6db1-4e3b-8c5a-2c0b1f2e3d4a"),
+		schema_definition.AttributeName:               tftypes.NewValue(tftypes.String, "Databases"),
+		schema_definition.AttributeDeletionProtection: tftypes.NewValue(tftypes.Bool, false),
+	}
+	prior := testResourceState(t, server, "bitwarden_folder", attrs)
+	attrs[schema_definition.AttributeDeletionProtection] = tftypes.NewValue(tftypes.Bool, true)
+	planned := testResourceState(t, server, "bitwarden_folder", attrs)
+
+	resp, err := server.ApplyResourceChange(t.Context(), &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:     "bitwarden_folder",
+		PriorState:   prior,
+		PlannedState: planned,
+		Config:       planned,
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.Diagnostics)
+	assert.Equal(t, planned, resp.NewState)
+
+	// Any other change is written to the Vault.
+	attrs[schema_definition.AttributeName] = tftypes.NewValue(tftypes.String, "Servers")
+	planned = testResourceState(t, server, "bitwarden_folder", attrs)
+	resp, err = server.ApplyResourceChange(t.Context(), &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:     "bitwarden_folder",
+		PriorState:   prior,
+		PlannedState: planned,
+		Config:       planned,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Diagnostics)
+	assert.Contains(t, resp.Diagnostics[0].Detail, "read-only")
+}
+
+type failingSchemaProviderServer struct {
+	tfprotov6.ProviderServer
+}
+
+func (failingSchemaProviderServer) GetProviderSchema(_ context.Context, _ *tfprotov6.GetProviderSchemaRequest) (*tfprotov6.GetProviderSchemaResponse, error) {
+	return nil, errors.New("schema unavailable")
+}
+
+func TestDeletionProtectionFailsClosed(t *testing.T) {
+	server := &deletionProtectedProviderServer{ProviderServer: failingSchemaProviderServer{}}
+
+	objectType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{schema_definition.AttributeID: tftypes.String}}
+	state, err := tfprotov6.NewDynamicValue(objectType, tftypes.NewValue(objectType, map[string]tftypes.Value{
+		schema_definition.AttributeID: tftypes.NewValue(tftypes.String, "0f4e8c25-6db1-4e3b-8c5a-2c0b1f2e3d4a"),
+	}))
+	require.NoError(t, err)
+
+	configureResp, err := server.ConfigureProvider(t.Context(), &tfprotov6.ConfigureProviderRequest{Config: &state})
+	require.NoError(t, err)
+	assertDeletionProtectionUnavailable(t, configureResp.Diagnostics)
+
+	applyResp, err := server.ApplyResourceChange(t.Context(), &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:     "bitwarden_folder",
+		PriorState:   &state,
+		PlannedState: nil,
+	})
+	require.NoError(t, err)
+	assertDeletionProtectionUnavailable(t, applyResp.Diagnostics)
+	assert.Equal(t, &state, applyResp.NewState)
+
+	// A configuration that can't be decoded doesn't disable the protection
+	// either.
+	server = newTestProviderServer(t)
+	configureResp, err = server.ConfigureProvider(t.Context(), &tfprotov6.ConfigureProviderRequest{Config: &state})
+	require.NoError(t, err)
+	assertDeletionProtectionUnavailable(t, configureResp.Diagnostics)
+}
+
+func TestDeletionProtectionDefaultsToTheProvider(t *testing.T) {
+	server := newTestProviderServer(t)
+
+	configureTestProviderServer(t, server, map[string]tftypes.Value{
+		schema_definition.AttributeClientImplementation:       tftypes.NewValue(tftypes.String, schema_definition.ClientImplementationMemory),
+		schema_definition.AttributeProviderDeletionProtection: tftypes.NewValue(tftypes.Bool, true),
+	})
+
+	// Resources created before deletion_protection existed don't have it in
+	// their state.
+	resp, err := server.ApplyResourceChange(t.Context(), &tfprotov6.ApplyResourceChangeRequest{
+		TypeName: "bitwarden_secret",
+		PriorState: testResourceState(t, server, "bitwarden_secret", map[string]tftypes.Value{
+			schema_definition.AttributeID: tftypes.NewValue(tftypes.String, "0f4e8c25-6db1-4e3b-8c5a-2c0b1f2e3d4a"),
+		}),
+		PlannedState: testResourceState(t, server, "bitwarden_secret", nil),
+		Config:       testResourceState(t, server, "bitwarden_secret", nil),
+	})
+	require.NoError(t, err)
+	assertDeletionProtectionError(t, resp.Diagnostics)
+}
+
+func newTestProviderServer(t *testing.T) *deletionProtectedProviderServer {
+	t.Helper()
+
+	factory, err := NewProviderServer(versionTestSkippedLogin)
+	require.NoError(t, err)
+	traced, ok := factory().(*tracedProviderServer)
+	require.True(t, ok)
+	server, ok := traced.ProviderServer.(*deletionProtectedProviderServer)
+	require.True(t, ok)
+	return server
+}
+
+// configureTestProviderServer configures the provider with the given
+// attributes, the others being null.
+func configureTestProviderServer(t *testing.T, server *deletionProtectedProviderServer, attrs map[string]tftypes.Value) {
+	t.Helper()
+
+	schemas, err := server.providerSchemas(t.Context())
+	require.NoError(t, err)
+	providerType := schemas.Provider.ValueType().(tftypes.Object)
+	providerValues := map[string]tftypes.Value{}
+	for name, typ := range providerType.AttributeTypes {
+		providerValues[name] = tftypes.NewValue(typ, nil)
+	}
+	for name, v := range attrs {
+		providerValues[name] = v
+	}
+	config, err := tfprotov6.NewDynamicValue(providerType, tftypes.NewValue(providerType, providerValues))
+	require.NoError(t, err)
+
+	resp, err := server.ConfigureProvider(t.Context(), &tfprotov6.ConfigureProviderRequest{Config: &config})
+	require.NoError(t, err)
+	require.Empty(t, resp.Diagnostics)
+}
+
+// testResourceState returns a state of a resource with the given attributes,
+// the others being null. No attributes returns a null state.
+func testResourceState(t *testing.T, server *deletionProtectedProviderServer, typeName string, attrs map[string]tftypes.Value) *tfprotov6.DynamicValue {
+	t.Helper()
+
+	schemas, err := server.providerSchemas(t.Context())
+	require.NoError(t, err)
+	resourceSchema, ok := schemas.ResourceSchemas[typeName]
+	require.True(t, ok, "missing schema for %s", typeName)
+	objectType := resourceSchema.ValueType().(tftypes.Object)
+
+	value := tftypes.NewValue(objectType, nil)
+	if attrs != nil {
+		values := map[string]tftypes.Value{}
+		for name, typ := range objectType.AttributeTypes {
+			values[name] = tftypes.NewValue(typ, nil)
+		}
+		for name, v := range attrs {
+			values[name] = v
+		}
+		value = tftypes.NewValue(objectType, values)
+	}
+
+	state, err := tfprotov6.NewDynamicValue(objectType, value)
+	require.NoError(t, err)
+	return &state
+}
+
+func assertDeletionProtectionError(t *testing.T, diags []*tfprotov6.Diagnostic) {
+	t.Helper()
+
+	for _, diag := range diags {
+		if diag.Severity == tfprotov6.DiagnosticSeverityError && diag.Summary == "Resource is protected against deletion" {
+			assert.Contains(t, diag.Detail, "0f4e8c25-6db1-4e3b-8c5a-2c0b1f2e3d4a")
+			return
+		}
+	}
+	assert.Fail(t, "missing deletion protection error", "diagnostics: %v", diags)
+}
+
+func assertDeletionProtectionUnavailable(t *testing.T, diags []*tfprotov6.Diagnostic) {
+	t.Helper()
+
+	for _, diag := range diags {
+		if diag.Severity == tfprotov6.DiagnosticSeverityError && diag.Summary == "Unable to check deletion protection" {
+			return
+		}
+	}
+	assert.Fail(t, "missing deletion protection error", "diagnostics: %v", diags)
+}
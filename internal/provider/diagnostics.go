@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	sdkdiag "github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+)
+
+// remediationHints tell users what to check for each kind of error, so that
+// they don't need TF_LOG=trace to find out.
+var remediationHints = map[models.ErrorKind]string{
+	models.ErrorKindAuthFailure:      "Check the credentials of the provider, and that they belong to an account of the server it is configured with.",
+	models.ErrorKindConflict:         "The object was modified by someone else in the meantime. Refresh the state to review the changes and apply again, or set `conflict_policy = \"overwrite\"` to replace them.",
+	models.ErrorKindCryptoFailure:    "The object couldn't be encrypted or decrypted with the keys of the account. Check that the account is a member of the organization owning it, and that the Vault is in sync.",
+	models.ErrorKindNotFound:         "Check that the identifier is correct, and that the account of the provider has access to the object, e.g. through its collections.",
+	models.ErrorKindPermissionDenied: "The account of the provider isn't allowed to do this, or the provider is read-only. Check its role in the organization, the permissions it has on the collections involved, and the `read_only` and `client_implementation` attributes of the provider.",
+	models.ErrorKindRateLimited:      "The Bitwarden Server is rate limiting requests. Retry later, or reduce the number of concurrent operations with `terraform apply -parallelism=N`.",
+	models.ErrorKindTransportFailure: "The Bitwarden Server couldn't be reached. Check the server URL, the proxy and TLS settings of the provider, and the network connectivity.",
+	models.ErrorKindUnsupported:      "Use a client implementation, or versions of the CLIs and of the server, supporting this feature. The `bitwarden_server_info` data source shows which ones are used.",
+}
+
+// errorDiagnostic returns the summary and detail of the diagnostic reporting
+// an error. The summary is the message of the error, which the historical
+// SDKv2 formatting used and acceptance tests assert against. The detail
+// names the object involved, and how to fix the error when its kind is known.
+func errorDiagnostic(err error) (string, string) {
+	details := []string{}
+	if objectType, objectID := models.ObjectOf(err); len(objectType) > 0 {
+		if len(objectID) > 0 {
+			details = append(details, fmt.Sprintf("Object: %s '%s'", objectType, objectID))
+		} else {
+			details = append(details, fmt.Sprintf("Object: %s", objectType))
+		}
+	}
+	if kind, ok := models.ErrorKindOf(err); ok {
+		details = append(details, fmt.Sprintf("Cause: %s", kind))
+		details = append(details, remediationHints[kind])
+	}
+	return err.Error(), strings.Join(details, "\n")
+}
+
+// addErr reports a client/API error as a Framework diagnostic.
+func addErr(diags *diag.Diagnostics, err error) {
+	diags.AddError(errorDiagnostic(err))
+}
+
+// diagFromErr is the SDKv2 equivalent of addErr. It returns nil when err is
+// nil, like sdkdiag.FromErr.
+func diagFromErr(err error) sdkdiag.Diagnostics {
+	if err == nil {
+		return nil
+	}
+	summary, detail := errorDiagnostic(err)
+	return sdkdiag.Diagnostics{
+		sdkdiag.Diagnostic{
+			Severity: sdkdiag.Error,
+			Summary:  summary,
+			Detail:   detail,
+		},
+	}
+}
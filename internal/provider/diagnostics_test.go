@@ -0,0 +1,30 @@
+//go:build offline
+
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorDiagnostic(t *testing.T) {
+	err := fmt.Errorf("error reading collection: %w", models.WithObject(models.ErrObjectNotFound, models.ObjectTypeOrgCollection, "collection-id"))
+	summary, detail := errorDiagnostic(err)
+	assert.Equal(t, "error reading collection: object not found", summary)
+	assert.Equal(t, "Object: org-collection 'collection-id'\nCause: not found\n"+remediationHints[models.ErrorKindNotFound], detail)
+
+	summary, detail = errorDiagnostic(errors.New("something unexpected"))
+	assert.Equal(t, "something unexpected", summary)
+	assert.Empty(t, detail)
+
+	diags := diagFromErr(models.ErrItemKeyRotationUnsupported)
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, "rotating item keys is only supported by the embedded client", diags[0].Summary)
+		assert.Contains(t, diags[0].Detail, "Cause: unsupported feature")
+	}
+	assert.Nil(t, diagFromErr(nil))
+}
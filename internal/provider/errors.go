@@ -1,8 +1,13 @@
 package provider
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+)
 
 var (
 	errPasswordManagerRequired = errors.New("provider was not configured with Password Manager credentials")
 	errSecretsManagerRequired  = errors.New("provider was not configured with Secrets Manager credentials")
+	errFeatureUnsupported      = models.NewError(models.ErrorKindUnsupported, errors.New("feature not supported by the configured backend"))
 )
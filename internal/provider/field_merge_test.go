@@ -0,0 +1,122 @@
+//go:build offline
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/embedded"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/transformation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldMergePreservesUnmanagedAttributes(t *testing.T) {
+	vault, err := embedded.NewMemoryVault(t.Context(), "")
+	require.NoError(t, err)
+
+	// Terraform only declares the password and one of the URIs.
+	declared, err := vault.CreateItem(t.Context(), models.Item{
+		Type:   models.ItemTypeLogin,
+		Name:   "shared",
+		Login:  models.Login{Password: "secret", URIs: []models.LoginURI{{URI: "https://example.com"}}},
+		Object: models.ObjectTypeItem,
+	})
+	require.NoError(t, err)
+
+	edited := *declared
+	edited.Notes = "maintained by humans"
+	edited.Fields = []models.Field{{Name: "owner", Value: "team", Type: models.FieldTypeText}}
+	edited.Login.URIs = append(edited.Login.URIs, models.LoginURI{URI: "https://example.com/login"})
+	editedObj, err := vault.EditItem(t.Context(), edited)
+	require.NoError(t, err)
+
+	known := *declared
+	known.RevisionDate = editedObj.RevisionDate
+	d := testItemLoginUpdate(t, known, map[string]*terraform.ResourceAttrDiff{
+		schema_definition.AttributeFieldMerge:    {New: schema_definition.FieldMergeMerge},
+		schema_definition.AttributeLoginPassword: {Old: "secret", New: "rotated"},
+	})
+
+	diags := opItemUpdate(models.ItemTypeLogin)(t.Context(), d, vault, false)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	obj, err := vault.GetItem(t.Context(), models.Item{ID: declared.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", obj.Login.Password)
+	assert.Equal(t, "maintained by humans", obj.Notes)
+	assert.Equal(t, editedObj.Fields, obj.Fields)
+	assert.Len(t, obj.Login.URIs, 2)
+
+	// What Terraform doesn't declare stays out of its state.
+	assert.Empty(t, d.Get(schema_definition.AttributeNotes))
+	assert.Empty(t, d.Get(schema_definition.AttributeField))
+	assert.Len(t, d.Get(schema_definition.AttributeLoginURIs), 1)
+
+	diags = opItemRead(models.ItemTypeLogin)(t.Context(), d, vault)
+	require.False(t, diags.HasError(), "%v", diags)
+	assert.Empty(t, d.Get(schema_definition.AttributeNotes))
+	assert.Empty(t, d.Get(schema_definition.AttributeField))
+	assert.Len(t, d.Get(schema_definition.AttributeLoginURIs), 1)
+}
+
+func TestFieldMergeRemovesPreviouslyDeclaredAttributes(t *testing.T) {
+	vault, err := embedded.NewMemoryVault(t.Context(), "")
+	require.NoError(t, err)
+
+	// Terraform declares the notes, a custom field and one of the URIs.
+	declared, err := vault.CreateItem(t.Context(), models.Item{
+		Type:   models.ItemTypeLogin,
+		Name:   "shared",
+		Notes:  "managed by Terraform",
+		Fields: []models.Field{{Name: "api_key", Value: "secret", Type: models.FieldTypeHidden}},
+		Login:  models.Login{Password: "secret", URIs: []models.LoginURI{{URI: "https://example.com"}}},
+		Object: models.ObjectTypeItem,
+	})
+	require.NoError(t, err)
+
+	edited := *declared
+	edited.Fields = append(edited.Fields, models.Field{Name: "owner", Value: "team", Type: models.FieldTypeText})
+	edited.Login.URIs = append(edited.Login.URIs, models.LoginURI{URI: "https://example.com/login"})
+	editedObj, err := vault.EditItem(t.Context(), edited)
+	require.NoError(t, err)
+
+	known := *declared
+	known.RevisionDate = editedObj.RevisionDate
+	d := testItemLoginUpdate(t, known, map[string]*terraform.ResourceAttrDiff{
+		schema_definition.AttributeFieldMerge:           {New: schema_definition.FieldMergeMerge},
+		schema_definition.AttributeNotes:                {Old: "managed by Terraform", New: ""},
+		schema_definition.AttributeField + ".#":         {Old: "1", New: "0"},
+		schema_definition.AttributeField + ".0.name":    {Old: "api_key", NewRemoved: true},
+		schema_definition.AttributeLoginURIs + ".#":     {Old: "1", New: "0"},
+		schema_definition.AttributeLoginURIs + ".0.uri": {Old: "https://example.com", NewRemoved: true},
+	})
+
+	diags := opItemUpdate(models.ItemTypeLogin)(t.Context(), d, vault, false)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	// What Terraform no longer declares is removed, what it never managed is
+	// preserved.
+	obj, err := vault.GetItem(t.Context(), models.Item{ID: declared.ID})
+	require.NoError(t, err)
+	assert.Empty(t, obj.Notes)
+	assert.Equal(t, []models.Field{{Name: "owner", Value: "team", Type: models.FieldTypeText}}, obj.Fields)
+	assert.Equal(t, []models.LoginURI{{URI: "https://example.com/login"}}, obj.Login.URIs)
+}
+
+// testItemLoginUpdate returns the data of a login item resource whose state
+// holds known, being updated with the given changes.
+func testItemLoginUpdate(t *testing.T, known models.Item, changes map[string]*terraform.ResourceAttrDiff) *schema.ResourceData {
+	t.Helper()
+
+	resource := resourceItemLogin()
+	d := resource.Data(nil)
+	require.NoError(t, transformation.ItemObjectToSchema(t.Context(), &known, d))
+	d, err := schema.InternalMap(resource.Schema).Data(d.State(), &terraform.InstanceDiff{Attributes: changes})
+	require.NoError(t, err)
+	return d
+}
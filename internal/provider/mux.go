@@ -33,5 +33,7 @@ func NewProviderServer(version string) (func() tfprotov6.ProviderServer, error)
 		return nil, fmt.Errorf("mux Framework and SDKv2 providers: %w", err)
 	}
 
-	return muxServer.ProviderServer, nil
+	return func() tfprotov6.ProviderServer {
+		return &tracedProviderServer{ProviderServer: &deletionProtectedProviderServer{ProviderServer: muxServer.ProviderServer()}}
+	}, nil
 }
@@ -36,16 +36,16 @@ func opAttachmentCreate(ctx context.Context, d *schema.ResourceData, bwClient bi
 		err = errors.New("BUG: either file or content&file_name should be specified")
 	}
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
-	return diag.FromErr(transformation.AttachmentObjectToSchema(ctx, *obj, d))
+	return diagFromErr(transformation.AttachmentObjectToSchema(ctx, *obj, d))
 }
 
 func opAttachmentDelete(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager) diag.Diagnostics {
 	itemId := d.Get(schema_definition.AttributeAttachmentItemID).(string)
 
-	return diag.FromErr(bwClient.DeleteAttachment(ctx, itemId, d.Id()))
+	return diagFromErr(bwClient.DeleteAttachment(ctx, itemId, d.Id()))
 }
 
 func opAttachmentImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
@@ -70,12 +70,12 @@ func opAttachmentRead(ctx context.Context, d *schema.ResourceData, bwClient bitw
 
 	content, err := bwClient.GetAttachment(ctx, itemId, attachmentId)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
 	d.SetId(attachmentId)
 
-	return diag.FromErr(d.Set(schema_definition.AttributeAttachmentContent, string(content)))
+	return diagFromErr(d.Set(schema_definition.AttributeAttachmentContent, string(content)))
 }
 
 func opAttachmentReadIgnoreMissing(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager) diag.Diagnostics {
@@ -87,12 +87,12 @@ func opAttachmentReadIgnoreMissing(ctx context.Context, d *schema.ResourceData,
 		// deleted, because we won't have an item to attach it to.
 		// This means we don't need a special handling for NotFound errors and
 		// should just return whatever we get.
-		return diag.FromErr(err)
+		return diagFromErr(err)
 	}
 
 	for _, attachment := range obj.Attachments {
 		if attachment.ID == d.Id() {
-			return diag.FromErr(transformation.AttachmentObjectToSchema(ctx, attachment, d))
+			return diagFromErr(transformation.AttachmentObjectToSchema(ctx, attachment, d))
 		}
 	}
 
@@ -138,7 +138,7 @@ func contentHash(val interface{}) string {
 func fileHashComputable(val interface{}, _ cty.Path) diag.Diagnostics {
 	_, err := fileSha1Sum(val.(string))
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("unable to compute hash of file: %w", err))
+		return diagFromErr(fmt.Errorf("unable to compute hash of file: %w", err))
 	}
 	return diag.Diagnostics{}
 }
@@ -67,7 +67,7 @@ func ignoreMissing(ctx context.Context, d *schema.ResourceData, err error) diag.
 		return diag.Diagnostics{}
 	}
 
-	return diag.FromErr(err)
+	return diagFromErr(err)
 }
 
 func resourceImporter(stateContext schema.StateContextFunc) *schema.ResourceImporter {
@@ -75,3 +75,31 @@ func resourceImporter(stateContext schema.StateContextFunc) *schema.ResourceImpo
 		StateContext: stateContext,
 	}
 }
+
+// priorState reads the values an SDKv2 resource had before the current change,
+// to rebuild the object as Terraform last knew it.
+type priorState struct {
+	d *schema.ResourceData
+}
+
+var _ transformation.AttrData = priorState{}
+
+func (p priorState) Id() string   { return p.d.Id() }
+func (p priorState) SetId(string) {}
+
+func (p priorState) Get(key string) interface{} {
+	known, _ := p.d.GetChange(key)
+	return known
+}
+
+func (p priorState) GetOk(key string) (interface{}, bool) {
+	known := p.Get(key)
+	if set, ok := known.(*schema.Set); ok {
+		return known, set.Len() > 0
+	}
+	return transformation.NewMapData(map[string]interface{}{key: known}).GetOk(key)
+}
+
+func (p priorState) Set(string, interface{}) error {
+	return errors.New("INTERNAL BUG: the prior state of a resource can't be modified")
+}
@@ -2,10 +2,13 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/audit"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/transformation"
@@ -13,13 +16,13 @@ import (
 
 func opItemCreate(attrType models.ItemType) passwordManagerOperation {
 	return func(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager) diag.Diagnostics {
-		return diag.FromErr(applyOperation(ctx, d, bwClient.CreateItem, transformation.ItemSchemaToObject(attrType), transformation.ItemObjectToSchema))
+		return diagFromErr(applyOperation(ctx, d, bwClient.CreateItem, transformation.ItemSchemaToObject(attrType), transformation.ItemObjectToSchema))
 	}
 }
 
 func opItemDelete(attrType models.ItemType) passwordManagerOperation {
 	return func(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager) diag.Diagnostics {
-		return diag.FromErr(applyOperation(ctx, d, withNilReturn(bwClient.DeleteItem), transformation.ItemSchemaToObject(attrType), transformation.ItemObjectToSchema))
+		return diagFromErr(applyOperation(ctx, d, withNilReturn(bwClient.DeleteItem), transformation.ItemSchemaToObject(attrType), transformation.ItemObjectToSchema))
 	}
 }
 
@@ -32,20 +35,110 @@ func opItemRead(attrType models.ItemType) passwordManagerOperation {
 	return func(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager) diag.Diagnostics {
 		d.SetId(d.Get(schema_definition.AttributeID).(string))
 		if _, idProvided := d.GetOk(schema_definition.AttributeID); !idProvided {
-			return diag.FromErr(searchItemOperation(ctx, d, bwClient.FindItem, transformation.ItemObjectToSchema, attrType))
+			return diagFromErr(searchItemOperation(ctx, d, bwClient.FindItem, transformation.ItemObjectToSchema, attrType))
 		}
-		return diag.FromErr(applyOperation(ctx, d, bwClient.GetItem, transformation.ItemSchemaToObject(attrType), transformation.ItemObjectToSchema))
+		return diagFromErr(applyOperation(ctx, d, ignoringUnmanagedAttributes(d, bwClient.GetItem), transformation.ItemSchemaToObject(attrType), transformation.ItemObjectToSchema))
 	}
 }
 
 func opItemReadIgnoreMissing(attrType models.ItemType) passwordManagerOperation {
 	return func(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager) diag.Diagnostics {
-		return ignoreMissing(ctx, d, applyOperation(ctx, d, bwClient.GetItem, transformation.ItemSchemaToObject(attrType), transformation.ItemObjectToSchema))
+		return ignoreMissing(ctx, d, applyOperation(ctx, d, ignoringUnmanagedAttributes(d, bwClient.GetItem), transformation.ItemSchemaToObject(attrType), transformation.ItemObjectToSchema))
 	}
 }
 
-func opItemUpdate(attrType models.ItemType) passwordManagerOperation {
-	return func(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager) diag.Diagnostics {
-		return diag.FromErr(applyOperation(ctx, d, bwClient.EditItem, transformation.ItemSchemaToObject(attrType), transformation.ItemObjectToSchema))
+func opItemUpdate(attrType models.ItemType) passwordManagerUpdateOperation {
+	return func(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager, overwriteConflicts bool) diag.Diagnostics {
+		if !d.HasChangesExcept(schema_definition.AttributeDeletionProtection, schema_definition.AttributeFieldMerge) {
+			return nil
+		}
+
+		// Content changes and key rotations are sent in a single edition, so
+		// that neither is applied without the other.
+		editItem := bwClient.EditItem
+		if d.HasChange(schema_definition.AttributeRotateItemKey) {
+			editItem = rotateItemKey(bwClient)
+		}
+
+		ctx = audit.WithPriorObject(ctx, transformation.ItemSchemaToObject(attrType)(ctx, priorState{d}))
+
+		var knownRevisionDate *time.Time
+		if !overwriteConflicts {
+			var err error
+			knownRevisionDate, err = checkItemConflict(ctx, d, bwClient, attrType)
+			if err != nil {
+				return diagFromErr(err)
+			}
+		}
+
+		editKnownRevision := func(ctx context.Context, obj models.Item) (*models.Item, error) {
+			obj.RevisionDate = knownRevisionDate
+			return editItem(ctx, obj)
+		}
+		return diagFromErr(applyOperation(ctx, d, preservingUnmanagedAttributes(d, bwClient, editKnownRevision), transformation.ItemSchemaToObject(attrType), transformation.ItemObjectToSchema))
+	}
+}
+
+// mergesFields tells whether an item resource only manages the custom fields,
+// URIs and notes it declares, with field_merge = "merge".
+func mergesFields(d *schema.ResourceData) bool {
+	fieldMerge, _ := d.Get(schema_definition.AttributeFieldMerge).(string)
+	return fieldMerge == schema_definition.FieldMergeMerge
+}
+
+// ignoringUnmanagedAttributes removes the custom fields, URIs and notes a
+// resource merging fields doesn't declare from the items an operation returns.
+func ignoringUnmanagedAttributes(d *schema.ResourceData, operation applyOperationFn[models.Item]) applyOperationFn[models.Item] {
+	if !mergesFields(d) {
+		return operation
+	}
+	return func(ctx context.Context, obj models.Item) (*models.Item, error) {
+		resObj, err := operation(ctx, obj)
+		if err != nil || resObj == nil {
+			return resObj, err
+		}
+		filteredObj := transformation.IgnoreUnmanagedItemAttributes(*resObj, obj)
+		return &filteredObj, nil
+	}
+}
+
+// preservingUnmanagedAttributes makes an edition of a resource merging fields
+// keep the custom fields, URIs and notes of the item it doesn't manage, and
+// remove the ones it managed previously and no longer declares.
+func preservingUnmanagedAttributes(d *schema.ResourceData, bwClient bitwarden.PasswordManager, operation applyOperationFn[models.Item]) applyOperationFn[models.Item] {
+	if !mergesFields(d) {
+		return operation
+	}
+	return ignoringUnmanagedAttributes(d, func(ctx context.Context, obj models.Item) (*models.Item, error) {
+		remoteObj, err := bwClient.GetItem(ctx, obj)
+		if err != nil {
+			return nil, fmt.Errorf("error reading item prior to edition: %w", err)
+		}
+		// The item holds attributes the state doesn't know about.
+		ctx = audit.WithPriorObject(ctx, *remoteObj)
+		return operation(ctx, transformation.MergeUnmanagedItemAttributes(obj, previouslyManagedAttributes(ctx, d, obj.Type), *remoteObj))
+	})
+}
+
+// previouslyManagedAttributes returns an item holding the custom fields, URIs
+// and notes of the prior state of a resource merging fields, which are the
+// ones it managed before the current change.
+func previouslyManagedAttributes(ctx context.Context, d *schema.ResourceData, attrType models.ItemType) models.Item {
+	return transformation.ItemSchemaToObject(attrType)(ctx, priorState{d})
+}
+
+// itemKeyRotator is implemented by the clients able to rotate item keys, which
+// are the embedded one and the decorators wrapping it.
+type itemKeyRotator interface {
+	RotateItemKey(ctx context.Context, obj models.Item) (*models.Item, error)
+}
+
+func rotateItemKey(bwClient bitwarden.PasswordManager) applyOperationFn[models.Item] {
+	return func(ctx context.Context, obj models.Item) (*models.Item, error) {
+		rotator, ok := bwClient.(itemKeyRotator)
+		if !ok {
+			return nil, models.ErrItemKeyRotationUnsupported
+		}
+		return rotator.RotateItemKey(ctx, obj)
 	}
 }
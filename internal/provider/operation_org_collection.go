@@ -8,16 +8,17 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/audit"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/transformation"
 )
 
 func opOrganizationCollectionCreate(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager) diag.Diagnostics {
-	return diag.FromErr(applyOperation(ctx, d, bwClient.CreateOrganizationCollection, transformation.OrganizationCollectionToObject, transformation.OrganizationCollectionObjectToSchema))
+	return diagFromErr(applyOperation(ctx, d, bwClient.CreateOrganizationCollection, transformation.OrganizationCollectionToObject, transformation.OrganizationCollectionObjectToSchema))
 }
 
 func opOrganizationCollectionDelete(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager) diag.Diagnostics {
-	return diag.FromErr(applyOperation(ctx, d, withNilReturn(bwClient.DeleteOrganizationCollection), transformation.OrganizationCollectionToObject, transformation.OrganizationCollectionObjectToSchema))
+	return diagFromErr(applyOperation(ctx, d, withNilReturn(bwClient.DeleteOrganizationCollection), transformation.OrganizationCollectionToObject, transformation.OrganizationCollectionObjectToSchema))
 }
 
 func opOrganizationCollectionImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
@@ -38,16 +39,25 @@ func opOrganizationCollectionImport(ctx context.Context, d *schema.ResourceData,
 func opOrganizationCollectionRead(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager) diag.Diagnostics {
 	d.SetId(d.Get(schema_definition.AttributeID).(string))
 	if _, idProvided := d.GetOk(schema_definition.AttributeID); !idProvided {
-		return diag.FromErr(searchOperation(ctx, d, bwClient.FindOrganizationCollection, transformation.OrganizationCollectionObjectToSchema))
+		return diagFromErr(searchOperation(ctx, d, bwClient.FindOrganizationCollection, transformation.OrganizationCollectionObjectToSchema))
 	}
 
-	return diag.FromErr(applyOperation(ctx, d, bwClient.GetOrganizationCollection, transformation.OrganizationCollectionToObject, transformation.OrganizationCollectionObjectToSchema))
+	return diagFromErr(applyOperation(ctx, d, bwClient.GetOrganizationCollection, transformation.OrganizationCollectionToObject, transformation.OrganizationCollectionObjectToSchema))
 }
 
 func opOrganizationCollectionReadIgnoreMissing(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager) diag.Diagnostics {
 	return ignoreMissing(ctx, d, applyOperation(ctx, d, bwClient.GetOrganizationCollection, transformation.OrganizationCollectionToObject, transformation.OrganizationCollectionObjectToSchema))
 }
 
-func opOrganizationCollectionUpdate(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager) diag.Diagnostics {
-	return diag.FromErr(applyOperation(ctx, d, bwClient.EditOrganizationCollection, transformation.OrganizationCollectionToObject, transformation.OrganizationCollectionObjectToSchema))
+func opOrganizationCollectionUpdate(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager, overwriteConflicts bool) diag.Diagnostics {
+	if !d.HasChangeExcept(schema_definition.AttributeDeletionProtection) {
+		return nil
+	}
+	if !overwriteConflicts {
+		if err := checkOrganizationCollectionConflict(ctx, d, bwClient); err != nil {
+			return diagFromErr(err)
+		}
+	}
+	ctx = audit.WithPriorObject(ctx, transformation.OrganizationCollectionToObject(ctx, priorState{d}))
+	return diagFromErr(applyOperation(ctx, d, bwClient.EditOrganizationCollection, transformation.OrganizationCollectionToObject, transformation.OrganizationCollectionObjectToSchema))
 }
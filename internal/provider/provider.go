@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
 )
 
@@ -30,19 +31,58 @@ func New(version string) func() provider.Provider {
 type experimentalModel struct {
 	EmbeddedClient                    types.Bool `tfsdk:"embedded_client"`
 	DisableSyncAfterWriteVerification types.Bool `tfsdk:"disable_sync_after_write_verification"`
+	MigrateToItemKeys                 types.Bool `tfsdk:"migrate_to_item_keys"`
+	CLIListCache                      types.Bool `tfsdk:"cli_list_cache"`
+}
+
+type cliModel struct {
+	MaxParallelReads  types.Int64  `tfsdk:"max_parallel_reads"`
+	MaxParallelWrites types.Int64  `tfsdk:"max_parallel_writes"`
+	CommandTimeout    types.String `tfsdk:"command_timeout"`
+}
+
+type httpModel struct {
+	MaxConcurrentRequests types.Int64   `tfsdk:"max_concurrent_requests"`
+	MaxRetries            types.Int64   `tfsdk:"max_retries"`
+	AttemptTimeout        types.String  `tfsdk:"attempt_timeout"`
+	DialTimeout           types.String  `tfsdk:"dial_timeout"`
+	RetryBackoffFactor    types.Float64 `tfsdk:"retry_backoff_factor"`
+	RetryOn429            types.Bool    `tfsdk:"retry_on_429"`
+	RetryOn500            types.Bool    `tfsdk:"retry_on_500"`
+	RetryOn503            types.Bool    `tfsdk:"retry_on_503"`
 }
 
 type bitwardenProviderModel struct {
 	MasterPassword       types.String `tfsdk:"master_password"`
 	SessionKey           types.String `tfsdk:"session_key"`
+	UserKey              types.String `tfsdk:"user_key"`
 	ClientID             types.String `tfsdk:"client_id"`
 	ClientSecret         types.String `tfsdk:"client_secret"`
 	AccessToken          types.String `tfsdk:"access_token"`
 	Server               types.String `tfsdk:"server"`
+	Region               types.String `tfsdk:"region"`
+	APIURL               types.String `tfsdk:"api_url"`
+	IdentityURL          types.String `tfsdk:"identity_url"`
+	EventsURL            types.String `tfsdk:"events_url"`
 	Email                types.String `tfsdk:"email"`
 	VaultPath            types.String `tfsdk:"vault_path"`
 	ExtraCACerts         types.String `tfsdk:"extra_ca_certs"`
+	ClientCert           types.String `tfsdk:"client_cert"`
+	ClientKey            types.String `tfsdk:"client_key"`
+	ProxyURL             types.String `tfsdk:"proxy_url"`
+	DebugHARPath         types.String `tfsdk:"debug_har_path"`
+	AuditLogPath         types.String `tfsdk:"audit_log_path"`
+	ReadOnly             types.Bool   `tfsdk:"read_only"`
+	DeletionProtection   types.Bool   `tfsdk:"deletion_protection"`
+	ConflictPolicy       types.String `tfsdk:"conflict_policy"`
+	ServerSPKIPins       types.List   `tfsdk:"server_spki_pins"`
+	HTTPHeaders          types.Map    `tfsdk:"http_headers"`
+	HTTP                 types.Set    `tfsdk:"http"`
+	CLI                  types.Set    `tfsdk:"cli"`
 	ClientImplementation types.String `tfsdk:"client_implementation"`
+	ExportFile           types.String `tfsdk:"export_file"`
+	ExportPassword       types.String `tfsdk:"export_password"`
+	MemoryFixture        types.String `tfsdk:"memory_fixture"`
 	Experimental         types.Set    `tfsdk:"experimental"`
 }
 
@@ -67,6 +107,24 @@ func (p *bitwardenProvider) Schema(_ context.Context, _ provider.SchemaRequest,
 				Optional:            true,
 				Sensitive:           true,
 			},
+			schema_definition.AttributeUserKey: provschema.StringAttribute{
+				MarkdownDescription: schema_definition.DescriptionUserKey,
+				Optional:            true,
+				Sensitive:           true,
+			},
+			schema_definition.AttributeExportFile: provschema.StringAttribute{
+				MarkdownDescription: schema_definition.DescriptionExportFile,
+				Optional:            true,
+			},
+			schema_definition.AttributeExportPassword: provschema.StringAttribute{
+				MarkdownDescription: schema_definition.DescriptionExportPassword,
+				Optional:            true,
+				Sensitive:           true,
+			},
+			schema_definition.AttributeMemoryFixture: provschema.StringAttribute{
+				MarkdownDescription: schema_definition.DescriptionMemoryFixture,
+				Optional:            true,
+			},
 			schema_definition.AttributeClientID: provschema.StringAttribute{
 				MarkdownDescription: schema_definition.DescriptionClientID,
 				Optional:            true,
@@ -87,6 +145,25 @@ func (p *bitwardenProvider) Schema(_ context.Context, _ provider.SchemaRequest,
 				MarkdownDescription: schema_definition.DescriptionServer,
 				Optional:            true,
 			},
+			schema_definition.AttributeRegion: provschema.StringAttribute{
+				MarkdownDescription: schema_definition.DescriptionRegion,
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(bitwarden.RegionUS, bitwarden.RegionEU),
+				},
+			},
+			schema_definition.AttributeAPIURL: provschema.StringAttribute{
+				MarkdownDescription: schema_definition.DescriptionAPIURL,
+				Optional:            true,
+			},
+			schema_definition.AttributeIdentityURL: provschema.StringAttribute{
+				MarkdownDescription: schema_definition.DescriptionIdentityURL,
+				Optional:            true,
+			},
+			schema_definition.AttributeEventsURL: provschema.StringAttribute{
+				MarkdownDescription: schema_definition.DescriptionEventsURL,
+				Optional:            true,
+			},
 			schema_definition.AttributeProviderEmail: provschema.StringAttribute{
 				MarkdownDescription: schema_definition.DescriptionProviderEmail,
 				Optional:            true,
@@ -99,15 +176,120 @@ func (p *bitwardenProvider) Schema(_ context.Context, _ provider.SchemaRequest,
 				MarkdownDescription: schema_definition.DescriptionExtraCACertsPath,
 				Optional:            true,
 			},
+			schema_definition.AttributeClientCertPath: provschema.StringAttribute{
+				MarkdownDescription: schema_definition.DescriptionClientCertPath,
+				Optional:            true,
+			},
+			schema_definition.AttributeClientKeyPath: provschema.StringAttribute{
+				MarkdownDescription: schema_definition.DescriptionClientKeyPath,
+				Optional:            true,
+			},
+			schema_definition.AttributeProxyURL: provschema.StringAttribute{
+				MarkdownDescription: schema_definition.DescriptionProxyURL,
+				Optional:            true,
+			},
+			schema_definition.AttributeDebugHARPath: provschema.StringAttribute{
+				MarkdownDescription: schema_definition.DescriptionDebugHARPath,
+				Optional:            true,
+			},
+			schema_definition.AttributeAuditLogPath: provschema.StringAttribute{
+				MarkdownDescription: schema_definition.DescriptionAuditLogPath,
+				Optional:            true,
+			},
+			schema_definition.AttributeReadOnly: provschema.BoolAttribute{
+				MarkdownDescription: schema_definition.DescriptionReadOnly,
+				Optional:            true,
+			},
+			schema_definition.AttributeProviderDeletionProtection: provschema.BoolAttribute{
+				MarkdownDescription: schema_definition.DescriptionProviderDeletionProtection,
+				Optional:            true,
+			},
+			schema_definition.AttributeServerSPKIPins: provschema.ListAttribute{
+				MarkdownDescription: schema_definition.DescriptionServerSPKIPins,
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			schema_definition.AttributeHTTPHeaders: provschema.MapAttribute{
+				MarkdownDescription: schema_definition.DescriptionHTTPHeaders,
+				ElementType:         types.StringType,
+				Optional:            true,
+				Sensitive:           true,
+			},
+			schema_definition.AttributeConflictPolicy: provschema.StringAttribute{
+				MarkdownDescription: schema_definition.DescriptionConflictPolicy,
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(schema_definition.ConflictPolicyFail, schema_definition.ConflictPolicyOverwrite),
+				},
+			},
 			schema_definition.AttributeClientImplementation: provschema.StringAttribute{
 				MarkdownDescription: schema_definition.DescriptionClientImplementation,
 				Optional:            true,
 				Validators: []validator.String{
-					stringvalidator.OneOf(schema_definition.ClientImplementationCLI, schema_definition.ClientImplementationEmbedded),
+					stringvalidator.OneOf(schema_definition.ClientImplementationCLI, schema_definition.ClientImplementationCLIServe, schema_definition.ClientImplementationEmbedded, schema_definition.ClientImplementationExportFile, schema_definition.ClientImplementationMemory),
 				},
 			},
 		},
 		Blocks: map[string]provschema.Block{
+			schema_definition.AttributeCLI: provschema.SetNestedBlock{
+				MarkdownDescription: schema_definition.DescriptionCLI,
+				NestedObject: provschema.NestedBlockObject{
+					Attributes: map[string]provschema.Attribute{
+						schema_definition.AttributeCLIMaxParallelReads: provschema.Int64Attribute{
+							MarkdownDescription: schema_definition.DescriptionCLIMaxParallelReads,
+							Optional:            true,
+						},
+						schema_definition.AttributeCLIMaxParallelWrites: provschema.Int64Attribute{
+							MarkdownDescription: schema_definition.DescriptionCLIMaxParallelWrites,
+							Optional:            true,
+						},
+						schema_definition.AttributeCLICommandTimeout: provschema.StringAttribute{
+							MarkdownDescription: schema_definition.DescriptionCLICommandTimeout,
+							Optional:            true,
+						},
+					},
+				},
+			},
+			schema_definition.AttributeHTTP: provschema.SetNestedBlock{
+				MarkdownDescription: schema_definition.DescriptionHTTP,
+				NestedObject: provschema.NestedBlockObject{
+					Attributes: map[string]provschema.Attribute{
+						schema_definition.AttributeHTTPMaxConcurrentRequests: provschema.Int64Attribute{
+							MarkdownDescription: schema_definition.DescriptionHTTPMaxConcurrentRequests,
+							Optional:            true,
+						},
+						schema_definition.AttributeHTTPMaxRetries: provschema.Int64Attribute{
+							MarkdownDescription: schema_definition.DescriptionHTTPMaxRetries,
+							Optional:            true,
+						},
+						schema_definition.AttributeHTTPAttemptTimeout: provschema.StringAttribute{
+							MarkdownDescription: schema_definition.DescriptionHTTPAttemptTimeout,
+							Optional:            true,
+						},
+						schema_definition.AttributeHTTPDialTimeout: provschema.StringAttribute{
+							MarkdownDescription: schema_definition.DescriptionHTTPDialTimeout,
+							Optional:            true,
+						},
+						schema_definition.AttributeHTTPRetryBackoffFactor: provschema.Float64Attribute{
+							MarkdownDescription: schema_definition.DescriptionHTTPRetryBackoffFactor,
+							Optional:            true,
+						},
+						schema_definition.AttributeHTTPRetryOn429: provschema.BoolAttribute{
+							MarkdownDescription: schema_definition.DescriptionHTTPRetryOn429,
+							Optional:            true,
+						},
+						schema_definition.AttributeHTTPRetryOn500: provschema.BoolAttribute{
+							MarkdownDescription: schema_definition.DescriptionHTTPRetryOn500,
+							Optional:            true,
+						},
+						schema_definition.AttributeHTTPRetryOn503: provschema.BoolAttribute{
+							MarkdownDescription: schema_definition.DescriptionHTTPRetryOn503,
+							Optional:            true,
+						},
+					},
+				},
+			},
+
 			// Experimental
 			schema_definition.AttributeExperimental: provschema.SetNestedBlock{
 				MarkdownDescription: schema_definition.DescriptionExperimental,
@@ -125,6 +307,14 @@ func (p *bitwardenProvider) Schema(_ context.Context, _ provider.SchemaRequest,
 							MarkdownDescription: schema_definition.DescriptionExperimentalDisableSyncAfterWriteVerification,
 							Optional:            true,
 						},
+						schema_definition.AttributeExperimentalMigrateToItemKeys: provschema.BoolAttribute{
+							MarkdownDescription: schema_definition.DescriptionExperimentalMigrateToItemKeys,
+							Optional:            true,
+						},
+						schema_definition.AttributeExperimentalCLIListCache: provschema.BoolAttribute{
+							MarkdownDescription: schema_definition.DescriptionExperimentalCLIListCache,
+							Optional:            true,
+						},
 					},
 				},
 			},
@@ -148,17 +338,47 @@ func (p *bitwardenProvider) Configure(ctx context.Context, req provider.Configur
 
 	cfg := applyProviderConfigEnvDefaults(providerConfig{
 		Server:               model.Server.ValueString(),
+		Region:               model.Region.ValueString(),
+		APIURL:               model.APIURL.ValueString(),
+		IdentityURL:          model.IdentityURL.ValueString(),
+		EventsURL:            model.EventsURL.ValueString(),
 		Email:                model.Email.ValueString(),
 		MasterPassword:       model.MasterPassword.ValueString(),
 		SessionKey:           model.SessionKey.ValueString(),
+		UserKey:              model.UserKey.ValueString(),
 		ClientID:             model.ClientID.ValueString(),
 		ClientSecret:         model.ClientSecret.ValueString(),
 		AccessToken:          model.AccessToken.ValueString(),
 		VaultPath:            vaultPathFromFramework(model.VaultPath),
 		ExtraCACertsPath:     model.ExtraCACerts.ValueString(),
+		ClientCertPath:       model.ClientCert.ValueString(),
+		ClientKeyPath:        model.ClientKey.ValueString(),
+		ProxyURL:             model.ProxyURL.ValueString(),
+		DebugHARPath:         model.DebugHARPath.ValueString(),
+		AuditLogPath:         model.AuditLogPath.ValueString(),
+		ReadOnly:             model.ReadOnly.ValueBool(),
+		DeletionProtection:   model.DeletionProtection.ValueBool(),
+		ConflictPolicy:       model.ConflictPolicy.ValueString(),
 		ClientImplementation: model.ClientImplementation.ValueString(),
+		ExportFile:           model.ExportFile.ValueString(),
+		ExportPassword:       model.ExportPassword.ValueString(),
+		MemoryFixture:        model.MemoryFixture.ValueString(),
 	})
 
+	if !model.ServerSPKIPins.IsNull() && !model.ServerSPKIPins.IsUnknown() {
+		resp.Diagnostics.Append(model.ServerSPKIPins.ElementsAs(ctx, &cfg.ServerSPKIPins, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if !model.HTTPHeaders.IsNull() && !model.HTTPHeaders.IsUnknown() {
+		resp.Diagnostics.Append(model.HTTPHeaders.ElementsAs(ctx, &cfg.HTTPHeaders, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	if !model.Experimental.IsNull() && !model.Experimental.IsUnknown() {
 		var experimental []experimentalModel
 		resp.Diagnostics.Append(model.Experimental.ElementsAs(ctx, &experimental, false)...)
@@ -168,6 +388,43 @@ func (p *bitwardenProvider) Configure(ctx context.Context, req provider.Configur
 		if len(experimental) > 0 {
 			cfg.ExperimentalEmbeddedClient = experimental[0].EmbeddedClient.ValueBool()
 			cfg.ExperimentalDisableSyncAfterWriteVerification = experimental[0].DisableSyncAfterWriteVerification.ValueBool()
+			cfg.ExperimentalMigrateToItemKeys = experimental[0].MigrateToItemKeys.ValueBool()
+			cfg.ExperimentalCLIListCache = experimental[0].CLIListCache.ValueBool()
+		}
+	}
+
+	if !model.HTTP.IsNull() && !model.HTTP.IsUnknown() {
+		var httpSettings []httpModel
+		resp.Diagnostics.Append(model.HTTP.ElementsAs(ctx, &httpSettings, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if len(httpSettings) > 0 {
+			cfg.HTTP = httpConfig{
+				MaxConcurrentRequests: httpSettings[0].MaxConcurrentRequests.ValueInt64Pointer(),
+				MaxRetries:            httpSettings[0].MaxRetries.ValueInt64Pointer(),
+				AttemptTimeout:        httpSettings[0].AttemptTimeout.ValueString(),
+				DialTimeout:           httpSettings[0].DialTimeout.ValueString(),
+				RetryBackoffFactor:    httpSettings[0].RetryBackoffFactor.ValueFloat64Pointer(),
+				RetryOn429:            httpSettings[0].RetryOn429.ValueBoolPointer(),
+				RetryOn500:            httpSettings[0].RetryOn500.ValueBoolPointer(),
+				RetryOn503:            httpSettings[0].RetryOn503.ValueBoolPointer(),
+			}
+		}
+	}
+
+	if !model.CLI.IsNull() && !model.CLI.IsUnknown() {
+		var cliSettings []cliModel
+		resp.Diagnostics.Append(model.CLI.ElementsAs(ctx, &cliSettings, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if len(cliSettings) > 0 {
+			cfg.CLI = cliConfig{
+				MaxParallelReads:  cliSettings[0].MaxParallelReads.ValueInt64Pointer(),
+				MaxParallelWrites: cliSettings[0].MaxParallelWrites.ValueInt64Pointer(),
+				CommandTimeout:    cliSettings[0].CommandTimeout.ValueString(),
+			}
 		}
 	}
 
@@ -217,5 +474,6 @@ func (p *bitwardenProvider) DataSources(_ context.Context) []func() datasource.D
 		NewOrgMemberDataSource,
 		NewProjectDataSource,
 		NewSecretDataSource,
+		NewServerInfoDataSource,
 	}
 }
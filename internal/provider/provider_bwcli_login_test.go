@@ -5,6 +5,7 @@ package provider
 import (
 	"testing"
 
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
 	test_command "github.com/maxlaverse/terraform-provider-bitwarden/internal/command/test"
 	"github.com/stretchr/testify/assert"
 )
@@ -87,6 +88,54 @@ func TestProviderDoesntLogoutFirstIfUnauthenticated(t *testing.T) {
 	}, commandsExecuted())
 }
 
+func TestProviderReadOnlyDoesntLoginIfUnauthenticated(t *testing.T) {
+	removeMocks, commandsExecuted := test_command.MockCommands(t, map[string]string{
+		"status": `{"serverURL": "http://127.0.0.99/", "status": "unauthenticated"}`,
+	})
+	defer removeMocks(t)
+
+	cfg := providerConfig{
+		Server:         "http://127.0.0.1/",
+		Email:          "test@laverse.net",
+		MasterPassword: "master-password-9",
+		ReadOnly:       true,
+	}
+
+	_, err := configureClients(t.Context(), versionTestDisabledRetries, cfg)
+	assert.ErrorIs(t, err, models.ErrReadOnlyVault)
+
+	assert.Equal(t, []string{
+		"status",
+	}, commandsExecuted())
+}
+
+func TestProviderReadOnlyReusesLockedVault(t *testing.T) {
+	removeMocks, commandsExecuted := test_command.MockCommands(t, map[string]string{
+		"status":                                 `{"serverURL": "http://127.0.0.1/", "userEmail": "test@laverse.net", "status": "locked"}`,
+		"unlock --raw --passwordenv BW_PASSWORD": `session-key1234`,
+		"sync":                                   ``,
+	})
+	defer removeMocks(t)
+
+	cfg := providerConfig{
+		Server:         "http://127.0.0.1/",
+		Email:          "test@laverse.net",
+		MasterPassword: "master-password-9",
+		ReadOnly:       true,
+	}
+
+	_, err := configureClients(t.Context(), versionTestDisabledRetries, cfg)
+	if !assert.NoError(t, err) {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{
+		"status",
+		"unlock --raw --passwordenv BW_PASSWORD",
+		"sync",
+	}, commandsExecuted())
+}
+
 func TestProviderWithSessionKeySync(t *testing.T) {
 	removeMocks, commandsExecuted := test_command.MockCommands(t, map[string]string{
 		"status": `{"serverURL": "http://127.0.0.1/", "userEmail": "test@laverse.net", "status": "unlocked"}`,
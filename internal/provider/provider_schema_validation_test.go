@@ -3,11 +3,15 @@
 package provider
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/bwcli"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/embedded"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
 	"github.com/stretchr/testify/assert"
 )
@@ -123,6 +127,215 @@ func TestProviderAuthUsingAccessToken(t *testing.T) {
 	assert.Implements(t, (*embedded.SecretsManager)(nil), sm)
 }
 
+func TestProviderAuthUsingPasswordManagerAndAccessToken(t *testing.T) {
+	cfg := providerConfig{
+		Server:               "http://127.0.0.1/",
+		Email:                "test@laverse.net",
+		MasterPassword:       "master-password-9",
+		AccessToken:          "0.client_id.client_secret:dGVzdC1lbmNyeXB0aW9uLWtleQ==",
+		ClientImplementation: schema_definition.ClientImplementationEmbedded,
+	}
+	assert.NoError(t, validateProviderConfig(cfg))
+
+	clients, err := configureClients(t.Context(), versionTestSkippedLogin, cfg)
+	assert.NoError(t, err)
+
+	pm, err := clients.RequirePasswordManager()
+	assert.NoError(t, err)
+	assert.Implements(t, (*embedded.PasswordManagerClient)(nil), pm)
+
+	sm, err := clients.RequireSecretsManager()
+	assert.NoError(t, err)
+	assert.Implements(t, (*embedded.SecretsManager)(nil), sm)
+}
+
+func TestProviderAuthUsingAccessToken_ThrowsErrorOnMasterPasswordWithoutEmail(t *testing.T) {
+	cfg := providerConfig{
+		Server:         "http://127.0.0.1/",
+		MasterPassword: "master-password-9",
+		AccessToken:    "0.client_id.client_secret:dGVzdC1lbmNyeXB0aW9uLWtleQ==",
+	}
+
+	err := validateProviderConfig(cfg)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "email")
+	}
+}
+
+func TestProviderAuthUsingUserKey(t *testing.T) {
+	cfg := providerConfig{
+		Server:               "http://127.0.0.1/",
+		ClientID:             "client-id-1234",
+		ClientSecret:         "client-secret-5678",
+		UserKey:              "dGVzdC11c2VyLWtleQ==",
+		ClientImplementation: schema_definition.ClientImplementationEmbedded,
+	}
+	assert.NoError(t, validateProviderConfig(cfg))
+
+	clients, err := configureClients(t.Context(), versionTestSkippedLogin, cfg)
+	assert.NoError(t, err)
+
+	pm, err := clients.RequirePasswordManager()
+	assert.NoError(t, err)
+	assert.Implements(t, (*embedded.PasswordManagerClient)(nil), pm)
+}
+
+func TestProviderAuthUsingUserKey_ThrowsErrorOnInvalidCombinations(t *testing.T) {
+	testData := map[string]struct {
+		cfg           providerConfig
+		expectedError string
+	}{
+		"missing-api-key": {
+			cfg: providerConfig{
+				UserKey:              "dGVzdC11c2VyLWtleQ==",
+				ClientImplementation: schema_definition.ClientImplementationEmbedded,
+			},
+			expectedError: "client_id",
+		},
+		"with-master-password": {
+			cfg: providerConfig{
+				ClientID:             "client-id-1234",
+				ClientSecret:         "client-secret-5678",
+				MasterPassword:       "master-password-9",
+				UserKey:              "dGVzdC11c2VyLWtleQ==",
+				ClientImplementation: schema_definition.ClientImplementationEmbedded,
+			},
+			expectedError: "conflicts",
+		},
+		"with-cli": {
+			cfg: providerConfig{
+				ClientID:     "client-id-1234",
+				ClientSecret: "client-secret-5678",
+				UserKey:      "dGVzdC11c2VyLWtleQ==",
+			},
+			expectedError: "embedded client",
+		},
+	}
+
+	for name, tt := range testData {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorContains(t, validateProviderConfig(tt.cfg), tt.expectedError)
+		})
+	}
+}
+
+func TestProviderAuthUsingSessionKeyAndEmbedded_ReadsCLIVault(t *testing.T) {
+	cfg := providerConfig{
+		Server:               "http://127.0.0.1/",
+		ClientID:             "client-id-1234",
+		ClientSecret:         "client-secret-5678",
+		SessionKey:           "1234",
+		VaultPath:            explicitVaultPath(t.TempDir()),
+		ClientImplementation: schema_definition.ClientImplementationEmbedded,
+	}
+	assert.NoError(t, validateProviderConfig(cfg))
+
+	clients, err := configureClients(t.Context(), versionTestSkippedLogin, cfg)
+	assert.NoError(t, err)
+
+	pm, err := clients.RequirePasswordManager()
+	assert.NoError(t, err)
+
+	err = ensureLoggedInEmbeddedPasswordManager(t.Context(), cfg, pm)
+	assert.ErrorContains(t, err, "error reading user key from the CLI vault")
+}
+
+func TestProviderAuthUsingSessionKeyAndEmbedded_LoadsCLIVaultOffline(t *testing.T) {
+	cfg := providerConfig{
+		Server:               "http://127.0.0.1/",
+		SessionKey:           "1234",
+		VaultPath:            explicitVaultPath(t.TempDir()),
+		ClientImplementation: schema_definition.ClientImplementationEmbedded,
+	}
+	assert.NoError(t, validateProviderConfig(cfg))
+
+	clients, err := configureClients(t.Context(), versionTestSkippedLogin, cfg)
+	assert.NoError(t, err)
+
+	pm, err := clients.RequirePasswordManager()
+	assert.NoError(t, err)
+
+	err = ensureLoggedInEmbeddedPasswordManager(t.Context(), cfg, pm)
+	assert.ErrorContains(t, err, "error reading bw CLI data")
+}
+
+func TestProviderUsingExportFile(t *testing.T) {
+	exportPath := filepath.Join(t.TempDir(), "export.json")
+	err := os.WriteFile(exportPath, []byte(`{"encrypted": false, "folders": [{"id": "folder-id", "name": "Databases"}], "items": []}`), 0600)
+	assert.NoError(t, err)
+
+	cfg := providerConfig{
+		ExportFile:           exportPath,
+		ClientImplementation: schema_definition.ClientImplementationExportFile,
+	}
+	assert.NoError(t, validateProviderConfig(cfg))
+
+	clients, err := configureClients(t.Context(), versionTestSkippedLogin, cfg)
+	assert.NoError(t, err)
+
+	pm, err := clients.RequirePasswordManager()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	folder, err := pm.GetFolder(t.Context(), models.Folder{ID: "folder-id"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Databases", folder.Name)
+	}
+
+	_, err = pm.CreateFolder(t.Context(), models.Folder{Name: "new"})
+	assert.ErrorIs(t, err, models.ErrReadOnlyVault)
+}
+
+func TestProviderUsingExportFile_ThrowsErrorOnMissingFile(t *testing.T) {
+	cfg := providerConfig{
+		ClientImplementation: schema_definition.ClientImplementationExportFile,
+	}
+
+	err := validateProviderConfig(cfg)
+	assert.ErrorContains(t, err, "`export_file` is required")
+}
+
+func TestProviderUsingMemoryVault(t *testing.T) {
+	fixturePath := filepath.Join(t.TempDir(), "fixture.json")
+	err := os.WriteFile(fixturePath, []byte(`{"folders": [{"id": "folder-id", "name": "Databases"}], "projects": [{"id": "project-id", "name": "Backend"}]}`), 0600)
+	assert.NoError(t, err)
+
+	cfg := providerConfig{
+		MemoryFixture:        fixturePath,
+		ClientImplementation: schema_definition.ClientImplementationMemory,
+	}
+	assert.NoError(t, validateProviderConfig(cfg))
+
+	clients, err := configureClients(t.Context(), versionTestSkippedLogin, cfg)
+	assert.NoError(t, err)
+
+	pm, err := clients.RequirePasswordManager()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	folder, err := pm.GetFolder(t.Context(), models.Folder{ID: "folder-id"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Databases", folder.Name)
+	}
+
+	folder, err = pm.CreateFolder(t.Context(), models.Folder{Name: "new"})
+	if assert.NoError(t, err) {
+		assert.NotEmpty(t, folder.ID)
+	}
+
+	sm, err := clients.RequireSecretsManager()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	secret, err := sm.CreateSecret(t.Context(), models.Secret{Key: "KEY", Value: "value", ProjectID: "project-id"})
+	if assert.NoError(t, err) {
+		assert.NotEmpty(t, secret.ID)
+	}
+}
+
 func TestProviderAuthUsingAPIKey_ThrowsErrorOnMissingClientID(t *testing.T) {
 	cfg := providerConfig{
 		Server:         "http://127.0.0.1/",
@@ -209,6 +422,23 @@ func TestSyncAfterWriteVerificationDisabled(t *testing.T) {
 	assert.True(t, pm.(embedded.PasswordManagerClient).IsSyncAfterWriteVerificationDisabled())
 }
 
+func TestMigrateToItemKeysRequiresEmbeddedClient(t *testing.T) {
+	cfg := providerConfig{
+		Server:                        "http://127.0.0.1/",
+		Email:                         "test@laverse.net",
+		MasterPassword:                "master-password-9",
+		ClientImplementation:          schema_definition.ClientImplementationCLI,
+		ExperimentalMigrateToItemKeys: true,
+	}
+	err := validateProviderConfig(cfg)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "migrate_to_item_keys")
+	}
+
+	cfg.ClientImplementation = schema_definition.ClientImplementationEmbedded
+	assert.NoError(t, validateProviderConfig(cfg))
+}
+
 func TestProviderAuthUsingExperimentalEmbeddedClient_BackwardCompatibility(t *testing.T) {
 	cfg := providerConfig{
 		Server:                     "http://127.0.0.1/",
@@ -285,3 +515,86 @@ func TestGetClientImplementation_ExperimentalTakesPrecedenceWhenBothSet(t *testi
 	clientImpl := getClientImplementation(cfg)
 	assert.Equal(t, schema_definition.ClientImplementationEmbedded, clientImpl, "experimental.embedded_client should take precedence when both are set")
 }
+
+func TestProviderClientCertificate_ThrowsErrorOnMissingClientKey(t *testing.T) {
+	cfg := providerConfig{
+		Server:         "http://127.0.0.1/",
+		Email:          "test@laverse.net",
+		MasterPassword: "master-password-9",
+		ClientCertPath: "client.pem",
+	}
+
+	err := validateProviderConfig(cfg)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "client_key")
+	}
+}
+
+func TestProviderTransportOptionsWithEmbeddedClient(t *testing.T) {
+	cfg := providerConfig{
+		Server:               "http://127.0.0.1/",
+		Email:                "test@laverse.net",
+		MasterPassword:       "master-password-9",
+		ProxyURL:             "http://proxy.internal:3128",
+		ServerSPKIPins:       []string{"sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+		ClientImplementation: schema_definition.ClientImplementationEmbedded,
+	}
+	assert.NoError(t, validateProviderConfig(cfg))
+
+	_, err := configureClients(t.Context(), versionTestSkippedLogin, cfg)
+	assert.NoError(t, err)
+
+	cfg.ServerSPKIPins = []string{"not base64!"}
+	_, err = configureClients(t.Context(), versionTestSkippedLogin, cfg)
+	assert.ErrorContains(t, err, "invalid SPKI pin")
+}
+
+func TestApplyProviderConfigEnvDefaults_Region(t *testing.T) {
+	t.Setenv("BW_URL", "https://bitwarden.example.com")
+
+	cfg := applyProviderConfigEnvDefaults(providerConfig{Region: bitwarden.RegionEU})
+	assert.Equal(t, "https://vault.bitwarden.eu", cfg.Server)
+
+	cfg = applyProviderConfigEnvDefaults(providerConfig{Server: "https://vault.example.com", Region: bitwarden.RegionEU})
+	assert.Equal(t, "https://vault.example.com", cfg.Server)
+
+	cfg = applyProviderConfigEnvDefaults(providerConfig{})
+	assert.Equal(t, "https://bitwarden.example.com", cfg.Server)
+}
+
+func TestCLIListCacheRequiresCLI(t *testing.T) {
+	cfg := providerConfig{
+		Server:                   "http://127.0.0.1/",
+		Email:                    "test@laverse.net",
+		MasterPassword:           "master-password-9",
+		ClientImplementation:     schema_definition.ClientImplementationEmbedded,
+		ExperimentalCLIListCache: true,
+	}
+	err := validateProviderConfig(cfg)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cli_list_cache")
+	}
+
+	cfg.ClientImplementation = schema_definition.ClientImplementationCLI
+	assert.NoError(t, validateProviderConfig(cfg))
+}
+
+func TestHTTPHeadersRequireEmbeddedClient(t *testing.T) {
+	cfg := providerConfig{
+		Server:         "http://127.0.0.1/",
+		Email:          "test@laverse.net",
+		MasterPassword: "master-password-9",
+		HTTPHeaders:    map[string]string{"CF-Access-Client-Id": "client-id"},
+	}
+
+	for _, clientImplementation := range []string{"", schema_definition.ClientImplementationCLI, schema_definition.ClientImplementationCLIServe} {
+		cfg.ClientImplementation = clientImplementation
+		err := validateProviderConfig(cfg)
+		if assert.Error(t, err, clientImplementation) {
+			assert.Contains(t, err.Error(), "`http_headers` is only supported by the embedded client")
+		}
+	}
+
+	cfg.ClientImplementation = schema_definition.ClientImplementationEmbedded
+	assert.NoError(t, validateProviderConfig(cfg))
+}
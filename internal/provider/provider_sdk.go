@@ -3,6 +3,7 @@ package provider
 import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
 )
 
@@ -35,6 +36,28 @@ func NewSDK(version string) func() *schema.Provider {
 					Optional:    true,
 					Sensitive:   true,
 				},
+				schema_definition.AttributeUserKey: {
+					Type:        schema.TypeString,
+					Description: schema_definition.DescriptionUserKey,
+					Optional:    true,
+					Sensitive:   true,
+				},
+				schema_definition.AttributeExportFile: {
+					Type:        schema.TypeString,
+					Description: schema_definition.DescriptionExportFile,
+					Optional:    true,
+				},
+				schema_definition.AttributeExportPassword: {
+					Type:        schema.TypeString,
+					Description: schema_definition.DescriptionExportPassword,
+					Optional:    true,
+					Sensitive:   true,
+				},
+				schema_definition.AttributeMemoryFixture: {
+					Type:        schema.TypeString,
+					Description: schema_definition.DescriptionMemoryFixture,
+					Optional:    true,
+				},
 				schema_definition.AttributeClientID: {
 					Type:        schema.TypeString,
 					Description: schema_definition.DescriptionClientID,
@@ -59,6 +82,27 @@ func NewSDK(version string) func() *schema.Provider {
 					Description: schema_definition.DescriptionServer,
 					Optional:    true,
 				},
+				schema_definition.AttributeRegion: {
+					Type:             schema.TypeString,
+					Description:      schema_definition.DescriptionRegion,
+					Optional:         true,
+					ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{bitwarden.RegionUS, bitwarden.RegionEU}, false)),
+				},
+				schema_definition.AttributeAPIURL: {
+					Type:        schema.TypeString,
+					Description: schema_definition.DescriptionAPIURL,
+					Optional:    true,
+				},
+				schema_definition.AttributeIdentityURL: {
+					Type:        schema.TypeString,
+					Description: schema_definition.DescriptionIdentityURL,
+					Optional:    true,
+				},
+				schema_definition.AttributeEventsURL: {
+					Type:        schema.TypeString,
+					Description: schema_definition.DescriptionEventsURL,
+					Optional:    true,
+				},
 				schema_definition.AttributeProviderEmail: {
 					Type:        schema.TypeString,
 					Description: schema_definition.DescriptionProviderEmail,
@@ -74,11 +118,143 @@ func NewSDK(version string) func() *schema.Provider {
 					Description: schema_definition.DescriptionExtraCACertsPath,
 					Optional:    true,
 				},
+				schema_definition.AttributeClientCertPath: {
+					Type:        schema.TypeString,
+					Description: schema_definition.DescriptionClientCertPath,
+					Optional:    true,
+				},
+				schema_definition.AttributeClientKeyPath: {
+					Type:        schema.TypeString,
+					Description: schema_definition.DescriptionClientKeyPath,
+					Optional:    true,
+				},
+				schema_definition.AttributeProxyURL: {
+					Type:        schema.TypeString,
+					Description: schema_definition.DescriptionProxyURL,
+					Optional:    true,
+				},
+				schema_definition.AttributeDebugHARPath: {
+					Type:        schema.TypeString,
+					Description: schema_definition.DescriptionDebugHARPath,
+					Optional:    true,
+				},
+				schema_definition.AttributeAuditLogPath: {
+					Type:        schema.TypeString,
+					Description: schema_definition.DescriptionAuditLogPath,
+					Optional:    true,
+				},
+				schema_definition.AttributeReadOnly: {
+					Type:        schema.TypeBool,
+					Description: schema_definition.DescriptionReadOnly,
+					Optional:    true,
+				},
+				schema_definition.AttributeProviderDeletionProtection: {
+					Type:        schema.TypeBool,
+					Description: schema_definition.DescriptionProviderDeletionProtection,
+					Optional:    true,
+				},
+				schema_definition.AttributeServerSPKIPins: {
+					Type:        schema.TypeList,
+					Description: schema_definition.DescriptionServerSPKIPins,
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+				},
+				schema_definition.AttributeHTTPHeaders: {
+					Type:        schema.TypeMap,
+					Description: schema_definition.DescriptionHTTPHeaders,
+					Optional:    true,
+					Sensitive:   true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+				},
+				schema_definition.AttributeConflictPolicy: {
+					Type:             schema.TypeString,
+					Description:      schema_definition.DescriptionConflictPolicy,
+					Optional:         true,
+					ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{schema_definition.ConflictPolicyFail, schema_definition.ConflictPolicyOverwrite}, false)),
+				},
 				schema_definition.AttributeClientImplementation: {
 					Type:             schema.TypeString,
 					Description:      schema_definition.DescriptionClientImplementation,
 					Optional:         true,
-					ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{schema_definition.ClientImplementationCLI, schema_definition.ClientImplementationEmbedded}, false)),
+					ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{schema_definition.ClientImplementationCLI, schema_definition.ClientImplementationCLIServe, schema_definition.ClientImplementationEmbedded, schema_definition.ClientImplementationExportFile, schema_definition.ClientImplementationMemory}, false)),
+				},
+
+				schema_definition.AttributeCLI: {
+					Description: schema_definition.DescriptionCLI,
+					Type:        schema.TypeSet,
+					Optional:    true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							schema_definition.AttributeCLIMaxParallelReads: {
+								Description: schema_definition.DescriptionCLIMaxParallelReads,
+								Type:        schema.TypeInt,
+								Optional:    true,
+							},
+							schema_definition.AttributeCLIMaxParallelWrites: {
+								Description: schema_definition.DescriptionCLIMaxParallelWrites,
+								Type:        schema.TypeInt,
+								Optional:    true,
+							},
+							schema_definition.AttributeCLICommandTimeout: {
+								Description: schema_definition.DescriptionCLICommandTimeout,
+								Type:        schema.TypeString,
+								Optional:    true,
+							},
+						},
+					},
+				},
+
+				schema_definition.AttributeHTTP: {
+					Description: schema_definition.DescriptionHTTP,
+					Type:        schema.TypeSet,
+					Optional:    true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							schema_definition.AttributeHTTPMaxConcurrentRequests: {
+								Description: schema_definition.DescriptionHTTPMaxConcurrentRequests,
+								Type:        schema.TypeInt,
+								Optional:    true,
+							},
+							schema_definition.AttributeHTTPMaxRetries: {
+								Description: schema_definition.DescriptionHTTPMaxRetries,
+								Type:        schema.TypeInt,
+								Optional:    true,
+							},
+							schema_definition.AttributeHTTPAttemptTimeout: {
+								Description: schema_definition.DescriptionHTTPAttemptTimeout,
+								Type:        schema.TypeString,
+								Optional:    true,
+							},
+							schema_definition.AttributeHTTPDialTimeout: {
+								Description: schema_definition.DescriptionHTTPDialTimeout,
+								Type:        schema.TypeString,
+								Optional:    true,
+							},
+							schema_definition.AttributeHTTPRetryBackoffFactor: {
+								Description: schema_definition.DescriptionHTTPRetryBackoffFactor,
+								Type:        schema.TypeFloat,
+								Optional:    true,
+							},
+							schema_definition.AttributeHTTPRetryOn429: {
+								Description: schema_definition.DescriptionHTTPRetryOn429,
+								Type:        schema.TypeBool,
+								Optional:    true,
+								Default:     true,
+							},
+							schema_definition.AttributeHTTPRetryOn500: {
+								Description: schema_definition.DescriptionHTTPRetryOn500,
+								Type:        schema.TypeBool,
+								Optional:    true,
+								Default:     true,
+							},
+							schema_definition.AttributeHTTPRetryOn503: {
+								Description: schema_definition.DescriptionHTTPRetryOn503,
+								Type:        schema.TypeBool,
+								Optional:    true,
+								Default:     true,
+							},
+						},
+					},
 				},
 
 				// Experimental
@@ -102,6 +278,16 @@ func NewSDK(version string) func() *schema.Provider {
 								Type:        schema.TypeBool,
 								Optional:    true,
 							},
+							schema_definition.AttributeExperimentalMigrateToItemKeys: {
+								Description: schema_definition.DescriptionExperimentalMigrateToItemKeys,
+								Type:        schema.TypeBool,
+								Optional:    true,
+							},
+							schema_definition.AttributeExperimentalCLIListCache: {
+								Description: schema_definition.DescriptionExperimentalCLIListCache,
+								Type:        schema.TypeBool,
+								Optional:    true,
+							},
 						},
 					},
 				},
@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/audit"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/transformation"
@@ -16,6 +17,7 @@ var (
 	_ resource.Resource                = &folderResource{}
 	_ resource.ResourceWithConfigure   = &folderResource{}
 	_ resource.ResourceWithImportState = &folderResource{}
+	_ resource.ResourceWithModifyPlan  = &folderResource{}
 )
 
 type folderResource struct {
@@ -27,8 +29,9 @@ func NewFolderResource() resource.Resource {
 }
 
 type folderResourceModel struct {
-	ID   types.String `tfsdk:"id"`
-	Name types.String `tfsdk:"name"`
+	ID                 types.String `tfsdk:"id"`
+	Name               types.String `tfsdk:"name"`
+	DeletionProtection types.Bool   `tfsdk:"deletion_protection"`
 }
 
 func (r *folderResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -47,6 +50,10 @@ func (r *folderResource) Configure(_ context.Context, req resource.ConfigureRequ
 	r.clients = clients
 }
 
+func (r *folderResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	modifyDeletionProtectionPlan(ctx, req, resp, r.clients)
+}
+
 func (r *folderResource) folderAttrFromModel(model folderResourceModel) *transformation.MapData {
 	attr := transformation.NewMapData(map[string]interface{}{
 		schema_definition.AttributeName: model.Name.ValueString(),
@@ -86,7 +93,9 @@ func (r *folderResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, folderModelFromData(attr))...)
+	model := folderModelFromData(attr)
+	model.DeletionProtection = resolveDeletionProtection(plan.DeletionProtection, r.clients)
+	resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
 }
 
 func (r *folderResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -117,7 +126,9 @@ func (r *folderResource) Read(ctx context.Context, req resource.ReadRequest, res
 		return
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, folderModelFromData(attr))...)
+	model := folderModelFromData(attr)
+	model.DeletionProtection = resolveDeletionProtection(state.DeletionProtection, r.clients)
+	resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
 }
 
 func (r *folderResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -127,11 +138,32 @@ func (r *folderResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
+	if onlyDeletionProtectionChanged(req.Plan.Raw, req.State.Raw) {
+		var state folderResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		state.DeletionProtection = resolveDeletionProtection(plan.DeletionProtection, r.clients)
+		resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+		return
+	}
+
 	bwClient, ok := requirePasswordManager(r.clients, &resp.Diagnostics)
 	if !ok {
 		return
 	}
 
+	var state folderResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !r.clients.overwritesConflicts() {
+		if err := checkFolderConflict(ctx, r.folderAttrFromModel(state), bwClient); err != nil {
+			addErr(&resp.Diagnostics, err)
+			return
+		}
+	}
+
+	ctx = audit.WithPriorObject(ctx, transformation.SchemaToFolderObject(ctx, r.folderAttrFromModel(state)))
 	attr := r.folderAttrFromModel(plan)
 	obj, err := bwClient.EditFolder(ctx, transformation.SchemaToFolderObject(ctx, attr))
 	if err != nil {
@@ -144,7 +176,9 @@ func (r *folderResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, folderModelFromData(attr))...)
+	model := folderModelFromData(attr)
+	model.DeletionProtection = resolveDeletionProtection(plan.DeletionProtection, r.clients)
+	resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
 }
 
 func (r *folderResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -16,9 +16,10 @@ func resourceItemLogin() *schema.Resource {
 		Description:   "Manages a login item.",
 		CreateContext: withPasswordManager(opItemCreate(models.ItemTypeLogin)),
 		ReadContext:   withPasswordManager(opItemReadIgnoreMissing(models.ItemTypeLogin)),
-		UpdateContext: withPasswordManager(opItemUpdate(models.ItemTypeLogin)),
+		UpdateContext: withConflictPolicy(opItemUpdate(models.ItemTypeLogin)),
 		DeleteContext: withPasswordManager(opItemDelete(models.ItemTypeLogin)),
 		Importer:      resourceImporter(opItemImport),
+		CustomizeDiff: customizeDeletionProtectionDiff,
 		Schema:        itemLoginSchema,
 	}
 }
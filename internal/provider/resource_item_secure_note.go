@@ -16,9 +16,10 @@ func resourceItemSecureNote() *schema.Resource {
 		Description:   "Manages a secure note item.",
 		CreateContext: withPasswordManager(opItemCreate(models.ItemTypeSecureNote)),
 		ReadContext:   withPasswordManager(opItemReadIgnoreMissing(models.ItemTypeSecureNote)),
-		UpdateContext: withPasswordManager(opItemUpdate(models.ItemTypeSecureNote)),
+		UpdateContext: withConflictPolicy(opItemUpdate(models.ItemTypeSecureNote)),
 		DeleteContext: withPasswordManager(opItemDelete(models.ItemTypeSecureNote)),
 		Importer:      resourceImporter(opItemImport),
+		CustomizeDiff: customizeDeletionProtectionDiff,
 		Schema:        itemSecureNoteSchema,
 	}
 }
@@ -14,11 +14,12 @@ func resourceItemSSHKey() *schema.Resource {
 
 	return &schema.Resource{
 		Description:   "Manages an SSH key item.",
-		CreateContext: withPasswordManager(opItemCreate(models.ItemTypeSSHKey)),
+		CreateContext: withFeature(featureSSHKeyItems, withPasswordManager(opItemCreate(models.ItemTypeSSHKey))),
 		ReadContext:   withPasswordManager(opItemReadIgnoreMissing(models.ItemTypeSSHKey)),
-		UpdateContext: withPasswordManager(opItemUpdate(models.ItemTypeSSHKey)),
+		UpdateContext: withFeature(featureSSHKeyItems, withConflictPolicy(opItemUpdate(models.ItemTypeSSHKey))),
 		DeleteContext: withPasswordManager(opItemDelete(models.ItemTypeSSHKey)),
 		Importer:      resourceImporter(opItemImport),
+		CustomizeDiff: customizeDeletionProtectionDiff,
 		Schema:        itemSSHKeySchema,
 	}
 }
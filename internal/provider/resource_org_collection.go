@@ -9,11 +9,12 @@ func resourceOrgCollection() *schema.Resource {
 	return &schema.Resource{
 		Description: "Manages an organization collection.",
 
-		CreateContext: withPasswordManager(opOrganizationCollectionCreate),
+		CreateContext: withFeature(featureOrgCollectionMembers, withPasswordManager(opOrganizationCollectionCreate)),
 		ReadContext:   withPasswordManager(opOrganizationCollectionReadIgnoreMissing),
-		UpdateContext: withPasswordManager(opOrganizationCollectionUpdate),
+		UpdateContext: withFeature(featureOrgCollectionMembers, withConflictPolicy(opOrganizationCollectionUpdate)),
 		DeleteContext: withPasswordManager(opOrganizationCollectionDelete),
 		Importer:      resourceImporter(opOrganizationCollectionImport),
+		CustomizeDiff: customizeDeletionProtectionDiff,
 
 		Schema: schema_definition.OrgCollectionSchema(schema_definition.Resource),
 	}
@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/audit"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/transformation"
@@ -16,6 +17,7 @@ var (
 	_ resource.Resource                = &projectResource{}
 	_ resource.ResourceWithConfigure   = &projectResource{}
 	_ resource.ResourceWithImportState = &projectResource{}
+	_ resource.ResourceWithModifyPlan  = &projectResource{}
 )
 
 type projectResource struct {
@@ -27,9 +29,10 @@ func NewProjectResource() resource.Resource {
 }
 
 type projectResourceModel struct {
-	ID             types.String `tfsdk:"id"`
-	Name           types.String `tfsdk:"name"`
-	OrganizationID types.String `tfsdk:"organization_id"`
+	ID                 types.String `tfsdk:"id"`
+	Name               types.String `tfsdk:"name"`
+	OrganizationID     types.String `tfsdk:"organization_id"`
+	DeletionProtection types.Bool   `tfsdk:"deletion_protection"`
 }
 
 func (r *projectResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -48,6 +51,10 @@ func (r *projectResource) Configure(_ context.Context, req resource.ConfigureReq
 	r.clients = clients
 }
 
+func (r *projectResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	modifyDeletionProtectionPlan(ctx, req, resp, r.clients)
+}
+
 func (r *projectResource) projectAttrFromModel(model projectResourceModel) *transformation.MapData {
 	attr := transformation.NewMapData(map[string]interface{}{
 		schema_definition.AttributeName:           model.Name.ValueString(),
@@ -89,7 +96,9 @@ func (r *projectResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, projectModelFromData(attr))...)
+	model := projectModelFromData(attr)
+	model.DeletionProtection = resolveDeletionProtection(plan.DeletionProtection, r.clients)
+	resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
 }
 
 func (r *projectResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -120,7 +129,9 @@ func (r *projectResource) Read(ctx context.Context, req resource.ReadRequest, re
 		return
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, projectModelFromData(attr))...)
+	model := projectModelFromData(attr)
+	model.DeletionProtection = resolveDeletionProtection(state.DeletionProtection, r.clients)
+	resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
 }
 
 func (r *projectResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -130,11 +141,26 @@ func (r *projectResource) Update(ctx context.Context, req resource.UpdateRequest
 		return
 	}
 
+	if onlyDeletionProtectionChanged(req.Plan.Raw, req.State.Raw) {
+		var state projectResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		state.DeletionProtection = resolveDeletionProtection(plan.DeletionProtection, r.clients)
+		resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+		return
+	}
+
 	bwsClient, ok := requireSecretsManager(r.clients, &resp.Diagnostics)
 	if !ok {
 		return
 	}
 
+	var state projectResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = audit.WithPriorObject(ctx, transformation.ProjectSchemaToObject(ctx, r.projectAttrFromModel(state)))
 	attr := r.projectAttrFromModel(plan)
 	obj, err := bwsClient.EditProject(ctx, transformation.ProjectSchemaToObject(ctx, attr))
 	if err != nil {
@@ -147,7 +173,9 @@ func (r *projectResource) Update(ctx context.Context, req resource.UpdateRequest
 		return
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, projectModelFromData(attr))...)
+	model := projectModelFromData(attr)
+	model.DeletionProtection = resolveDeletionProtection(plan.DeletionProtection, r.clients)
+	resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
 }
 
 func (r *projectResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/audit"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/schema_definition"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/transformation"
@@ -16,6 +17,7 @@ var (
 	_ resource.Resource                = &secretResource{}
 	_ resource.ResourceWithConfigure   = &secretResource{}
 	_ resource.ResourceWithImportState = &secretResource{}
+	_ resource.ResourceWithModifyPlan  = &secretResource{}
 )
 
 type secretResource struct {
@@ -27,12 +29,13 @@ func NewSecretResource() resource.Resource {
 }
 
 type secretResourceModel struct {
-	ID             types.String `tfsdk:"id"`
-	Key            types.String `tfsdk:"key"`
-	Value          types.String `tfsdk:"value"`
-	Note           types.String `tfsdk:"note"`
-	OrganizationID types.String `tfsdk:"organization_id"`
-	ProjectID      types.String `tfsdk:"project_id"`
+	ID                 types.String `tfsdk:"id"`
+	Key                types.String `tfsdk:"key"`
+	Value              types.String `tfsdk:"value"`
+	Note               types.String `tfsdk:"note"`
+	OrganizationID     types.String `tfsdk:"organization_id"`
+	ProjectID          types.String `tfsdk:"project_id"`
+	DeletionProtection types.Bool   `tfsdk:"deletion_protection"`
 }
 
 func (r *secretResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -51,6 +54,10 @@ func (r *secretResource) Configure(_ context.Context, req resource.ConfigureRequ
 	r.clients = clients
 }
 
+func (r *secretResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	modifyDeletionProtectionPlan(ctx, req, resp, r.clients)
+}
+
 func (r *secretResource) secretAttrFromModel(model secretResourceModel) *transformation.MapData {
 	attr := transformation.NewMapData(map[string]interface{}{
 		schema_definition.AttributeKey:            model.Key.ValueString(),
@@ -99,7 +106,9 @@ func (r *secretResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, secretModelFromData(attr))...)
+	model := secretModelFromData(attr)
+	model.DeletionProtection = resolveDeletionProtection(plan.DeletionProtection, r.clients)
+	resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
 }
 
 func (r *secretResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -130,7 +139,9 @@ func (r *secretResource) Read(ctx context.Context, req resource.ReadRequest, res
 		return
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, secretModelFromData(attr))...)
+	model := secretModelFromData(attr)
+	model.DeletionProtection = resolveDeletionProtection(state.DeletionProtection, r.clients)
+	resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
 }
 
 func (r *secretResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -140,11 +151,26 @@ func (r *secretResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
+	if onlyDeletionProtectionChanged(req.Plan.Raw, req.State.Raw) {
+		var state secretResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		state.DeletionProtection = resolveDeletionProtection(plan.DeletionProtection, r.clients)
+		resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+		return
+	}
+
 	bwsClient, ok := requireSecretsManager(r.clients, &resp.Diagnostics)
 	if !ok {
 		return
 	}
 
+	var state secretResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = audit.WithPriorObject(ctx, transformation.SecretSchemaToObject(ctx, r.secretAttrFromModel(state)))
 	attr := r.secretAttrFromModel(plan)
 	obj, err := bwsClient.EditSecret(ctx, transformation.SecretSchemaToObject(ctx, attr))
 	if err != nil {
@@ -157,7 +183,9 @@ func (r *secretResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, secretModelFromData(attr))...)
+	model := secretModelFromData(attr)
+	model.DeletionProtection = resolveDeletionProtection(plan.DeletionProtection, r.clients)
+	resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
 }
 
 func (r *secretResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -50,13 +50,6 @@ func requireSecretsManager(clients *ProviderClients, diags *diag.Diagnostics) (b
 	return bwsClient, true
 }
 
-// addErr reports a client/API error using the error text as the diagnostic
-// summary, matching the historical SDKv2 diag.FromErr formatting that
-// acceptance tests assert against (e.g. "Error: object not found").
-func addErr(diags *diag.Diagnostics, err error) {
-	diags.AddError(err.Error(), "")
-}
-
 // mapStr converts a map value from MapData into a Framework string attribute.
 // Non-string values become null.
 func mapStr(v interface{}) types.String {
@@ -67,6 +60,7 @@ func mapStr(v interface{}) types.String {
 }
 
 type passwordManagerOperation func(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager) sdkdiag.Diagnostics
+type passwordManagerUpdateOperation func(ctx context.Context, d *schema.ResourceData, bwClient bitwarden.PasswordManager, overwriteConflicts bool) sdkdiag.Diagnostics
 type secretsManagerOperation func(ctx context.Context, d *schema.ResourceData, bwsClient bitwarden.SecretsManager) sdkdiag.Diagnostics
 
 // withPasswordManager wraps an SDKv2 resource operation with a Password Manager client
@@ -75,27 +69,56 @@ func withPasswordManager(resourceAction passwordManagerOperation) func(ctx conte
 	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) sdkdiag.Diagnostics {
 		clients, ok := meta.(*ProviderClients)
 		if !ok {
-			return sdkdiag.FromErr(errPasswordManagerRequired)
+			return diagFromErr(errPasswordManagerRequired)
 		}
 		bwClient, err := clients.RequirePasswordManager()
 		if err != nil {
-			return sdkdiag.FromErr(err)
+			return diagFromErr(err)
 		}
 		return resourceAction(ctx, d, bwClient)
 	}
 }
 
+// withConflictPolicy wraps an SDKv2 resource update with a Password Manager
+// client and whether conflict_policy overwrites changes made in the Vault.
+func withConflictPolicy(resourceAction passwordManagerUpdateOperation) func(ctx context.Context, d *schema.ResourceData, meta interface{}) sdkdiag.Diagnostics {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) sdkdiag.Diagnostics {
+		clients, ok := meta.(*ProviderClients)
+		if !ok {
+			return diagFromErr(errPasswordManagerRequired)
+		}
+		bwClient, err := clients.RequirePasswordManager()
+		if err != nil {
+			return diagFromErr(err)
+		}
+		return resourceAction(ctx, d, bwClient, clients.overwritesConflicts())
+	}
+}
+
+// withFeature wraps an SDKv2 resource operation with a check that the backend
+// supports a feature, reporting a clear diagnostic when it doesn't.
+func withFeature(f feature, resourceAction func(ctx context.Context, d *schema.ResourceData, meta interface{}) sdkdiag.Diagnostics) func(ctx context.Context, d *schema.ResourceData, meta interface{}) sdkdiag.Diagnostics {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) sdkdiag.Diagnostics {
+		if clients, ok := meta.(*ProviderClients); ok && (f.usedBy == nil || f.usedBy(d)) {
+			if err := clients.Capabilities.require(ctx, f); err != nil {
+				return diagFromErr(err)
+			}
+		}
+		return resourceAction(ctx, d, meta)
+	}
+}
+
 // withSecretsManager wraps an SDKv2 resource operation with a Secrets Manager client
 // from provider meta. Kept until those resources migrate to Framework.
 func withSecretsManager(resourceAction secretsManagerOperation) func(ctx context.Context, d *schema.ResourceData, meta interface{}) sdkdiag.Diagnostics {
 	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) sdkdiag.Diagnostics {
 		clients, ok := meta.(*ProviderClients)
 		if !ok {
-			return sdkdiag.FromErr(errSecretsManagerRequired)
+			return diagFromErr(errSecretsManagerRequired)
 		}
 		bwsClient, err := clients.RequireSecretsManager()
 		if err != nil {
-			return sdkdiag.FromErr(err)
+			return diagFromErr(err)
 		}
 		return resourceAction(ctx, d, bwsClient)
 	}
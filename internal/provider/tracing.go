@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	attributeTerraformType      = attribute.Key("terraform.type")
+	attributeTerraformOperation = attribute.Key("terraform.operation")
+)
+
+// tracedProviderServer starts a span for each operation Terraform runs on a
+// resource or data source, which the HTTP calls and commands it triggers are
+// children of.
+type tracedProviderServer struct {
+	tfprotov6.ProviderServer
+}
+
+func (s *tracedProviderServer) ReadResource(ctx context.Context, req *tfprotov6.ReadResourceRequest) (*tfprotov6.ReadResourceResponse, error) {
+	ctx, span := startOperationSpan(ctx, req.TypeName, "read")
+	resp, err := s.ProviderServer.ReadResource(ctx, req)
+	if resp != nil {
+		endOperationSpan(span, resp.Diagnostics, err)
+	} else {
+		endOperationSpan(span, nil, err)
+	}
+	return resp, err
+}
+
+func (s *tracedProviderServer) ApplyResourceChange(ctx context.Context, req *tfprotov6.ApplyResourceChangeRequest) (*tfprotov6.ApplyResourceChangeResponse, error) {
+	ctx, span := startOperationSpan(ctx, req.TypeName, applyChangeOperation(req))
+	resp, err := s.ProviderServer.ApplyResourceChange(ctx, req)
+	if resp != nil {
+		endOperationSpan(span, resp.Diagnostics, err)
+	} else {
+		endOperationSpan(span, nil, err)
+	}
+	return resp, err
+}
+
+func (s *tracedProviderServer) ImportResourceState(ctx context.Context, req *tfprotov6.ImportResourceStateRequest) (*tfprotov6.ImportResourceStateResponse, error) {
+	ctx, span := startOperationSpan(ctx, req.TypeName, "import")
+	resp, err := s.ProviderServer.ImportResourceState(ctx, req)
+	if resp != nil {
+		endOperationSpan(span, resp.Diagnostics, err)
+	} else {
+		endOperationSpan(span, nil, err)
+	}
+	return resp, err
+}
+
+func (s *tracedProviderServer) ReadDataSource(ctx context.Context, req *tfprotov6.ReadDataSourceRequest) (*tfprotov6.ReadDataSourceResponse, error) {
+	ctx, span := startOperationSpan(ctx, req.TypeName, "read")
+	resp, err := s.ProviderServer.ReadDataSource(ctx, req)
+	if resp != nil {
+		endOperationSpan(span, resp.Diagnostics, err)
+	} else {
+		endOperationSpan(span, nil, err)
+	}
+	return resp, err
+}
+
+// applyChangeOperation tells whether an apply creates, updates or deletes a
+// resource, based on which of its prior and planned states are null.
+func applyChangeOperation(req *tfprotov6.ApplyResourceChangeRequest) string {
+	if isNullDynamicValue(req.PlannedState) {
+		return "delete"
+	}
+	if isNullDynamicValue(req.PriorState) {
+		return "create"
+	}
+	return "update"
+}
+
+func isNullDynamicValue(v *tfprotov6.DynamicValue) bool {
+	if v == nil {
+		return true
+	}
+	isNull, err := v.IsNull()
+	return err == nil && isNull
+}
+
+func startOperationSpan(ctx context.Context, typeName, operation string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, fmt.Sprintf("%s %s", typeName, operation),
+		attributeTerraformType.String(typeName),
+		attributeTerraformOperation.String(operation),
+	)
+}
+
+func endOperationSpan(span trace.Span, diags []*tfprotov6.Diagnostic, err error) {
+	if err == nil {
+		for _, diag := range diags {
+			if diag != nil && diag.Severity == tfprotov6.DiagnosticSeverityError {
+				err = fmt.Errorf("%s: %s", diag.Summary, diag.Detail)
+				break
+			}
+		}
+	}
+	tracing.End(span, err)
+}
@@ -0,0 +1,200 @@
+// Package redact removes secrets from what the provider logs, so that logs can
+// be shared when reporting issues.
+package redact
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const Placeholder = "[REDACTED]"
+
+var (
+	// sensitiveKeySuffixes match JSON keys, form fields and headers holding
+	// secrets, once lower-cased and without separators, e.g. "access_token",
+	// "masterPasswordHash" or "privateKey".
+	sensitiveKeySuffixes = []string{"key", "password", "passwordhash", "secret", "token", "totp"}
+
+	// sensitiveKeys are the other JSON keys of decrypted objects holding
+	// secrets.
+	sensitiveKeys = []string{"code", "licensenumber", "notes", "number", "otp", "passportnumber", "ssn", "value"}
+
+	sensitiveHeaders = []string{"authemail", "authorization", "cookie", "proxyauthorization", "setcookie"}
+)
+
+// IsSensitiveKey returns true when a JSON key, form field or header name is
+// known to hold secrets.
+func IsSensitiveKey(name string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(name))
+	if slices.Contains(sensitiveKeys, normalized) {
+		return true
+	}
+	for _, suffix := range sensitiveKeySuffixes {
+		if strings.HasSuffix(normalized, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// JSON redacts the values of sensitive keys of a JSON document, at any depth.
+// It returns false when data isn't JSON.
+func JSON(data []byte) ([]byte, bool) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return nil, false
+	}
+
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, false
+	}
+
+	redacted, err := json.Marshal(redactValue(doc))
+	if err != nil {
+		return nil, false
+	}
+	return redacted, true
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if value != nil && IsSensitiveKey(key) {
+				v[key] = Placeholder
+				continue
+			}
+			v[key] = redactValue(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = redactValue(value)
+		}
+	}
+	return v
+}
+
+// Form redacts the values of sensitive fields of a form.
+func Form(values url.Values) url.Values {
+	redacted := make(url.Values, len(values))
+	for name, value := range values {
+		if IsSensitiveKey(name) {
+			redacted[name] = []string{Placeholder}
+			continue
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
+
+// Headers redacts the values of sensitive headers.
+func Headers(headers http.Header) http.Header {
+	redacted := make(http.Header, len(headers))
+	for name, values := range headers {
+		normalized := strings.ReplaceAll(strings.ToLower(name), "-", "")
+		if slices.Contains(sensitiveHeaders, normalized) || IsSensitiveKey(name) {
+			redacted[name] = []string{Placeholder}
+			continue
+		}
+		if normalized == "location" {
+			locations := make([]string, 0, len(values))
+			for _, value := range values {
+				locations = append(locations, Location(value))
+			}
+			redacted[name] = locations
+			continue
+		}
+		redacted[name] = values
+	}
+	return redacted
+}
+
+// URL redacts the sensitive parameters of the query of a URL.
+func URL(u *url.URL) string {
+	if len(u.RawQuery) == 0 {
+		return u.String()
+	}
+	redacted := *u
+	redacted.RawQuery = Form(u.Query()).Encode()
+	return redacted.String()
+}
+
+// Location redacts the sensitive parameters of the URL of a redirection, and
+// its fragment which may hold tokens as well.
+func Location(location string) string {
+	u, err := url.Parse(location)
+	if err != nil {
+		return Placeholder
+	}
+	if len(u.Fragment) > 0 {
+		u.Fragment = Placeholder
+		u.RawFragment = ""
+	}
+	return URL(u)
+}
+
+// Body redacts a request or response body of the given content type. Bodies
+// which are neither JSON, forms nor text are omitted.
+func Body(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if redacted, ok := JSON(body); ok {
+		return string(redacted)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return omitted(body)
+		}
+		return Form(values).Encode()
+	case strings.HasPrefix(mediaType, "text/"):
+		return string(body)
+	}
+	return omitted(body)
+}
+
+// Text redacts text when it is JSON, and returns it unchanged otherwise.
+func Text(text string) string {
+	if redacted, ok := JSON([]byte(text)); ok {
+		return string(redacted)
+	}
+	return text
+}
+
+// Args redacts the arguments of a command holding base64-encoded JSON
+// objects, as the Bitwarden CLI expects when creating or editing them.
+func Args(args []string) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		redacted[i] = arg
+		decoded, err := base64.StdEncoding.DecodeString(arg)
+		if err != nil {
+			decoded, err = base64.RawStdEncoding.DecodeString(arg)
+		}
+		if err != nil {
+			continue
+		}
+		if redactedJSON, ok := JSON(decoded); ok {
+			redacted[i] = string(redactedJSON)
+		}
+	}
+	return redacted
+}
+
+func omitted(body []byte) string {
+	return fmt.Sprintf("[%d bytes omitted]", len(body))
+}
@@ -0,0 +1,71 @@
+//go:build offline
+
+package redact
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSensitiveKey(t *testing.T) {
+	for _, key := range []string{"password", "Key", "access_token", "refresh_token", "privateKey", "value", "masterPasswordHash", "client_secret", "encryptedPrivateKey", "totp", "notes"} {
+		assert.True(t, IsSensitiveKey(key), key)
+	}
+	for _, key := range []string{"id", "name", "object", "revisionDate", "username", "uris", "kdfIterations", "organizationId"} {
+		assert.False(t, IsSensitiveKey(key), key)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	redacted, ok := JSON([]byte(`[{"id":"1","login":{"username":"alice","password":"p4ssw0rd","totp":null},"fields":[{"name":"pin","value":"1234"}],"sshKey":{"privateKey":"-----BEGIN"}}]`))
+	assert.True(t, ok)
+	assert.JSONEq(t, `[{"id":"1","login":{"username":"alice","password":"[REDACTED]","totp":null},"fields":[{"name":"pin","value":"[REDACTED]"}],"sshKey":"[REDACTED]"}]`, string(redacted))
+
+	_, ok = JSON([]byte("Syncing complete."))
+	assert.False(t, ok)
+}
+
+func TestBody(t *testing.T) {
+	assert.Equal(t, "client_id=id&client_secret=%5BREDACTED%5D&grant_type=client_credentials", Body("application/x-www-form-urlencoded; charset=utf-8", []byte("grant_type=client_credentials&client_id=id&client_secret=s3cr3t")))
+	assert.Equal(t, "Not found.", Body("text/plain", []byte("Not found.")))
+	assert.Equal(t, "[4 bytes omitted]", Body("application/octet-stream", []byte{0, 1, 2, 3}))
+	assert.Equal(t, "", Body("application/json", nil))
+}
+
+func TestHeaders(t *testing.T) {
+	redacted := Headers(http.Header{
+		"Authorization":           {"Bearer token"},
+		"Auth-Email":              {"YWxpY2VAZXhhbXBsZS5jb20"},
+		"Cf-Access-Client-Secret": {"secret"},
+		"Content-Type":            {"application/json"},
+		"Location":                {"https://vault.example.com/sso?code=abc&state=1"},
+	})
+	assert.Equal(t, http.Header{
+		"Authorization":           {Placeholder},
+		"Auth-Email":              {Placeholder},
+		"Cf-Access-Client-Secret": {Placeholder},
+		"Content-Type":            {"application/json"},
+		"Location":                {"https://vault.example.com/sso?code=%5BREDACTED%5D&state=1"},
+	}, redacted)
+}
+
+func TestLocation(t *testing.T) {
+	assert.Equal(t, "/sso-connector?code=%5BREDACTED%5D&state=1", Location("/sso-connector?code=abc&state=1"))
+	assert.Equal(t, "https://vault.example.com/#%5BREDACTED%5D", Location("https://vault.example.com/#access_token=abc"))
+	assert.Equal(t, Placeholder, Location("%zz"))
+}
+
+func TestURL(t *testing.T) {
+	u, _ := url.Parse("https://vault.example.com/api/accounts?access_token=abc&page=2")
+	assert.Equal(t, "https://vault.example.com/api/accounts?access_token=%5BREDACTED%5D&page=2", URL(u))
+}
+
+func TestArgs(t *testing.T) {
+	encoded := base64.RawStdEncoding.EncodeToString([]byte(`{"login":{"password":"p4ssw0rd"},"name":"test"}`))
+	assert.Equal(t, []string{"create", "item", `{"login":{"password":"[REDACTED]"},"name":"test"}`}, Args([]string{"create", "item", encoded}))
+	assert.Equal(t, []string{"get", "item", "1234"}, Args([]string{"get", "item", "1234"}))
+}
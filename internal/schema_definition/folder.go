@@ -24,6 +24,11 @@ func FolderResourceSchema() rsschema.Schema {
 				MarkdownDescription: DescriptionName,
 				Required:            true,
 			},
+			AttributeDeletionProtection: rsschema.BoolAttribute{
+				MarkdownDescription: DescriptionDeletionProtection,
+				Optional:            true,
+				Computed:            true,
+			},
 		},
 	}
 }
@@ -2,6 +2,7 @@ package schema_definition
 
 import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 type schemaTypeEnum int
@@ -124,6 +125,26 @@ func ItemBaseSchema(schemaType schemaTypeEnum) map[string]*schema.Schema {
 		},
 	}
 
+	if schemaType == Resource {
+		base[AttributeDeletionProtection] = &schema.Schema{
+			Description: DescriptionDeletionProtection,
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+		}
+		base[AttributeFieldMerge] = &schema.Schema{
+			Description:      DescriptionFieldMerge,
+			Type:             schema.TypeString,
+			Optional:         true,
+			ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{FieldMergeReplace, FieldMergeMerge}, false)),
+		}
+		base[AttributeRotateItemKey] = &schema.Schema{
+			Description: DescriptionRotateItemKey,
+			Type:        schema.TypeString,
+			Optional:    true,
+		}
+	}
+
 	if schemaType == DataSource {
 		base[AttributeFilterCollectionId] = &schema.Schema{
 			Description: DescriptionFilterCollectionID,
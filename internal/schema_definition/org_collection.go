@@ -41,6 +41,15 @@ func OrgCollectionSchema(schemaType schemaTypeEnum) map[string]*schema.Schema {
 		},
 	}
 
+	if schemaType == Resource {
+		base[AttributeDeletionProtection] = &schema.Schema{
+			Description: DescriptionDeletionProtection,
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Computed:    true,
+		}
+	}
+
 	if schemaType == DataSource {
 		base[AttributeFilterSearch] = &schema.Schema{
 			Description:  DescriptionFilterSearch,
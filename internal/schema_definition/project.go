@@ -20,6 +20,11 @@ func ProjectResourceSchema() rsschema.Schema {
 				MarkdownDescription: DescriptionName,
 				Required:            true,
 			},
+			AttributeDeletionProtection: rsschema.BoolAttribute{
+				MarkdownDescription: DescriptionDeletionProtection,
+				Optional:            true,
+				Computed:            true,
+			},
 			AttributeOrganizationID: rsschema.StringAttribute{
 				MarkdownDescription: DescriptionOrganizationID,
 				Optional:            true,
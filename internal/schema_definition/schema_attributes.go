@@ -10,6 +10,7 @@ const (
 	AttributeCollectionMemberManage        = "manage"
 	AttributeCreationDate                  = "creation_date"
 	AttributeDeletedDate                   = "deleted_date"
+	AttributeDeletionProtection            = "deletion_protection"
 	AttributeID                            = "id"
 	AttributeFavorite                      = "favorite"
 	AttributeField                         = "field"
@@ -18,6 +19,7 @@ const (
 	AttributeFieldHidden                   = "hidden"
 	AttributeFieldLinked                   = "linked"
 	AttributeFieldText                     = "text"
+	AttributeFieldMerge                    = "field_merge"
 	AttributeFolderID                      = "folder_id"
 	AttributeAttachmentContent             = "content"
 	AttributeAttachmentItemID              = "item_id"
@@ -49,6 +51,7 @@ const (
 	AttributeSSHKeyPublicKey               = "public_key"
 	AttributeReprompt                      = "reprompt"
 	AttributeRevisionDate                  = "revision_date"
+	AttributeRotateItemKey                 = "rotate_item_key"
 
 	// Secret specific attributes
 	AttributeKey       = "key"
@@ -56,6 +59,23 @@ const (
 	AttributeProjectID = "project_id"
 	AttributeValue     = "value"
 
+	// Server info specific attributes
+	AttributeServerInfoBWSVersion           = "bws_version"
+	AttributeServerInfoCLIVersion           = "cli_version"
+	AttributeServerInfoClientImplementation = "client_implementation"
+	AttributeServerInfoFeatureFlags         = "feature_flags"
+	AttributeServerInfoServerName           = "server_name"
+	AttributeServerInfoServerURL            = "server_url"
+	AttributeServerInfoServerVersion        = "server_version"
+
+	DescriptionServerInfoBWSVersion           = "Version of the Secrets Manager CLI, when it is used."
+	DescriptionServerInfoCLIVersion           = "Version of the Bitwarden CLI, when it is used."
+	DescriptionServerInfoClientImplementation = "Client implementation the provider uses."
+	DescriptionServerInfoFeatureFlags         = "Feature flags advertised by the Bitwarden Server."
+	DescriptionServerInfoServerName           = "Name of the server implementation, e.g. `Bitwarden` or `Vaultwarden`."
+	DescriptionServerInfoServerURL            = "URL of the Bitwarden Server."
+	DescriptionServerInfoServerVersion        = "Version of the Bitwarden Server."
+
 	// Data-source and Resource field descriptions
 	DescriptionAttachments                   = "List of item attachments."
 	DescriptionCollectionIDs                 = "Identifier of the collections the item belongs to."
@@ -67,6 +87,7 @@ const (
 	DescriptionCollectionMemberManage        = "[Experimental] Can manage the collection."
 	DescriptionCreationDate                  = "Date the item was created."
 	DescriptionDeletedDate                   = "Date the item was deleted."
+	DescriptionDeletionProtection            = "Refuse to destroy or replace the object until this is set to false and applied. Defaults to the `deletion_protection` of the provider."
 	DescriptionEmail                         = "User email."
 	DescriptionFavorite                      = "Mark as a Favorite to have item appear at the top of your Vault in the UI."
 	DescriptionField                         = "Extra fields."
@@ -75,6 +96,7 @@ const (
 	DescriptionFieldLinked                   = "Value of a linked field."
 	DescriptionFieldName                     = "Name of the field."
 	DescriptionFieldText                     = "Value of a text field."
+	DescriptionFieldMerge                    = "How the item is owned. Valid values are \"replace\" (default), which manages the whole item, or \"merge\", which only manages the declared custom fields, URIs and notes, preserving and ignoring the others that are maintained outside of Terraform."
 	DescriptionFilterCollectionID            = "Filter search results by collection ID."
 	DescriptionFilterFolderID                = "Filter search results by folder ID."
 	DescriptionFilterName                    = "Filter search results by name."
@@ -104,6 +126,7 @@ const (
 	DescriptionPublicKey                     = "Public key."
 	DescriptionReprompt                      = "Require master password 're-prompt' when displaying secret in the UI."
 	DescriptionRevisionDate                  = "Last time the item was updated."
+	DescriptionRotateItemKey                 = "Arbitrary value whose changes re-encrypt the item with a newly generated key of its own, along with its password history and the keys of its attachments (embedded client only)."
 
 	// Secret specific attributes
 	DescriptionValue     = "Value."
@@ -111,36 +134,113 @@ const (
 	DescriptionProjectID = "Identifier of the project."
 
 	// Provider field attributes
+	AttributeAPIURL                                        = "api_url"
+	AttributeAuditLogPath                                  = "audit_log_path"
 	AttributeBwsAccessToken                                = "access_token"
+	AttributeCLI                                           = "cli"
+	AttributeCLICommandTimeout                             = "command_timeout"
+	AttributeCLIMaxParallelReads                           = "max_parallel_reads"
+	AttributeCLIMaxParallelWrites                          = "max_parallel_writes"
 	AttributeClientID                                      = "client_id"
+	AttributeClientCertPath                                = "client_cert"
 	AttributeClientImplementation                          = "client_implementation"
+	AttributeClientKeyPath                                 = "client_key"
 	AttributeClientSecret                                  = "client_secret"
+	AttributeConflictPolicy                                = "conflict_policy"
+	AttributeDebugHARPath                                  = "debug_har_path"
+	AttributeProviderDeletionProtection                    = "deletion_protection"
 	AttributeProviderEmail                                 = "email"
+	AttributeEventsURL                                     = "events_url"
+	AttributeExportFile                                    = "export_file"
+	AttributeExportPassword                                = "export_password"
+	AttributeHTTP                                          = "http"
+	AttributeHTTPAttemptTimeout                            = "attempt_timeout"
+	AttributeHTTPDialTimeout                               = "dial_timeout"
+	AttributeHTTPHeaders                                   = "http_headers"
+	AttributeIdentityURL                                   = "identity_url"
+	AttributeHTTPMaxConcurrentRequests                     = "max_concurrent_requests"
+	AttributeHTTPMaxRetries                                = "max_retries"
+	AttributeHTTPRetryBackoffFactor                        = "retry_backoff_factor"
+	AttributeHTTPRetryOn429                                = "retry_on_429"
+	AttributeHTTPRetryOn500                                = "retry_on_500"
+	AttributeHTTPRetryOn503                                = "retry_on_503"
 	AttributeMasterPassword                                = "master_password"
+	AttributeMemoryFixture                                 = "memory_fixture"
+	AttributeProxyURL                                      = "proxy_url"
+	AttributeReadOnly                                      = "read_only"
+	AttributeRegion                                        = "region"
 	AttributeServer                                        = "server"
+	AttributeServerSPKIPins                                = "server_spki_pins"
 	AttributeSessionKey                                    = "session_key"
+	AttributeUserKey                                       = "user_key"
 	AttributeVaultPath                                     = "vault_path"
 	AttributeExtraCACertsPath                              = "extra_ca_certs"
 	AttributeExperimental                                  = "experimental"
 	AttributeExperimentalEmbeddedClient                    = "embedded_client"
 	AttributeExperimentalDisableSyncAfterWriteVerification = "disable_sync_after_write_verification"
+	AttributeExperimentalMigrateToItemKeys                 = "migrate_to_item_keys"
+	AttributeExperimentalCLIListCache                      = "cli_list_cache"
 
 	// Client implementation values
-	ClientImplementationCLI      = "cli"
-	ClientImplementationEmbedded = "embedded"
+	ClientImplementationCLI        = "cli"
+	ClientImplementationCLIServe   = "cli_serve"
+	ClientImplementationEmbedded   = "embedded"
+	ClientImplementationExportFile = "export_file"
+	ClientImplementationMemory     = "memory"
+
+	// Conflict policy values
+	ConflictPolicyFail      = "fail"
+	ConflictPolicyOverwrite = "overwrite"
+
+	// Field merge values
+	FieldMergeReplace = "replace"
+	FieldMergeMerge   = "merge"
 
 	// Provider field descriptions
+	DescriptionAPIURL                                        = "URL of the Bitwarden API, when not served under `<server>/api`."
+	DescriptionConflictPolicy                                = "What to do when an object was modified in the Vault since Terraform last read it. Valid values are \"fail\" (default), which fails the apply with the names of the attributes that changed, or \"overwrite\", which replaces the changes."
+	DescriptionAuditLogPath                                  = "Path of a file to which a JSON line is appended for every object the provider creates, edits or deletes, with the names of the changed attributes but never their values (env: `BW_AUDIT_LOG_PATH`)."
+	DescriptionProviderDeletionProtection                    = "Default `deletion_protection` of the items, folders, collections, projects and secrets which don't set it (default: `false`)."
+	DescriptionEventsURL                                     = "URL of the Bitwarden Events service, when not served under `<server>/events` (CLI client only, unused by the embedded client)."
+	DescriptionExportFile                                    = "Path to a Bitwarden JSON export served read-only when `client_implementation` is \"export_file\" (env: `BW_EXPORT_FILE`)."
+	DescriptionExportPassword                                = "Password of a password-protected encrypted export (env: `BW_EXPORT_PASSWORD`)."
+	DescriptionMemoryFixture                                 = "Path to a JSON file seeding the in-memory vault when `client_implementation` is \"memory\" (env: `BW_MEMORY_FIXTURE`)."
+	DescriptionIdentityURL                                   = "URL of the Bitwarden Identity service, when not served under `<server>/identity`."
+	DescriptionReadOnly                                      = "Refuse to create, edit or delete anything in the Vault, and avoid side effects like logging the CLI in or out or storing a device identifier (env: `BW_READ_ONLY`, which enables it whatever the configuration says)."
+	DescriptionRegion                                        = "Region of Bitwarden's cloud to connect to, resolving the server URL. Valid values are \"us\" (default) or \"eu\". Takes precedence over `BW_URL`, ignored when `server` is set."
 	DescriptionBwsAccessToken                                = "Machine Account Access Token (env: `BWS_ACCESS_TOKEN`))."
 	DescriptionClientSecret                                  = "Client Secret (env: `BW_CLIENTSECRET`). Do not commit this information in Git unless you know what you're doing. Prefer using a Terraform `variable {}` in order to inject this value from the environment."
 	DescriptionClientID                                      = "Client ID (env: `BW_CLIENTID`)"
 	DescriptionProviderEmail                                 = "Login Email of the Vault (env: `BW_EMAIL`)."
 	DescriptionMasterPassword                                = "Master password of the Vault (env: `BW_PASSWORD`). Do not commit this information in Git unless you know what you're doing. Prefer using a Terraform `variable {}` in order to inject this value from the environment."
 	DescriptionServer                                        = "Bitwarden Server URL (default: `https://vault.bitwarden.com`, env: `BW_URL` or `BWS_SERVER_URL`)."
-	DescriptionSessionKey                                    = "A Bitwarden Session Key (env: `BW_SESSION`)"
+	DescriptionSessionKey                                    = "A Bitwarden Session Key (env: `BW_SESSION`). With the embedded client, it unlocks the Vault previously synced and unlocked by the CLI in `vault_path`, which is used offline unless `client_id` and `client_secret` are also specified."
+	DescriptionUserKey                                       = "Base64-encoded user key unlocking the Vault instead of the master password (env: `BW_USER_KEY`, requires `client_id` and `client_secret`, embedded client only)."
 	DescriptionVaultPath                                     = "Alternative directory for storing the Vault locally (default: `.bitwarden/`, env: `BITWARDENCLI_APPDATA_DIR`; set to empty string to use CLI default)."
-	DescriptionExtraCACertsPath                              = "Extends the well known 'root' CAs (like VeriSign) with the extra certificates in file (env: `NODE_EXTRA_CA_CERTS`, doesn't work with embedded client)."
-	DescriptionClientImplementation                          = "Client implementation type. Valid values are \"embedded\" (use embedded client) or \"cli\" (use CLI binaries, default)."
+	DescriptionExtraCACertsPath                              = "Extends the well known 'root' CAs (like VeriSign) with the extra certificates in file (env: `NODE_EXTRA_CA_CERTS`)."
+	DescriptionClientCertPath                                = "Path to a PEM-encoded client certificate presented to servers requiring mutual TLS (requires `client_key`, embedded client only)."
+	DescriptionClientKeyPath                                 = "Path to the PEM-encoded private key of `client_cert` (embedded client only)."
+	DescriptionCLI                                           = "Tune how commands of the Bitwarden CLI are run (Bitwarden CLI only)."
+	DescriptionCLICommandTimeout                             = "Time after which a command is killed, as a duration like `5m` (default: `10m`)."
+	DescriptionCLIMaxParallelReads                           = "Maximum number of commands reading the Vault run at the same time (default: `4`)."
+	DescriptionCLIMaxParallelWrites                          = "Maximum number of commands writing to the Vault run at the same time, never alongside reads (default: `1`)."
+	DescriptionHTTP                                          = "Tune how requests to the Bitwarden Server are sent and retried."
+	DescriptionHTTPAttemptTimeout                            = "Overall timeout of a single request attempt, as a duration like `30s` (default: `15s`, embedded client only)."
+	DescriptionHTTPDialTimeout                               = "Timeout for resolving and connecting to the Bitwarden Server, as a duration like `10s` (default: `10s`, embedded client only)."
+	DescriptionHTTPMaxConcurrentRequests                     = "Maximum number of requests sent to the Bitwarden Server at the same time (default: `4`, embedded client only)."
+	DescriptionHTTPMaxRetries                                = "Maximum number of retries for failed requests (default: `1` for the embedded client, `2` for the CLI)."
+	DescriptionHTTPRetryBackoffFactor                        = "Base of the exponential backoff between retries, in seconds (default: `1.5` for the embedded client, `2` for the CLI)."
+	DescriptionHTTPRetryOn429                                = "Retry requests that were rate limited (`429 Too Many Requests`) (default: `true`)."
+	DescriptionHTTPRetryOn500                                = "Retry GET requests that failed with `500 Internal Server Error` (default: `true`, embedded client only)."
+	DescriptionHTTPRetryOn503                                = "Retry GET requests that failed with `503 Service Unavailable` (default: `true`, embedded client only)."
+	DescriptionHTTPHeaders                                   = "Additional HTTP headers sent with every request to the Bitwarden Server, e.g. to authenticate against a proxy like Cloudflare Access (embedded client only, setting them with the Bitwarden CLIs is an error as they don't send custom headers)."
+	DescriptionDebugHARPath                                  = "Path of a HAR file recording every request sent to the Bitwarden Server, with secrets redacted, to attach to bug reports (env: `BW_DEBUG_HAR_PATH`, embedded client only)."
+	DescriptionProxyURL                                      = "URL of the HTTP(S) proxy to send requests through, instead of the one derived from `HTTPS_PROXY`/`HTTP_PROXY`/`NO_PROXY` (embedded client only)."
+	DescriptionServerSPKIPins                                = "SHA-256 digests (base64, optionally prefixed with `sha256/`) of the Subject Public Key Info the Bitwarden Server's certificate chain must match (embedded client only)."
+	DescriptionClientImplementation                          = "Client implementation type. Valid values are \"embedded\" (use embedded client), \"cli\" (use CLI binaries, default), \"cli_serve\" (use CLI binaries through a single `bw serve` process), \"export_file\" (serve a Bitwarden JSON export read-only) or \"memory\" (keep objects in memory, for testing)."
 	DescriptionExperimental                                  = "Enable experimental features."
 	DescriptionExperimentalEmbeddedClient                    = "Use the embedded client instead of an external binary."
 	DescriptionExperimentalDisableSyncAfterWriteVerification = "Skip verification of server-side modifications (like timestamp updates) after write operations - useful when the Bitwarden server makes minor, non-functional changes to objects."
+	DescriptionExperimentalCLIListCache                      = "Serve reads from a single `bw list` per object type and Terraform run, instead of running a command for each of them (Bitwarden CLI only)."
+	DescriptionExperimentalMigrateToItemKeys                 = "Give items a key of their own when they're created or updated, even if the server doesn't enable cipher key encryption yet (embedded client only)."
 )
@@ -80,9 +80,10 @@ func TestSchemaContracts(t *testing.T) {
 			name: "ItemBase/Resource",
 			got:  ItemBaseSchema(Resource),
 			want: map[string]attrContract{
-				"collection_ids": {Type: schema.TypeSet, Required: false, Optional: true, Computed: false, ForceNew: false, Sensitive: false},
-				"creation_date":  {Type: schema.TypeString, Required: false, Optional: false, Computed: true, ForceNew: false, Sensitive: false},
-				"deleted_date":   {Type: schema.TypeString, Required: false, Optional: false, Computed: true, ForceNew: false, Sensitive: false},
+				"collection_ids":      {Type: schema.TypeSet, Required: false, Optional: true, Computed: false, ForceNew: false, Sensitive: false},
+				"creation_date":       {Type: schema.TypeString, Required: false, Optional: false, Computed: true, ForceNew: false, Sensitive: false},
+				"deleted_date":        {Type: schema.TypeString, Required: false, Optional: false, Computed: true, ForceNew: false, Sensitive: false},
+				"deletion_protection": {Type: schema.TypeBool, Required: false, Optional: true, Computed: true, ForceNew: false, Sensitive: false},
 				"field": {Type: schema.TypeList, Required: false, Optional: true, Computed: false, ForceNew: false, Sensitive: true, Nested: map[string]attrContract{
 					"boolean": {Type: schema.TypeBool, Required: false, Optional: true, Computed: false, ForceNew: false, Sensitive: false},
 					"hidden":  {Type: schema.TypeString, Required: false, Optional: true, Computed: false, ForceNew: false, Sensitive: false},
@@ -90,6 +91,7 @@ func TestSchemaContracts(t *testing.T) {
 					"name":    {Type: schema.TypeString, Required: true, Optional: false, Computed: false, ForceNew: false, Sensitive: false},
 					"text":    {Type: schema.TypeString, Required: false, Optional: true, Computed: false, ForceNew: false, Sensitive: false},
 				}},
+				"field_merge":     {Type: schema.TypeString, Required: false, Optional: true, Computed: false, ForceNew: false, Sensitive: false},
 				"folder_id":       {Type: schema.TypeString, Required: false, Optional: true, Computed: false, ForceNew: false, Sensitive: false},
 				"id":              {Type: schema.TypeString, Required: false, Optional: true, Computed: true, ForceNew: false, Sensitive: false},
 				"name":            {Type: schema.TypeString, Required: true, Optional: false, Computed: false, ForceNew: false, Sensitive: false},
@@ -97,6 +99,7 @@ func TestSchemaContracts(t *testing.T) {
 				"organization_id": {Type: schema.TypeString, Required: false, Optional: true, Computed: false, ForceNew: true, Sensitive: false},
 				"reprompt":        {Type: schema.TypeBool, Required: false, Optional: true, Computed: false, ForceNew: false, Sensitive: false},
 				"revision_date":   {Type: schema.TypeString, Required: false, Optional: false, Computed: true, ForceNew: false, Sensitive: false},
+				"rotate_item_key": {Type: schema.TypeString, Required: false, Optional: true, Computed: false, ForceNew: false, Sensitive: false},
 			},
 		},
 		{
@@ -219,7 +222,8 @@ func TestSchemaContracts(t *testing.T) {
 			name: "OrgCollection/Resource",
 			got:  OrgCollectionSchema(Resource),
 			want: map[string]attrContract{
-				"id": {Type: schema.TypeString, Required: false, Optional: true, Computed: true, ForceNew: false, Sensitive: false},
+				"deletion_protection": {Type: schema.TypeBool, Required: false, Optional: true, Computed: true, ForceNew: false, Sensitive: false},
+				"id":                  {Type: schema.TypeString, Required: false, Optional: true, Computed: true, ForceNew: false, Sensitive: false},
 				"member": {Type: schema.TypeSet, Required: false, Optional: true, Computed: false, ForceNew: false, Sensitive: false, Nested: map[string]attrContract{
 					"hide_passwords": {Type: schema.TypeBool, Required: false, Optional: true, Computed: false, ForceNew: false, Sensitive: false},
 					"id":             {Type: schema.TypeString, Required: true, Optional: false, Computed: false, ForceNew: false, Sensitive: false},
@@ -20,6 +20,11 @@ func SecretResourceSchema() rsschema.Schema {
 				Computed:            true,
 				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
 			},
+			AttributeDeletionProtection: rsschema.BoolAttribute{
+				MarkdownDescription: DescriptionDeletionProtection,
+				Optional:            true,
+				Computed:            true,
+			},
 			AttributeKey: rsschema.StringAttribute{
 				MarkdownDescription: DescriptionName,
 				Required:            true,
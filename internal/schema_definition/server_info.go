@@ -0,0 +1,47 @@
+package schema_definition
+
+import (
+	dsschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func ServerInfoDataSourceSchema() dsschema.Schema {
+	return dsschema.Schema{
+		MarkdownDescription: "Use this data source to get information on the Bitwarden Server and clients the provider uses.",
+		Attributes: map[string]dsschema.Attribute{
+			AttributeID: dsschema.StringAttribute{
+				MarkdownDescription: DescriptionIdentifier,
+				Computed:            true,
+			},
+			AttributeServerInfoBWSVersion: dsschema.StringAttribute{
+				MarkdownDescription: DescriptionServerInfoBWSVersion,
+				Computed:            true,
+			},
+			AttributeServerInfoCLIVersion: dsschema.StringAttribute{
+				MarkdownDescription: DescriptionServerInfoCLIVersion,
+				Computed:            true,
+			},
+			AttributeServerInfoClientImplementation: dsschema.StringAttribute{
+				MarkdownDescription: DescriptionServerInfoClientImplementation,
+				Computed:            true,
+			},
+			AttributeServerInfoFeatureFlags: dsschema.MapAttribute{
+				MarkdownDescription: DescriptionServerInfoFeatureFlags,
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			AttributeServerInfoServerName: dsschema.StringAttribute{
+				MarkdownDescription: DescriptionServerInfoServerName,
+				Computed:            true,
+			},
+			AttributeServerInfoServerURL: dsschema.StringAttribute{
+				MarkdownDescription: DescriptionServerInfoServerURL,
+				Computed:            true,
+			},
+			AttributeServerInfoServerVersion: dsschema.StringAttribute{
+				MarkdownDescription: DescriptionServerInfoServerVersion,
+				Computed:            true,
+			},
+		},
+	}
+}
@@ -0,0 +1,112 @@
+// Package tracing instruments the provider with OpenTelemetry spans. Spans are
+// only exported when enabled with the standard OTEL_* environment variables,
+// and cost next to nothing otherwise.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/maxlaverse/terraform-provider-bitwarden"
+	serviceName         = "terraform-provider-bitwarden"
+)
+
+// Setup installs a tracer provider exporting spans with OTLP, when the
+// environment enables it the same way it does for Terraform itself:
+// OTEL_TRACES_EXPORTER set to "otlp", or an OTLP endpoint configured with
+// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT. The
+// exporter reads the other OTEL_EXPORTER_OTLP_* variables, like headers or
+// certificates, on its own.
+//
+// The returned function flushes the spans which haven't been exported yet,
+// and must be called before the process exits. It is never nil, and does
+// nothing when tracing is disabled or couldn't be set up.
+func Setup(ctx context.Context, version string) (func(context.Context) error, error) {
+	noShutdown := func(context.Context) error { return nil }
+	if !enabled(os.Getenv) {
+		return noShutdown, nil
+	}
+
+	exporter, err := newExporter(ctx, os.Getenv)
+	if err != nil {
+		return noShutdown, fmt.Errorf("error creating OTLP trace exporter: %w", err)
+	}
+
+	// Attributes from OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take
+	// precedence over the defaults.
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(serviceName), semconv.ServiceVersion(version)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		_ = exporter.Shutdown(ctx)
+		return noShutdown, fmt.Errorf("error creating OpenTelemetry resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func enabled(getenv func(string) string) bool {
+	if strings.EqualFold(getenv("OTEL_SDK_DISABLED"), "true") {
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(getenv("OTEL_TRACES_EXPORTER"))) {
+	case "otlp":
+		return true
+	case "":
+		return len(getenv("OTEL_EXPORTER_OTLP_ENDPOINT")) > 0 || len(getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")) > 0
+	default:
+		return false
+	}
+}
+
+func newExporter(ctx context.Context, getenv func(string) string) (sdktrace.SpanExporter, error) {
+	protocol := getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+	if len(protocol) == 0 {
+		protocol = getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+
+	switch protocol {
+	case "", "http/protobuf":
+		return otlptracehttp.New(ctx)
+	case "grpc":
+		return otlptracegrpc.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol '%s', expected 'http/protobuf' or 'grpc'", protocol)
+	}
+}
+
+// Start starts a span, as a child of the one in ctx if there is one.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends a span, marking it as failed when err isn't nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
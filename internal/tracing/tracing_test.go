@@ -0,0 +1,88 @@
+//go:build offline
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/tracing/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestEnabled(t *testing.T) {
+	testCases := map[string]struct {
+		env      map[string]string
+		expected bool
+	}{
+		"disabled by default": {
+			env:      map[string]string{},
+			expected: false,
+		},
+		"otlp exporter": {
+			env:      map[string]string{"OTEL_TRACES_EXPORTER": "otlp"},
+			expected: true,
+		},
+		"endpoint": {
+			env:      map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318"},
+			expected: true,
+		},
+		"traces endpoint": {
+			env:      map[string]string{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "http://localhost:4318/v1/traces"},
+			expected: true,
+		},
+		"other exporter": {
+			env:      map[string]string{"OTEL_TRACES_EXPORTER": "none", "OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318"},
+			expected: false,
+		},
+		"sdk disabled": {
+			env:      map[string]string{"OTEL_SDK_DISABLED": "true", "OTEL_TRACES_EXPORTER": "otlp"},
+			expected: false,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, enabled(func(key string) string { return tc.env[key] }))
+		})
+	}
+}
+
+func TestNewExporter_UnsupportedProtocol(t *testing.T) {
+	_, err := newExporter(context.Background(), func(key string) string {
+		if key == "OTEL_EXPORTER_OTLP_PROTOCOL" {
+			return "http/json"
+		}
+		return ""
+	})
+	assert.ErrorContains(t, err, "unsupported OTLP protocol 'http/json'")
+}
+
+func TestSetup_FailureDisablesTracing(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/json")
+
+	shutdown, err := Setup(context.Background(), "test")
+	assert.ErrorContains(t, err, "unsupported OTLP protocol")
+	require.NotNil(t, shutdown)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestStartEnd(t *testing.T) {
+	recorder := test.UseRecorder(t)
+
+	ctx, parent := Start(context.Background(), "parent")
+	_, child := Start(ctx, "child")
+	End(child, errors.New("failure"))
+	End(parent, nil)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "failure", spans[0].Status().Description)
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
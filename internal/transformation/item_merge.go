@@ -0,0 +1,73 @@
+package transformation
+
+import (
+	"slices"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+)
+
+// MergeUnmanagedItemAttributes returns the item to write when its resource
+// only manages the custom fields, URIs and notes it declares: the declared
+// ones, followed by the ones of the item in the Vault it doesn't manage. The
+// ones previously declared and no longer are removed. Custom fields are
+// matched by name, URIs by value, and notes are declared when not empty.
+func MergeUnmanagedItemAttributes(declared, previous, remote models.Item) models.Item {
+	merged := declared
+
+	merged.Fields = slices.Clone(declared.Fields)
+	for _, field := range remote.Fields {
+		if !slices.ContainsFunc(declared.Fields, sameFieldName(field)) && !slices.ContainsFunc(previous.Fields, sameFieldName(field)) {
+			merged.Fields = append(merged.Fields, field)
+		}
+	}
+
+	merged.Login.URIs = slices.Clone(declared.Login.URIs)
+	for _, uri := range remote.Login.URIs {
+		if !slices.ContainsFunc(declared.Login.URIs, sameURI(uri)) && !slices.ContainsFunc(previous.Login.URIs, sameURI(uri)) {
+			merged.Login.URIs = append(merged.Login.URIs, uri)
+		}
+	}
+
+	if len(declared.Notes) == 0 && len(previous.Notes) == 0 {
+		merged.Notes = remote.Notes
+	}
+	return merged
+}
+
+// IgnoreUnmanagedItemAttributes returns the item in the Vault without the
+// custom fields, URIs and notes its resource doesn't declare, so that they
+// don't show up as changes. The remaining ones follow the declared order.
+func IgnoreUnmanagedItemAttributes(remote, declared models.Item) models.Item {
+	filtered := remote
+
+	filtered.Fields = nil
+	for _, field := range declared.Fields {
+		if i := slices.IndexFunc(remote.Fields, sameFieldName(field)); i >= 0 {
+			filtered.Fields = append(filtered.Fields, remote.Fields[i])
+		}
+	}
+
+	filtered.Login.URIs = nil
+	for _, uri := range declared.Login.URIs {
+		if i := slices.IndexFunc(remote.Login.URIs, sameURI(uri)); i >= 0 {
+			filtered.Login.URIs = append(filtered.Login.URIs, remote.Login.URIs[i])
+		}
+	}
+
+	if len(declared.Notes) == 0 {
+		filtered.Notes = ""
+	}
+	return filtered
+}
+
+func sameFieldName(field models.Field) func(models.Field) bool {
+	return func(other models.Field) bool {
+		return other.Name == field.Name
+	}
+}
+
+func sameURI(uri models.LoginURI) func(models.LoginURI) bool {
+	return func(other models.LoginURI) bool {
+		return other.URI == uri.URI
+	}
+}
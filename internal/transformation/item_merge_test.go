@@ -0,0 +1,75 @@
+package transformation
+
+import (
+	"testing"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeUnmanagedItemAttributes(t *testing.T) {
+	t.Parallel()
+
+	declared := models.Item{
+		Name:   "shared",
+		Fields: []models.Field{{Name: "api_key", Value: "new", Type: models.FieldTypeHidden}},
+		Login: models.Login{
+			Password: "new",
+			URIs:     []models.LoginURI{{URI: "https://example.com"}},
+		},
+	}
+	remote := models.Item{
+		Name:  "shared",
+		Notes: "maintained by humans",
+		Fields: []models.Field{
+			{Name: "owner", Value: "team", Type: models.FieldTypeText},
+			{Name: "api_key", Value: "old", Type: models.FieldTypeHidden},
+		},
+		Login: models.Login{
+			Password: "old",
+			URIs:     []models.LoginURI{{URI: "https://example.com/login"}, {URI: "https://example.com"}},
+		},
+	}
+
+	merged := MergeUnmanagedItemAttributes(declared, models.Item{}, remote)
+	assert.Equal(t, "new", merged.Login.Password)
+	assert.Equal(t, "maintained by humans", merged.Notes)
+	assert.Equal(t, []models.Field{
+		{Name: "api_key", Value: "new", Type: models.FieldTypeHidden},
+		{Name: "owner", Value: "team", Type: models.FieldTypeText},
+	}, merged.Fields)
+	assert.Equal(t, []models.LoginURI{{URI: "https://example.com"}, {URI: "https://example.com/login"}}, merged.Login.URIs)
+	assert.Len(t, declared.Fields, 1, "the declared item must not be modified")
+
+	filtered := IgnoreUnmanagedItemAttributes(merged, declared)
+	assert.Equal(t, declared.Fields, filtered.Fields)
+	assert.Equal(t, declared.Login.URIs, filtered.Login.URIs)
+	assert.Empty(t, filtered.Notes)
+
+	declared.Notes = "managed"
+	assert.Equal(t, "managed", MergeUnmanagedItemAttributes(declared, models.Item{}, remote).Notes)
+	assert.Equal(t, "maintained by humans", IgnoreUnmanagedItemAttributes(remote, declared).Notes)
+}
+
+func TestMergeUnmanagedItemAttributesRemovesPreviouslyDeclared(t *testing.T) {
+	t.Parallel()
+
+	previous := models.Item{
+		Notes:  "managed",
+		Fields: []models.Field{{Name: "api_key", Value: "old", Type: models.FieldTypeHidden}},
+		Login:  models.Login{URIs: []models.LoginURI{{URI: "https://example.com"}}},
+	}
+	remote := models.Item{
+		Notes: "managed",
+		Fields: []models.Field{
+			{Name: "owner", Value: "team", Type: models.FieldTypeText},
+			{Name: "api_key", Value: "old", Type: models.FieldTypeHidden},
+		},
+		Login: models.Login{URIs: []models.LoginURI{{URI: "https://example.com/login"}, {URI: "https://example.com"}}},
+	}
+
+	merged := MergeUnmanagedItemAttributes(models.Item{}, previous, remote)
+	assert.Empty(t, merged.Notes)
+	assert.Equal(t, []models.Field{{Name: "owner", Value: "team", Type: models.FieldTypeText}}, merged.Fields)
+	assert.Equal(t, []models.LoginURI{{URI: "https://example.com/login"}}, merged.Login.URIs)
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
+	"os"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6/tf6server"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/bwcli"
 	"github.com/maxlaverse/terraform-provider-bitwarden/internal/provider"
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/tracing"
 )
 
 //go:generate terraform fmt -recursive ./examples/
@@ -20,15 +25,33 @@ var (
 	commit string = ""
 
 	providerAddr string = "registry.terraform.io/maxlaverse/bitwarden"
+
+	// tracingShutdownTimeout bounds how long exiting waits for the remaining
+	// spans to be exported.
+	tracingShutdownTimeout = 5 * time.Second
 )
 
 func main() {
 	_ = commit
 
+	if len(os.Args) > 1 && os.Args[1] == serveFakeCommand {
+		if err := serveFake(os.Args[2:]); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
 	var debug bool
 	flag.BoolVar(&debug, "debug", false, "set to true to run the provider with support for debuggers like delve")
 	flag.Parse()
 
+	// Traces are only a diagnostic aid, they shouldn't prevent Terraform from
+	// using the provider.
+	shutdownTracing, err := tracing.Setup(context.Background(), version)
+	if err != nil {
+		log.Printf("[WARN] continuing without tracing: %v", err)
+	}
+
 	serverFactory, err := provider.NewProviderServer(version)
 	if err != nil {
 		log.Fatal(err.Error())
@@ -39,7 +62,19 @@ func main() {
 		serveOpts = append(serveOpts, tf6server.WithManagedDebug())
 	}
 
-	if err := tf6server.Serve(providerAddr, serverFactory, serveOpts...); err != nil {
+	err = tf6server.Serve(providerAddr, serverFactory, serveOpts...)
+
+	// Terraform stops the plugin once it's done with it, which is when the
+	// `bw serve` processes started with client_implementation = "cli_serve"
+	// aren't needed anymore.
+	bwcli.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	if shutdownErr := shutdownTracing(ctx); shutdownErr != nil {
+		log.Printf("error exporting traces: %v", shutdownErr)
+	}
+	if err != nil {
 		log.Fatal(err.Error())
 	}
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/maxlaverse/terraform-provider-bitwarden/internal/bitwarden/fakeserver"
+)
+
+const serveFakeCommand = "serve-fake"
+
+// serveFake runs an in-memory Bitwarden server seeded with an account, an
+// organization and a machine account, and prints the credentials to configure
+// the provider or the official CLIs with.
+func serveFake(args []string) error {
+	flags := flag.NewFlagSet(serveFakeCommand, flag.ExitOnError)
+	listenAddr := flags.String("listen", "127.0.0.1:8087", "address to listen on")
+	email := flags.String("email", "test@example.com", "email of the seeded account")
+	password := flags.String("password", "test-master-password", "master password of the seeded account")
+	organization := flags.String("organization", "Test Organization", "name of the seeded organization")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	server := fakeserver.New()
+	account, err := server.RegisterAccount(*email, *password)
+	if err != nil {
+		return fmt.Errorf("error seeding account: %w", err)
+	}
+
+	orgID, err := server.CreateOrganization(account.Email, *organization)
+	if err != nil {
+		return fmt.Errorf("error seeding organization: %w", err)
+	}
+
+	accessToken, err := server.CreateMachineAccount(orgID)
+	if err != nil {
+		return fmt.Errorf("error seeding machine account: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", *listenAddr)
+	if err != nil {
+		return fmt.Errorf("error listening on '%s': %w", *listenAddr, err)
+	}
+
+	fmt.Fprintf(os.Stdout, `Fake Bitwarden server listening on http://%s
+All data is kept in memory and lost on exit.
+
+export BW_URL=http://%s
+export BW_EMAIL=%s
+export BW_PASSWORD=%s
+export BW_CLIENTID=%s
+export BW_CLIENTSECRET=%s
+export BWS_ACCESS_TOKEN=%s
+
+Organization ID: %s
+`, listener.Addr(), listener.Addr(), account.Email, account.Password, account.ClientID, account.ClientSecret, accessToken, orgID)
+
+	return http.Serve(listener, server)
+}